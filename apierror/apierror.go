@@ -0,0 +1,103 @@
+// Package apierror writes the JSON envelope every endpoint and middleware reports errors in:
+//
+//	{"error": {"code": "validation_failed", "message": "...", "request_id": "...", "fields": [...]}}
+//
+// Messages are translated into the language of the request, see package i18n.
+package apierror
+
+import (
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/util"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// FieldError is the problem with one field of a request that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	// Variant and Params select and fill the template Message is translated from
+	Variant string      `json:"-"`
+	Params  i18n.Params `json:"-"`
+}
+
+// NewFieldError describes the problem with a field by the template of its code and variant,
+// with its message in English
+func NewFieldError(field string, code Code, variant string, params i18n.Params) FieldError {
+	message, _ := i18n.Translate(i18n.English, i18n.Key(string(code), variant), params)
+	return FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+		Variant: variant,
+		Params:  params,
+	}
+}
+
+// Body describes an error. RequestID lets support find the request in the logs.
+type Body struct {
+	Code      Code         `json:"code"`
+	Message   string       `json:"message"`
+	RequestID string       `json:"request_id,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+}
+
+// Envelope is the response body of every error
+type Envelope struct {
+	Error Body `json:"error"`
+}
+
+// Write responds to r with an error in the envelope. Messages are written in English; when the
+// request asks for another language, the message is replaced by the template of the code in that
+// language, or for an error listing fields by their translated messages.
+func Write(w http.ResponseWriter, r *http.Request, status int, code Code, message string, fields ...FieldError) {
+	if lang, ok := i18n.FromContext(r.Context()); ok {
+		if lang != i18n.English {
+			message, fields = translate(lang, code, message, fields)
+		}
+		w.Header().Set("Content-Language", string(lang))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Envelope{Error: Body{
+		Code:      code,
+		Message:   message,
+		RequestID: util.RequestID(r.Context()),
+		Fields:    fields,
+	}})
+}
+
+// translate replaces the English messages of an error by their templates in lang
+func translate(lang i18n.Language, code Code, message string, fields []FieldError) (string, []FieldError) {
+	if len(fields) == 0 {
+		if translated, ok := i18n.Translate(lang, string(code), nil); ok {
+			message = translated
+		}
+		return message, nil
+	}
+
+	translatedFields := make([]FieldError, len(fields))
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		if translated, ok := i18n.Translate(lang, i18n.Key(string(field.Code), field.Variant), field.Params); ok {
+			field.Message = translated
+		}
+		translatedFields[i] = field
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; "), translatedFields
+}
+
+// NotFound reports a route that does not exist
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusNotFound, CodeNotFound, "Not found")
+}
+
+// MethodNotAllowed reports a route that exists but does not accept the request method
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed")
+}
@@ -0,0 +1,148 @@
+package apierror_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/middleware"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeValidationFailed, "NIK is required", apierror.FieldError{
+			Field:   "nik",
+			Code:    apierror.CodeRequired,
+			Message: "NIK is required",
+		})
+	}))
+
+	t.Run("Envelope", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/register", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var envelope apierror.Envelope
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+		assert.Equal(t, apierror.CodeValidationFailed, envelope.Error.Code)
+		assert.Equal(t, "NIK is required", envelope.Error.Message)
+		assert.Equal(t, []apierror.FieldError{{Field: "nik", Code: apierror.CodeRequired, Message: "NIK is required"}}, envelope.Error.Fields)
+
+		// The ID in the body is the one echoed in the header
+		assert.Len(t, envelope.Error.RequestID, 32)
+		assert.Equal(t, recorder.Header().Get(middleware.RequestIDHeader), envelope.Error.RequestID)
+	})
+
+	t.Run("Request ID of a proxy is kept", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/register", nil)
+		req.Header.Set(middleware.RequestIDHeader, "lb-7f3a9c")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, "lb-7f3a9c", recorder.Header().Get(middleware.RequestIDHeader))
+		assert.Contains(t, recorder.Body.String(), `"request_id":"lb-7f3a9c"`)
+	})
+
+	t.Run("Malformed request ID is replaced", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/register", nil)
+		req.Header.Set(middleware.RequestIDHeader, "<script>")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.NotEqual(t, "<script>", recorder.Header().Get(middleware.RequestIDHeader))
+		assert.Len(t, recorder.Header().Get(middleware.RequestIDHeader), 32)
+	})
+}
+
+func TestWriteTranslated(t *testing.T) {
+	handler := middleware.Language(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/customers/me" {
+			apierror.Write(w, r, http.StatusNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+			return
+		}
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeValidationFailed, "Address must be at most 255 characters",
+			apierror.NewFieldError("address", apierror.CodeTooLong, "", i18n.Params{"field": "Address", "max": "255"}))
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptLanguage string
+		language       string
+		message        string
+		fieldMessage   string
+	}{
+		{name: "Default language", path: "/customers/me", language: "id", message: "Nasabah tidak ditemukan"},
+		{name: "English", path: "/customers/me", acceptLanguage: "en-US,en;q=0.9", language: "en", message: "Customer not found"},
+		{name: "Fields in Indonesian", path: "/customers/me/profile", acceptLanguage: "id-ID", language: "id",
+			message: "Address paling banyak 255 karakter", fieldMessage: "Address paling banyak 255 karakter"},
+		{name: "Fields in English", path: "/customers/me/profile", acceptLanguage: "en", language: "en",
+			message: "Address must be at most 255 characters", fieldMessage: "Address must be at most 255 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", tt.path, nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.language, recorder.Header().Get("Content-Language"))
+			assert.Equal(t, "Accept-Language", recorder.Header().Get("Vary"))
+
+			var envelope apierror.Envelope
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.message, envelope.Error.Message)
+			if tt.fieldMessage != "" {
+				assert.Equal(t, tt.fieldMessage, envelope.Error.Fields[0].Message)
+			}
+		})
+	}
+}
+
+// TestEveryCodeIsTranslated fails when a code declared in code.go has no message in a catalog
+func TestEveryCodeIsTranslated(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "code.go", nil, 0)
+	assert.NoError(t, err)
+
+	var codes []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || spec.Type == nil || spec.Type.(*ast.Ident).Name != "Code" {
+			return true
+		}
+		for _, value := range spec.Values {
+			code, err := strconv.Unquote(value.(*ast.BasicLit).Value)
+			assert.NoError(t, err)
+			codes = append(codes, code)
+		}
+		return true
+	})
+	assert.NotEmpty(t, codes)
+
+	for _, lang := range i18n.Languages {
+		for _, code := range codes {
+			assert.True(t, i18n.Has(lang, code), "%s has no message in %s", code, lang)
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/unknown", nil)
+	recorder := httptest.NewRecorder()
+	apierror.NotFound(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"error": {"code": "not_found", "message": "Not found"}}`, recorder.Body.String())
+}
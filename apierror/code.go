@@ -0,0 +1,116 @@
+package apierror
+
+// Code identifies an error for clients. Codes are part of the API: once released they keep
+// their meaning, so clients can branch on them instead of on messages.
+type Code string
+
+// Codes shared by every endpoint
+const (
+	CodeInternal         Code = "internal_error"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeInvalidPayload   Code = "invalid_payload"
+	CodeInvalidQuery     Code = "invalid_query"
+	CodeValidationFailed Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodePayloadTooLarge  Code = "payload_too_large"
+)
+
+// Codes of the fields listed by a validation error
+const (
+	CodeRequired      Code = "required"
+	CodeInvalidFormat Code = "invalid_format"
+	CodeOutOfRange    Code = "out_of_range"
+	CodeTooLong       Code = "too_long"
+	CodeNotAllowed    Code = "not_allowed"
+	CodeWeakPassword  Code = "weak_password"
+	CodeMismatch      Code = "mismatch"
+	CodeUnknownField  Code = "unknown_field"
+)
+
+// Codes of authentication and sessions
+const (
+	CodeMissingAuthorization      Code = "missing_authorization"
+	CodeInvalidAuthorization      Code = "invalid_authorization"
+	CodeInvalidToken              Code = "invalid_token"
+	CodeSessionRevoked            Code = "session_revoked"
+	CodeInvalidCredentials        Code = "invalid_credentials"
+	CodeTooManyLoginAttempts      Code = "too_many_login_attempts"
+	CodeIncorrectPassword         Code = "incorrect_password"
+	CodeInvalidResetToken         Code = "invalid_reset_token"
+	CodeInvalidMFAToken           Code = "invalid_mfa_token"
+	CodeInvalidMFACode            Code = "invalid_mfa_code"
+	CodeTooManyMFAAttempts        Code = "too_many_mfa_attempts"
+	CodeMFAAlreadyEnabled         Code = "mfa_already_enabled"
+	CodeMFAEnrollmentNotStarted   Code = "mfa_enrollment_not_started"
+	CodeMissingAPIKey             Code = "missing_api_key"
+	CodeInvalidAPIKey             Code = "invalid_api_key"
+	CodeInvalidTimestamp          Code = "invalid_timestamp"
+	CodeTimestampOutsideWindow    Code = "timestamp_outside_window"
+	CodeInvalidNonce              Code = "invalid_nonce"
+	CodeInvalidSignature          Code = "invalid_signature"
+	CodeReplayedRequest           Code = "replayed_request"
+	CodeNIKAlreadyRegistered      Code = "nik_already_registered"
+	CodeCustomerNotFound          Code = "customer_not_found"
+	CodeInvalidCustomerID         Code = "invalid_customer_id"
+	CodeIdentityFieldImmutable    Code = "identity_field_immutable"
+	CodeFieldNotEditable          Code = "field_not_editable"
+	CodeCorrectionNotFound        Code = "correction_not_found"
+	CodeInvalidCorrectionID       Code = "invalid_correction_id"
+	CodeCorrectionAlreadyReviewed Code = "correction_already_reviewed"
+)
+
+// Codes of documents and contracts
+const (
+	CodeInvalidDocumentType        Code = "invalid_document_type"
+	CodeDocumentRequired           Code = "document_required"
+	CodeDocumentTooLarge           Code = "document_too_large"
+	CodeUnsupportedMediaType       Code = "unsupported_media_type"
+	CodeInvalidDocument            Code = "invalid_document"
+	CodeDocumentNotFound           Code = "document_not_found"
+	CodeContractNotFound           Code = "contract_not_found"
+	CodeContractNotBooked          Code = "contract_not_booked"
+	CodeContractDocumentNotFound   Code = "contract_document_not_found"
+	CodeContractAlreadyAccepted    Code = "contract_already_accepted"
+	CodeContractNotAccepted        Code = "contract_not_accepted"
+	CodeContentHashMismatch        Code = "content_hash_mismatch"
+	CodeInvalidMonth               Code = "invalid_month"
+	CodeAmountExceedsOutstanding   Code = "amount_exceeds_outstanding"
+	CodeUnsupportedStatementFormat Code = "unsupported_statement_format"
+)
+
+// Codes of limits, transactions and their pricing
+const (
+	CodeLimitNotFound               Code = "limit_not_found"
+	CodeLimitExceeded               Code = "limit_exceeded"
+	CodeTransactionNotFound         Code = "transaction_not_found"
+	CodeContractNumberExists        Code = "contract_number_exists"
+	CodeInvalidTransactionID        Code = "invalid_transaction_id"
+	CodeTransactionNotPending       Code = "transaction_not_pending"
+	CodeTransactionNotBooked        Code = "transaction_not_booked"
+	CodeTransactionNotCancellable   Code = "transaction_not_cancellable"
+	CodeInvalidConfirmationCode     Code = "invalid_confirmation_code"
+	CodeConfirmationCodeExpired     Code = "confirmation_code_expired"
+	CodeTooManyConfirmationAttempts Code = "too_many_confirmation_attempts"
+	CodeAssetNotFound               Code = "asset_not_found"
+	CodeAssetExists                 Code = "asset_exists"
+	CodeInvalidAssetID              Code = "invalid_asset_id"
+	CodeAssetNotAvailable           Code = "asset_not_available"
+	CodeNoPricingRule               Code = "no_pricing_rule"
+	CodeInvalidVoucher              Code = "invalid_voucher"
+	CodeVoucherNotEligible          Code = "voucher_not_eligible"
+	CodeVoucherUnavailable          Code = "voucher_unavailable"
+	CodeVoucherAlreadyUsed          Code = "voucher_already_used"
+	CodeVoucherCodeExists           Code = "voucher_code_exists"
+	CodeCampaignNotFound            Code = "campaign_not_found"
+	CodeInvalidCampaignID           Code = "invalid_campaign_id"
+)
+
+// Codes of partners
+const (
+	CodePartnerNotFound  Code = "partner_not_found"
+	CodeInvalidPartnerID Code = "invalid_partner_id"
+	CodeConsentNotFound  Code = "consent_not_found"
+	CodeConsentRequired  Code = "consent_required"
+)
@@ -0,0 +1,179 @@
+// Package config loads the settings of the application. Every setting has one name, used as is in
+// the environment (DB_HOST), in lower case in a YAML or TOML file, either flat (db_host) or nested
+// (db: {host: ...}), and in lower case with dashes as a flag (-db-host). Sources override each
+// other in this order:
+//
+//  1. the defaults below
+//  2. the file named by -config or CONFIG_FILE
+//  3. the .env file named by -env-file, when it exists
+//  4. the environment
+//  5. flags
+//
+// The name of a setting is the path of its field in Config in upper snake case, e.g. the
+// MinLength field of Password is PASSWORD_MIN_LENGTH.
+package config
+
+import (
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Config holds the settings of the application
+type Config struct {
+	// Port is the TCP port the API listens on
+	Port     int
+	HTTP     HTTP
+	DB       DB
+	JWT      JWT
+	Password util.PasswordPolicy
+	LoginNIK util.LoginThrottlePolicy
+	LoginIP  util.LoginThrottlePolicy
+	// EncryptionKey is the AES key encrypting documents and partner secrets at rest
+	EncryptionKey Secret
+	BlobStoreDir  string
+	// Notifier selects where messages to customers go, console or file
+	Notifier     string
+	NotifierFile string
+	MFAIssuer    string
+	// MFARequiredForStaff forces officers and admins to log in with a TOTP code
+	MFARequiredForStaff     bool
+	PartnerSignatureMaxSkew time.Duration
+	// AssetOTRTolerancePercent is how far a transaction's OTR may deviate from the asset's reference price range
+	AssetOTRTolerancePercent float64
+	// MetricsAddr is the internal address /metrics is served on, apart from the API as the metrics
+	// include business figures. Empty serves no metrics.
+	MetricsAddr string
+
+	// sources records where each setting that is not a default was read from
+	sources map[string]string
+}
+
+// HTTP holds the limits of the HTTP server and its TLS certificate
+type HTTP struct {
+	// ReadHeaderTimeout bounds reading the headers, ReadTimeout the whole request including its body
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// WriteTimeout bounds the handling of a request, up to the end of the response
+	WriteTimeout time.Duration
+	// IdleTimeout closes kept-alive connections waiting that long for the next request
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	// MaxBodyBytes bounds request bodies, except multipart uploads, which are bounded by MaxUploadBytes
+	MaxBodyBytes   int
+	MaxUploadBytes int
+	// ShutdownDelay keeps serving with readiness failing for that long before shutting down, so the
+	// load balancer stops routing requests first. ShutdownTimeout then bounds how long in-flight
+	// requests and background jobs may take to finish.
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+	// TLSCertFile and TLSKeyFile serve HTTPS when set. The files are read again when they change,
+	// checked every TLSReloadInterval, or on SIGHUP.
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSReloadInterval time.Duration
+}
+
+// DB holds the settings of the MySQL database
+type DB struct {
+	Host     string
+	Port     int
+	User     string
+	Password Secret
+	Name     string
+	// Timeout bounds every repository operation, OperationTimeouts overrides it per operation
+	Timeout           time.Duration
+	OperationTimeouts map[string]time.Duration
+	// MaxRetries and RetryDelay apply to transactions aborted by a deadlock
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// JWT holds the settings of the keys signing and verifying access tokens
+type JWT struct {
+	SigningKeyFile string
+	SigningKeyID   string
+	// VerificationKeys maps the kid of rotated out signing keys to the file of their PEM public key
+	VerificationKeys map[string]string
+	Issuer           string
+	Audience         string
+}
+
+// Secret is a setting that is never written out in full, e.g. when the configuration is dumped
+type Secret string
+
+// String redacts the secret so it does not end up in logs by accident
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "[redacted]"
+}
+
+// Default returns the configuration used when no source sets anything
+func Default() *Config {
+	return &Config{
+		Port: 8080,
+		HTTP: HTTP{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			MaxBodyBytes:      1 << 20,
+			MaxUploadBytes:    8 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			TLSReloadInterval: time.Minute,
+		},
+		DB: DB{
+			Host:       "127.0.0.1",
+			Port:       3306,
+			Timeout:    repository.DefaultTimeouts().Default,
+			MaxRetries: 3,
+			RetryDelay: 50 * time.Millisecond,
+		},
+		JWT: JWT{
+			SigningKeyFile: "keys/jwt_signing.pem",
+			Issuer:         "alif-sigmatech",
+			Audience:       "alif-sigmatech-api",
+		},
+		Password:                 util.DefaultPasswordPolicy(),
+		LoginNIK:                 util.DefaultNIKThrottlePolicy(),
+		LoginIP:                  util.DefaultIPThrottlePolicy(),
+		BlobStoreDir:             "data/blobs",
+		Notifier:                 "console",
+		NotifierFile:             "data/notifications.log",
+		MFAIssuer:                "Sigmatech",
+		MFARequiredForStaff:      true,
+		PartnerSignatureMaxSkew:  5 * time.Minute,
+		AssetOTRTolerancePercent: 5,
+		MetricsAddr:              "127.0.0.1:9090",
+	}
+}
+
+// DSN returns the data source name the MySQL driver connects with. The driver formats it, so
+// credentials containing @, / or : need no escaping.
+func (db DB) DSN() string {
+	cfg := mysql.NewConfig()
+	cfg.User = db.User
+	cfg.Passwd = string(db.Password)
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(db.Host, strconv.Itoa(db.Port))
+	cfg.DBName = db.Name
+	cfg.ParseTime = true
+	return cfg.FormatDSN()
+}
+
+// Timeouts returns the repository timeouts, with the operation timeouts added to the defaults
+func (db DB) Timeouts() repository.Timeouts {
+	timeouts := repository.DefaultTimeouts()
+	timeouts.Default = db.Timeout
+	for operation, timeout := range db.OperationTimeouts {
+		timeouts.Operations[operation] = timeout
+	}
+	return timeouts
+}
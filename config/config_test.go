@@ -0,0 +1,218 @@
+package config_test
+
+import (
+	"alif-sigmatech/config"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// validEnv holds the settings without a usable default
+var validEnv = map[string]string{
+	"DB_USER":            "app",
+	"DB_NAME":            "sigmatech",
+	"JWT_SIGNING_KEY_ID": "2026-10",
+	"ENCRYPTION_KEY":     "k3Y-f0r+AES_128!",
+}
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func withEnv(overrides map[string]string) map[string]string {
+	env := make(map[string]string)
+	for name, value := range validEnv {
+		env[name] = value
+	}
+	for name, value := range overrides {
+		env[name] = value
+	}
+	return env
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func load(args []string, env map[string]string) (*config.Config, error) {
+	// A .env file in the working directory of the tests must not leak into them
+	args = append([]string{"-env-file", filepath.Join(os.TempDir(), "missing.env")}, args...)
+	return config.Load(flag.NewFlagSet("test", flag.ContinueOnError), args, lookupEnv(env))
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		c, err := load(nil, withEnv(nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, c.Port)
+		assert.Equal(t, 3306, c.DB.Port)
+		assert.Equal(t, 10, c.Password.MinLength)
+		assert.Equal(t, 5*time.Second, c.DB.Timeouts().Default)
+		assert.Equal(t, 10*time.Second, c.DB.Timeouts().For("ListTransactions"))
+	})
+
+	t.Run("Nested YAML file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", `
+port: 9090
+db:
+  host: db.internal
+  timeout: 3s
+  operation_timeouts:
+    ListTransactions: 30s
+password:
+  min_length: 12
+  require_symbol: true
+login_nik:
+  lockout_threshold: 5
+`)
+		c, err := load([]string{"-config", path}, withEnv(nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9090, c.Port)
+		assert.Equal(t, "db.internal", c.DB.Host)
+		assert.Equal(t, 3*time.Second, c.DB.Timeouts().Default)
+		assert.Equal(t, 30*time.Second, c.DB.Timeouts().For("ListTransactions"))
+		assert.Equal(t, 12, c.Password.MinLength)
+		assert.True(t, c.Password.RequireSymbol)
+		assert.Equal(t, 5, c.LoginNIK.LockoutThreshold)
+		assert.Equal(t, 50, c.LoginIP.LockoutThreshold)
+	})
+
+	t.Run("Flat TOML file named by CONFIG_FILE", func(t *testing.T) {
+		path := writeFile(t, "config.toml", `
+db_host = "db.internal"
+mfa_required_for_staff = false
+
+[jwt.verification_keys]
+2026-04 = "keys/2026-04.pub.pem"
+`)
+		c, err := load(nil, withEnv(map[string]string{"CONFIG_FILE": path}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "db.internal", c.DB.Host)
+		assert.False(t, c.MFARequiredForStaff)
+		assert.Equal(t, map[string]string{"2026-04": "keys/2026-04.pub.pem"}, c.JWT.VerificationKeys)
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", "port: 7000\ndb_host: file\nnotifier_file: file.log\nmfa_issuer: File\n")
+		dotEnv := writeFile(t, ".env", "PORT=7001\nDB_HOST=dotenv\nNOTIFIER_FILE=dotenv.log\n")
+		env := withEnv(map[string]string{"PORT": "7002", "DB_HOST": "env"})
+
+		c, err := load([]string{"-config", file, "-env-file", dotEnv, "-port", "7003"}, env)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 7003, c.Port)
+		assert.Equal(t, "env", c.DB.Host)
+		assert.Equal(t, "dotenv.log", c.NotifierFile)
+		assert.Equal(t, "File", c.MFAIssuer)
+	})
+
+	t.Run("Malformed values", func(t *testing.T) {
+		_, err := load([]string{"-db-timeout", "soon"}, withEnv(map[string]string{"PORT": "http"}))
+
+		assert.ErrorContains(t, err, "PORT from env: must be an integer")
+		assert.ErrorContains(t, err, "DB_TIMEOUT from flag: must be a duration such as 5s or 15m")
+	})
+
+	t.Run("Unknown setting in file", func(t *testing.T) {
+		path := writeFile(t, "config.yaml", "db:\n  hots: db.internal\n")
+
+		_, err := load([]string{"-config", path}, withEnv(nil))
+
+		assert.ErrorContains(t, err, "unknown setting db_hots")
+	})
+
+	t.Run("Unsupported file format", func(t *testing.T) {
+		path := writeFile(t, "config.json", "{}")
+
+		_, err := load([]string{"-config", path}, withEnv(nil))
+
+		assert.ErrorContains(t, err, "must be YAML (.yaml, .yml) or TOML (.toml)")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		message string
+	}{
+		{name: "Missing encryption key", env: map[string]string{"ENCRYPTION_KEY": ""}, message: "ENCRYPTION_KEY is required"},
+		{name: "Encryption key of the wrong length", env: map[string]string{"ENCRYPTION_KEY": "secret"},
+			message: "ENCRYPTION_KEY must be 16, 24 or 32 bytes long to be an AES key, it is 6"},
+		{name: "Weak encryption key", env: map[string]string{"ENCRYPTION_KEY": "abababababababab"},
+			message: "ENCRYPTION_KEY is too weak, it must have at least 8 different characters"},
+		{name: "Missing signing key ID", env: map[string]string{"JWT_SIGNING_KEY_ID": ""}, message: "JWT_SIGNING_KEY_ID is required"},
+		{name: "Short passwords", env: map[string]string{"PASSWORD_MIN_LENGTH": "4"}, message: "PASSWORD_MIN_LENGTH must be at least 8"},
+		{name: "Unknown notifier", env: map[string]string{"NOTIFIER": "sms"}, message: "NOTIFIER must be console or file"},
+		{name: "Certificate without key", env: map[string]string{"HTTP_TLS_CERT_FILE": "tls.crt"},
+			message: "HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together"},
+		{name: "Port out of range", env: map[string]string{"PORT": "70000"}, message: "PORT must be between 1 and 65535"},
+		{name: "Unknown operation timeout", env: map[string]string{"DB_OPERATION_TIMEOUTS": "ListTransaction=10s"},
+			message: "DB_OPERATION_TIMEOUTS names ListTransaction, which is not a repository operation"},
+		{name: "Metrics address without port", env: map[string]string{"METRICS_ADDR": "127.0.0.1"},
+			message: "METRICS_ADDR must be a host and port such as 127.0.0.1:9090"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(nil, withEnv(tt.env))
+
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+
+	t.Run("Every problem", func(t *testing.T) {
+		_, err := load(nil, map[string]string{})
+
+		assert.ErrorContains(t, err, "DB_USER is required")
+		assert.ErrorContains(t, err, "DB_NAME is required")
+		assert.ErrorContains(t, err, "JWT_SIGNING_KEY_ID is required")
+		assert.ErrorContains(t, err, "ENCRYPTION_KEY is required")
+	})
+}
+
+func TestDump(t *testing.T) {
+	c, err := load([]string{"-db-password", "hunter2"}, withEnv(map[string]string{"DB_OPERATION_TIMEOUTS": "RedeemVoucher=10s,ListTransactions=30s"}))
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	c.Dump(&out)
+
+	assert.Contains(t, out.String(), "PORT=8080 # default\n")
+	assert.Contains(t, out.String(), "DB_USER=app # env\n")
+	assert.Contains(t, out.String(), "DB_PASSWORD=[redacted] # flag\n")
+	assert.Contains(t, out.String(), "DB_OPERATION_TIMEOUTS=ListTransactions=30s,RedeemVoucher=10s # env\n")
+	assert.Contains(t, out.String(), "JWT_SIGNING_KEY_ID=2026-10 # env\n")
+	assert.Contains(t, out.String(), "ENCRYPTION_KEY=[redacted] # env\n")
+	assert.Contains(t, out.String(), "ASSET_OTR_TOLERANCE_PERCENT=5 # default\n")
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.NotContains(t, out.String(), validEnv["ENCRYPTION_KEY"])
+}
+
+func TestDSN(t *testing.T) {
+	c, err := load([]string{"-db-password", "p@ss/w:rd?"}, withEnv(map[string]string{"DB_HOST": "db.internal"}))
+	assert.NoError(t, err)
+
+	parsed, err := mysql.ParseDSN(c.DB.DSN())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "app", parsed.User)
+	assert.Equal(t, "p@ss/w:rd?", parsed.Passwd)
+	assert.Equal(t, "db.internal:3306", parsed.Addr)
+	assert.Equal(t, "sigmatech", parsed.DBName)
+	assert.True(t, parsed.ParseTime)
+}
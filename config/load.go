@@ -0,0 +1,313 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a setting, as reported by Dump
+const (
+	SourceFile    = "file"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	sourceDefault = "default"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting is a field of Config together with its name
+type setting struct {
+	name  string
+	value reflect.Value
+}
+
+// Load reads the configuration from the sources in order of precedence and validates it. Flags
+// of every setting, -config and -env-file are added to flags before args are parsed, so callers
+// may add flags of their own beforehand. Environment variables are read with lookupEnv.
+func Load(flags *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	c := Default()
+	settings := c.settings()
+
+	configFile := flags.String("config", "", "YAML or TOML file to read settings from, instead of CONFIG_FILE")
+	envFile := flags.String("env-file", ".env", "file of environment variables to read when it exists")
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		name := s.name
+		flags.Func(flagName(name), "sets "+name, func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	var fileValues map[string]string
+	if path != "" {
+		fileValues, err = readFile(path, settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dotEnvValues, err := godotenv.Read(*envFile)
+	if errors.Is(err, os.ErrNotExist) {
+		dotEnvValues = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %w", *envFile, err)
+	}
+
+	envValues := make(map[string]string)
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.name); ok {
+			envValues[s.name] = value
+		}
+	}
+
+	c.sources = make(map[string]string)
+	var errs []error
+	for _, source := range []struct {
+		name   string
+		values map[string]string
+	}{
+		{SourceFile, fileValues},
+		{SourceDotEnv, dotEnvValues},
+		{SourceEnv, envValues},
+		{SourceFlag, flagValues},
+	} {
+		errs = append(errs, c.apply(settings, source.name, source.values))
+	}
+	err = errors.Join(errs...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// apply sets the settings named in values. Names that are not settings are ignored, as the
+// environment and .env files hold variables of other programs too.
+func (c *Config) apply(settings []setting, source string, values map[string]string) error {
+	var errs []error
+	for _, s := range settings {
+		raw, ok := values[s.name]
+		if !ok {
+			continue
+		}
+		err := parse(s.value, raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s from %s: %w", s.name, source, err))
+			continue
+		}
+		c.sources[s.name] = source
+	}
+	return errors.Join(errs...)
+}
+
+// settings lists the fields of the configuration in declaration order
+func (c *Config) settings() []setting {
+	var settings []setting
+	collectSettings(reflect.ValueOf(c).Elem(), "", &settings)
+	return settings
+}
+
+func collectSettings(v reflect.Value, prefix string, settings *[]setting) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + upperSnakeCase(field.Name)
+		if field.Type.Kind() == reflect.Struct {
+			collectSettings(v.Field(i), name+"_", settings)
+			continue
+		}
+		*settings = append(*settings, setting{name: name, value: v.Field(i)})
+	}
+}
+
+// upperSnakeCase turns the name of a field into the name of its setting, e.g. SigningKeyID into SIGNING_KEY_ID
+func upperSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previousLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			endOfAcronym := unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || endOfAcronym {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// flagName returns the flag of a setting, e.g. -db-host for DB_HOST
+func flagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// parse sets a field from the text of a setting. Lists of pairs are written as "key=value,key=value".
+func parse(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 5s or 15m")
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(raw)
+	case v.Kind() == reflect.Int:
+		i, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		v.SetInt(int64(i))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, entry := range strings.Split(raw, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(entry, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("entry %q must be formatted as key=value", entry)
+			}
+			element := reflect.New(v.Type().Elem()).Elem()
+			err := parse(element, value)
+			if err != nil {
+				return fmt.Errorf("entry %q: %w", entry, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(key), element)
+		}
+		v.Set(m)
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %s", v.Type()))
+	}
+	return nil
+}
+
+// format writes the value of a setting the way parse reads it, with secrets redacted
+func format(v reflect.Value) string {
+	if v.Kind() != reflect.Map {
+		// Durations and secrets have a String method writing them as such
+		return fmt.Sprint(v.Interface())
+	}
+
+	entries := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		entries = append(entries, key.String()+"="+format(v.MapIndex(key)))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// readFile reads the settings of a YAML or TOML file, chosen by its extension
+func readFile(path string, settings []setting) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &document)
+	case ".toml":
+		err = toml.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("%s: configuration files must be YAML (.yaml, .yml) or TOML (.toml)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	known := make(map[string]reflect.Kind)
+	for _, s := range settings {
+		known[s.name] = s.value.Kind()
+	}
+	values := make(map[string]string)
+	err = flatten("", document, known, values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten turns the nested tables of a file into settings, e.g. min_length in the password table
+// into PASSWORD_MIN_LENGTH. Unlike the environment, a file only holds settings, so unknown names
+// are reported as the typos they most likely are.
+func flatten(name string, value interface{}, known map[string]reflect.Kind, values map[string]string) error {
+	table, isTable := value.(map[string]interface{})
+	if isTable && known[name] != reflect.Map {
+		for key, child := range table {
+			childName := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+			if name != "" {
+				childName = name + "_" + childName
+			}
+			err := flatten(childName, child, known, values)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, ok := known[name]; !ok {
+		return fmt.Errorf("unknown setting %s", strings.ToLower(name))
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		entries := make([]string, 0, len(value))
+		for key, element := range value {
+			entries = append(entries, fmt.Sprintf("%s=%v", key, element))
+		}
+		sort.Strings(entries)
+		values[name] = strings.Join(entries, ",")
+	case nil:
+		values[name] = ""
+	default:
+		values[name] = fmt.Sprint(value)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"alif-sigmatech/repository"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+// minEncryptionKeyCharacters is how many different characters an encryption key must have at
+// least, which rejects placeholders such as 0123456789abcdef repeated or a single repeated letter
+const minEncryptionKeyCharacters = 8
+
+// Validate reports every setting that is missing, malformed or too weak to start with
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port <= 65535, "PORT must be between 1 and 65535")
+	check(c.HTTP.ReadHeaderTimeout > 0, "HTTP_READ_HEADER_TIMEOUT must be positive")
+	check(c.HTTP.ReadTimeout > 0, "HTTP_READ_TIMEOUT must be positive")
+	check(c.HTTP.WriteTimeout > 0, "HTTP_WRITE_TIMEOUT must be positive")
+	check(c.HTTP.IdleTimeout > 0, "HTTP_IDLE_TIMEOUT must be positive")
+	check(c.HTTP.ShutdownDelay >= 0, "HTTP_SHUTDOWN_DELAY must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "HTTP_SHUTDOWN_TIMEOUT must be positive")
+	check(c.HTTP.MaxHeaderBytes > 0, "HTTP_MAX_HEADER_BYTES must be positive")
+	check(c.HTTP.MaxBodyBytes > 0, "HTTP_MAX_BODY_BYTES must be positive")
+	check(c.HTTP.MaxUploadBytes > 0, "HTTP_MAX_UPLOAD_BYTES must be positive")
+	check((c.HTTP.TLSCertFile == "") == (c.HTTP.TLSKeyFile == ""), "HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE must be set together")
+	check(c.HTTP.TLSCertFile == "" || c.HTTP.TLSReloadInterval > 0, "HTTP_TLS_RELOAD_INTERVAL must be positive")
+
+	check(c.DB.Host != "", "DB_HOST is required")
+	check(c.DB.Port > 0 && c.DB.Port <= 65535, "DB_PORT must be between 1 and 65535")
+	check(c.DB.User != "", "DB_USER is required")
+	check(c.DB.Name != "", "DB_NAME is required")
+	check(c.DB.Timeout > 0, "DB_TIMEOUT must be positive")
+	for operation, timeout := range c.DB.OperationTimeouts {
+		check(repository.IsOperation(operation), "DB_OPERATION_TIMEOUTS names %s, which is not a repository operation", operation)
+		check(timeout > 0, "DB_OPERATION_TIMEOUTS of %s must be positive", operation)
+	}
+	check(c.DB.MaxRetries >= 0, "DB_MAX_RETRIES must not be negative")
+	check(c.DB.RetryDelay >= 0, "DB_RETRY_DELAY must not be negative")
+
+	check(c.JWT.SigningKeyFile != "", "JWT_SIGNING_KEY_FILE is required")
+	check(c.JWT.SigningKeyID != "", "JWT_SIGNING_KEY_ID is required")
+	check(c.JWT.Issuer != "", "JWT_ISSUER is required")
+	check(c.JWT.Audience != "", "JWT_AUDIENCE is required")
+
+	// Documents are encrypted with AES, which only takes keys of 128, 192 or 256 bits
+	keyLength := len(c.EncryptionKey)
+	if keyLength == 0 {
+		check(false, "ENCRYPTION_KEY is required")
+	} else if keyLength != 16 && keyLength != 24 && keyLength != 32 {
+		check(false, "ENCRYPTION_KEY must be 16, 24 or 32 bytes long to be an AES key, it is %d", keyLength)
+	} else {
+		check(distinctCharacters(string(c.EncryptionKey)) >= minEncryptionKeyCharacters,
+			"ENCRYPTION_KEY is too weak, it must have at least %d different characters", minEncryptionKeyCharacters)
+	}
+
+	check(c.Password.MinLength >= 8, "PASSWORD_MIN_LENGTH must be at least 8")
+	for prefix, policy := range map[string]struct {
+		freeAttempts, lockoutThreshold int
+	}{
+		"LOGIN_NIK": {c.LoginNIK.FreeAttempts, c.LoginNIK.LockoutThreshold},
+		"LOGIN_IP":  {c.LoginIP.FreeAttempts, c.LoginIP.LockoutThreshold},
+	} {
+		check(policy.freeAttempts >= 0, "%s_FREE_ATTEMPTS must not be negative", prefix)
+		check(policy.lockoutThreshold > 0, "%s_LOCKOUT_THRESHOLD must be positive", prefix)
+	}
+
+	check(c.Notifier == "console" || c.Notifier == "file", "NOTIFIER must be console or file")
+	check(c.Notifier != "file" || c.NotifierFile != "", "NOTIFIER_FILE is required when NOTIFIER is file")
+	check(c.BlobStoreDir != "", "BLOB_STORE_DIR is required")
+	check(c.PartnerSignatureMaxSkew > 0, "PARTNER_SIGNATURE_MAX_SKEW must be positive")
+	if c.MetricsAddr != "" {
+		_, _, err := net.SplitHostPort(c.MetricsAddr)
+		check(err == nil, "METRICS_ADDR must be a host and port such as 127.0.0.1:9090")
+	}
+	check(c.AssetOTRTolerancePercent >= 0 && c.AssetOTRTolerancePercent <= 100, "ASSET_OTR_TOLERANCE_PERCENT must be between 0 and 100")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func distinctCharacters(s string) int {
+	characters := make(map[rune]bool)
+	for _, r := range s {
+		characters[r] = true
+	}
+	return len(characters)
+}
+
+// Dump writes every setting as NAME=value with the source it was read from, secrets redacted,
+// for diagnosing which value a setting ended up with
+func (c *Config) Dump(w io.Writer) {
+	for _, s := range c.settings() {
+		source, ok := c.sources[s.name]
+		if !ok {
+			source = sourceDefault
+		}
+		fmt.Fprintf(w, "%s=%s # %s\n", s.name, format(s.value), source)
+	}
+}
@@ -0,0 +1,135 @@
+// Package contract renders the agreement documents customers accept before a contract is booked.
+package contract
+
+import (
+	"alif-sigmatech/model"
+	"alif-sigmatech/pdf"
+	"alif-sigmatech/util"
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// CurrentTemplate is the template new contract documents are rendered with. Published templates
+// are never edited: changed terms get a new template so earlier documents can be rendered again.
+const CurrentTemplate = "loan_agreement_v1"
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"amount": util.FormatAmount,
+	"date":   func(t time.Time) string { return t.Format("2006-01-02") },
+}).ParseFS(templateFiles, "templates/*.tmpl"))
+
+// Data fills a contract template
+type Data struct {
+	Template    string
+	Customer    model.Customer
+	Limit       model.Limit
+	Transaction model.Transaction
+	GeneratedAt time.Time
+}
+
+// Financed is the part of the OTR the customer borrows
+func (d Data) Financed() float64 {
+	return d.Transaction.OTR - d.Transaction.DownPayment
+}
+
+// TotalPayable is the sum of all installments
+func (d Data) TotalPayable() float64 {
+	return d.Transaction.InstallmentAmount * float64(d.Transaction.Tenor)
+}
+
+// TenorLimit is the customer's limit for the tenor of the transaction
+func (d Data) TenorLimit() float64 {
+	switch d.Transaction.Tenor {
+	case 1:
+		return d.Limit.Tenor1
+	case 2:
+		return d.Limit.Tenor2
+	case 3:
+		return d.Limit.Tenor3
+	case 4:
+		return d.Limit.Tenor4
+	default:
+		return 0
+	}
+}
+
+// Render renders the contract document as a PDF and returns it with the hex SHA-256 hash of its content
+func Render(data Data) ([]byte, string, error) {
+	tmpl := templates.Lookup(data.Template + ".tmpl")
+	if tmpl == nil {
+		return nil, "", fmt.Errorf("unknown contract template %q", data.Template)
+	}
+
+	var text bytes.Buffer
+	err := tmpl.Execute(&text, data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	doc := pdf.New()
+	for _, line := range strings.Split(strings.TrimRight(text.String(), "\n"), "\n") {
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			doc.Bold(heading)
+			continue
+		}
+		if line == "" {
+			doc.Blank()
+			continue
+		}
+		if len(line) <= pdf.LineWidth {
+			doc.Text(line)
+			continue
+		}
+		for _, wrapped := range wrap(line, pdf.LineWidth) {
+			doc.Text(wrapped)
+		}
+	}
+
+	var out bytes.Buffer
+	_, err = doc.WriteTo(&out)
+	if err != nil {
+		return nil, "", err
+	}
+
+	hash := sha256.Sum256(out.Bytes())
+	return out.Bytes(), hex.EncodeToString(hash[:]), nil
+}
+
+// wrap breaks a line into lines of at most width characters at spaces. Words longer
+// than the width are split.
+func wrap(line string, width int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(line) {
+		for len(word) > width {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
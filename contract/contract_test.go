@@ -0,0 +1,75 @@
+package contract
+
+import (
+	"alif-sigmatech/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func newTestData() Data {
+	return Data{
+		Template: CurrentTemplate,
+		Customer: model.Customer{ID: 1, NIK: "3171234567890001", FullName: "Budi", LegalName: "Budi Santoso",
+			BirthPlace: "Jakarta", BirthDate: "1990-01-01", Address: "Jl. Sudirman No. 1 (Blok A), Jakarta", PhoneNumber: "+6281234567890"},
+		Limit: model.Limit{CustomerID: 1, Tenor1: 5000000, Tenor2: 10000000, Tenor3: 15000000, Tenor4: 20000000},
+		Transaction: model.Transaction{ID: 10, CustomerID: 1, ContractNumber: "KTR-001", AssetName: "Honda Vario 160",
+			OTR: 20000000, DownPayment: 4000000, AdminFee: 0, InterestAmount: 640000, InstallmentAmount: 4160000, Tenor: 4,
+			PromoCode: "ZEROFEE", DiscountAmount: 100000},
+		GeneratedAt: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRender(t *testing.T) {
+	document, hash, err := Render(newTestData())
+	assert.NoError(t, err)
+
+	sum := sha256.Sum256(document)
+	assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+
+	// Rendering is deterministic, so the hash proves which terms were shown
+	_, again, err := Render(newTestData())
+	assert.NoError(t, err)
+	assert.Equal(t, hash, again)
+
+	changed := newTestData()
+	changed.Transaction.InstallmentAmount++
+	_, other, err := Render(changed)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	path := filepath.Join("testdata", CurrentTemplate+".pdf")
+	if *update {
+		assert.NoError(t, os.WriteFile(path, document, 0644))
+	}
+	expected, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(document))
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	data := newTestData()
+	data.Template = "loan_agreement_v0"
+
+	_, _, err := Render(data)
+	assert.Error(t, err)
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"one two", "three"}, wrap("one two three", 8))
+	assert.Equal(t, []string{"abcd", "efgh", "ij k"}, wrap("abcdefghij k", 4))
+	assert.Empty(t, wrap("   ", 10))
+
+	for _, line := range wrap(strings.Repeat("word ", 50), 90) {
+		assert.LessOrEqual(t, len(line), 90)
+	}
+}
@@ -7,6 +7,15 @@ CREATE TABLE customer (
     birth_place VARCHAR(100),
     birth_date DATE,
     salary DECIMAL(15, 2),
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    phone_number VARCHAR(20) NOT NULL DEFAULT '',
+    language VARCHAR(5) NOT NULL DEFAULT '',
+    role VARCHAR(20) NOT NULL DEFAULT 'customer',
+    risk_grade VARCHAR(10) NOT NULL DEFAULT '',
+    token_version INT NOT NULL DEFAULT 0,
+    mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    mfa_secret VARBINARY(255),
+    mfa_last_step BIGINT,
     ktp_photo BLOB,
     selfie_photo BLOB,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -25,6 +34,73 @@ CREATE TABLE `limit` (
     FOREIGN KEY (customer_id) REFERENCES customer(id)
 );
 
+CREATE TABLE asset (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    category VARCHAR(50) NOT NULL,
+    brand VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    min_otr DECIMAL(15, 2) NOT NULL,
+    max_otr DECIMAL(15, 2) NOT NULL,
+    max_finance_percent DECIMAL(5, 2) NOT NULL,
+    allowed_tenors VARCHAR(50) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uq_asset (category, brand, model)
+);
+
+CREATE TABLE partner (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    api_key_prefix VARCHAR(16) NOT NULL,
+    api_key_hash CHAR(64) NOT NULL UNIQUE,
+    signing_secret VARBINARY(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE partner_consent (
+    customer_id INT NOT NULL,
+    partner_id INT NOT NULL,
+    granted_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    PRIMARY KEY (customer_id, partner_id),
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (partner_id) REFERENCES partner(id)
+);
+
+CREATE TABLE partner_nonce (
+    partner_id INT NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (partner_id, nonce),
+    INDEX idx_partner_nonce_expires (partner_id, expires_at),
+    FOREIGN KEY (partner_id) REFERENCES partner(id)
+);
+
+CREATE TABLE pricing_rule (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    tenor INT NOT NULL DEFAULT 0,
+    asset_category VARCHAR(50) NOT NULL DEFAULT '',
+    partner_id INT,
+    risk_grade VARCHAR(10) NOT NULL DEFAULT '',
+    promo_code VARCHAR(50) NOT NULL DEFAULT '',
+    priority INT NOT NULL DEFAULT 0,
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    admin_fee_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    monthly_interest_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    effective_from DATETIME NOT NULL,
+    effective_to DATETIME,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_pricing_rule_version (code, version),
+    FOREIGN KEY (partner_id) REFERENCES partner(id),
+    INDEX idx_pricing_rule_effective (effective_from, effective_to)
+);
+
 CREATE TABLE transaction (
     id INT AUTO_INCREMENT PRIMARY KEY,
     customer_id INT NOT NULL,
@@ -33,9 +109,177 @@ CREATE TABLE transaction (
     admin_fee DECIMAL(15, 2),
     installment_amount DECIMAL(15, 2),
     interest_amount DECIMAL(15, 2),
+    asset_id INT,
     asset_name VARCHAR(100),
+    down_payment DECIMAL(15, 2) NOT NULL DEFAULT 0,
     tenor INT,
+    status VARCHAR(20) NOT NULL DEFAULT 'confirmed',
+    otp_hash CHAR(64),
+    otp_attempts INT NOT NULL DEFAULT 0,
+    otp_expires_at DATETIME,
+    confirmed_at DATETIME,
+    cancelled_at DATETIME,
+    channel VARCHAR(20) NOT NULL DEFAULT 'app',
+    partner_id INT,
+    promo_code VARCHAR(50) NOT NULL DEFAULT '',
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    pricing_rule_id INT,
+    pricing_rule_version INT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (partner_id) REFERENCES partner(id),
+    FOREIGN KEY (asset_id) REFERENCES asset(id),
+    FOREIGN KEY (pricing_rule_id) REFERENCES pricing_rule(id),
+    INDEX idx_transaction_channel (channel, partner_id),
+    -- Listings are keyset paginated on (sort column, id), filtered by customer for /fund/transactions
+    INDEX idx_transaction_customer_created (customer_id, created_at, id),
+    INDEX idx_transaction_customer_otr (customer_id, otr, id),
+    INDEX idx_transaction_created (created_at, id),
+    INDEX idx_transaction_otr (otr, id),
+    INDEX idx_transaction_installment (installment_amount, id),
+    INDEX idx_transaction_status_created (status, created_at, id),
+    INDEX idx_transaction_partner_created (partner_id, created_at, id),
+    UNIQUE KEY uq_transaction_contract (customer_id, contract_number)
+);
+
+CREATE TABLE contract_document (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL UNIQUE,
+    customer_id INT NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    accepted_at DATETIME,
+    accepted_hash CHAR(64),
+    accepted_ip VARCHAR(45),
+    accepted_user_agent VARCHAR(255),
+    FOREIGN KEY (transaction_id) REFERENCES transaction(id),
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
+
+CREATE TABLE payment (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    paid_at DATETIME NOT NULL,
+    recorded_by INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transaction(id),
+    FOREIGN KEY (recorded_by) REFERENCES customer(id),
+    INDEX idx_payment_transaction (transaction_id, paid_at)
+);
+
+CREATE TABLE promo_campaign (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    admin_fee_discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    interest_discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    tenor INT NOT NULL DEFAULT 0,
+    asset_category VARCHAR(50) NOT NULL DEFAULT '',
+    channel VARCHAR(20) NOT NULL DEFAULT '',
+    min_financed_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME,
+    budget DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    budget_used DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    max_redemptions INT NOT NULL DEFAULT 0,
+    redemptions INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE promo_redemption (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    customer_id INT NOT NULL,
+    transaction_id INT NOT NULL UNIQUE,
+    discount_amount DECIMAL(15, 2) NOT NULL,
+    redeemed_at DATETIME NOT NULL,
+    reversed_at DATETIME,
+    FOREIGN KEY (campaign_id) REFERENCES promo_campaign(id),
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction(id),
+    INDEX idx_promo_redemption_customer (campaign_id, customer_id)
+);
+
+CREATE TABLE document_access_log (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    officer_id INT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45),
+    accessed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (officer_id) REFERENCES customer(id)
+);
+
+CREATE TABLE customer_document (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size INT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_customer_document_type (customer_id, document_type),
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
+
+CREATE TABLE correction_request (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    field_name VARCHAR(50) NOT NULL,
+    requested_value VARCHAR(255) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by INT,
+    review_note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP NULL,
+    INDEX idx_correction_request_status (status),
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (reviewed_by) REFERENCES customer(id)
+);
+
+CREATE TABLE password_reset_token (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
+
+CREATE TABLE login_attempt (
+    attempt_key VARCHAR(100) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE mfa_recovery_code (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_mfa_recovery_code (customer_id, code_hash),
     FOREIGN KEY (customer_id) REFERENCES customer(id)
 );
+
+-- The version of this schema, checked by /readyz against repository.SchemaVersion. Every change
+-- to the schema also ships as a migration in migrations/ and bumps the version here.
+CREATE TABLE schema_migrations (
+    version INT PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schema_migrations (version) VALUES (19);
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// AssetHandler handles HTTP requests related to the asset catalogue
+type AssetHandler struct {
+	AssetRepo repository.AssetRepository
+}
+
+// NewAssetHandler creates a new instance of AssetHandler
+func NewAssetHandler(assetRepo repository.AssetRepository) *AssetHandler {
+	return &AssetHandler{
+		AssetRepo: assetRepo,
+	}
+}
+
+// ListAssets returns the assets customers can finance, optionally filtered by category
+func (h *AssetHandler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	h.listAssets(w, r, true)
+}
+
+// ListCatalogue returns the whole catalogue including deactivated assets, optionally filtered by category
+func (h *AssetHandler) ListCatalogue(w http.ResponseWriter, r *http.Request) {
+	h.listAssets(w, r, false)
+}
+
+func (h *AssetHandler) listAssets(w http.ResponseWriter, r *http.Request, activeOnly bool) {
+	category := strings.ToLower(r.URL.Query().Get("category"))
+
+	assets, err := h.AssetRepo.ListAssets(r.Context(), category, activeOnly)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to list assets")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assets)
+}
+
+// GetAsset returns a single catalogue entry
+func (h *AssetHandler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAssetID, "Invalid asset ID")
+		return
+	}
+
+	asset, err := h.AssetRepo.GetAssetByID(r.Context(), id)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get asset")
+		return
+	}
+	if asset == nil {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeAssetNotFound, "Asset not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(asset)
+}
+
+// CreateAsset adds an asset to the catalogue
+func (h *AssetHandler) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	var asset model.Asset
+	if !decodeJSON(w, r, &asset) {
+		return
+	}
+
+	normalizeAsset(&asset)
+	err := validateAssetInput(asset)
+	if err != nil {
+		writeValidationError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	asset.ID = 0
+	asset.Active = true
+	asset.CreatedAt = now
+	asset.UpdatedAt = now
+
+	err = h.AssetRepo.CreateAsset(r.Context(), &asset)
+	if errors.Is(err, util.ErrDuplicate) {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeAssetExists, "Asset already exists")
+		return
+	}
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create asset")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(asset)
+}
+
+// UpdateAsset replaces a catalogue entry. Setting active to true reinstates a deactivated asset.
+func (h *AssetHandler) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAssetID, "Invalid asset ID")
+		return
+	}
+
+	var update model.Asset
+	if !decodeJSON(w, r, &update) {
+		return
+	}
+
+	normalizeAsset(&update)
+	err = validateAssetInput(update)
+	if err != nil {
+		writeValidationError(w, r, err)
+		return
+	}
+
+	asset, err := h.AssetRepo.GetAssetByID(r.Context(), id)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update asset")
+		return
+	}
+	if asset == nil {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeAssetNotFound, "Asset not found")
+		return
+	}
+
+	update.ID = asset.ID
+	update.CreatedAt = asset.CreatedAt
+	update.UpdatedAt = time.Now()
+
+	err = h.AssetRepo.UpdateAsset(r.Context(), &update)
+	if errors.Is(err, util.ErrDuplicate) {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeAssetExists, "Asset already exists")
+		return
+	}
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to update asset")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(update)
+}
+
+// DeleteAsset deactivates an asset so no new transactions can finance it.
+// Existing transactions keep referring to it.
+func (h *AssetHandler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidAssetID, "Invalid asset ID")
+		return
+	}
+
+	deactivated, err := h.AssetRepo.DeactivateAsset(r.Context(), id)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to delete asset")
+		return
+	}
+	if !deactivated {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeAssetNotFound, "Asset not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func normalizeAsset(asset *model.Asset) {
+	asset.Category = strings.ToLower(strings.TrimSpace(asset.Category))
+	asset.Brand = strings.TrimSpace(asset.Brand)
+	asset.Model = strings.TrimSpace(asset.Model)
+}
+
+func validateAssetInput(asset model.Asset) error {
+	v := validation.New()
+	if v.Required("category", asset.Category) {
+		v.MaxLength("category", asset.Category, 50)
+	}
+	if v.Required("brand", asset.Brand) {
+		v.MaxLength("brand", asset.Brand, 100)
+	}
+	if v.Required("model", asset.Model) {
+		v.MaxLength("model", asset.Model, 100)
+	}
+	if v.Positive("min_otr", asset.MinOTR) {
+		v.Check(asset.MaxOTR >= asset.MinOTR, "max_otr", apierror.CodeOutOfRange, "not_less_than", i18n.Params{"other": "MinOTR"})
+	}
+	v.Check(asset.MaxFinancePercent > 0 && asset.MaxFinancePercent <= 100, "max_finance_percent", apierror.CodeOutOfRange,
+		"above_and_at_most", i18n.Params{"min": "0", "max": "100"})
+	if v.Check(len(asset.AllowedTenors) > 0, "allowed_tenors", apierror.CodeRequired, "", nil) {
+		for _, tenor := range asset.AllowedTenors {
+			v.Between("allowed_tenors", float64(tenor), 1, 4)
+		}
+	}
+	return v.Err()
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAsset() *model.Asset {
+	return &model.Asset{
+		ID:                1,
+		Category:          "motorcycle",
+		Brand:             "Honda",
+		Model:             "Vario 160",
+		MinOTR:            19000000,
+		MaxOTR:            21000000,
+		MaxFinancePercent: 80,
+		AllowedTenors:     []int{1, 2, 3, 4},
+		Active:            true,
+	}
+}
+
+func TestCreateAsset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAssetRepo := mocks.NewMockAssetRepository(ctrl)
+	h := NewAssetHandler(mockAssetRepo)
+
+	tests := []struct {
+		name               string
+		body               string
+		setup              func()
+		expectedStatusCode int
+	}{
+		{
+			name: "Success",
+			body: `{"category": " Motorcycle ", "brand": "Honda", "model": "Vario 160", "min_otr": 19000000, "max_otr": 21000000, "max_finance_percent": 80, "allowed_tenors": [1, 2, 3]}`,
+			setup: func() {
+				mockAssetRepo.EXPECT().CreateAsset(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, asset *model.Asset) error {
+					assert.Equal(t, "motorcycle", asset.Category)
+					assert.True(t, asset.Active)
+					return nil
+				})
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Inverted OTR range",
+			body:               `{"category": "motorcycle", "brand": "Honda", "model": "Vario 160", "min_otr": 21000000, "max_otr": 19000000, "max_finance_percent": 80, "allowed_tenors": [1]}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Finance percentage above 100",
+			body:               `{"category": "motorcycle", "brand": "Honda", "model": "Vario 160", "min_otr": 19000000, "max_otr": 21000000, "max_finance_percent": 120, "allowed_tenors": [1]}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Unsupported tenor",
+			body:               `{"category": "motorcycle", "brand": "Honda", "model": "Vario 160", "min_otr": 19000000, "max_otr": 21000000, "max_finance_percent": 80, "allowed_tenors": [6]}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			req, _ := http.NewRequest("POST", "/admin/assets", bytes.NewBufferString(tt.body))
+			recorder := httptest.NewRecorder()
+			h.CreateAsset(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+		})
+	}
+
+	t.Run("Every invalid field", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/admin/assets", bytes.NewBufferString(`{"category": "motorcycle", "min_otr": 0, "max_finance_percent": 120, "allowed_tenors": [6]}`))
+		recorder := httptest.NewRecorder()
+		h.CreateAsset(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response apierror.Envelope
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, apierror.CodeValidationFailed, response.Error.Code)
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "brand", Code: apierror.CodeRequired, Message: "Brand is required"},
+			{Field: "model", Code: apierror.CodeRequired, Message: "Model is required"},
+			{Field: "min_otr", Code: apierror.CodeOutOfRange, Message: "MinOTR must be positive"},
+			{Field: "max_finance_percent", Code: apierror.CodeOutOfRange, Message: "MaxFinancePercent must be greater than 0 and at most 100"},
+			{Field: "allowed_tenors", Code: apierror.CodeOutOfRange, Message: "AllowedTenors must be between 1 and 4"},
+		}, response.Error.Fields)
+	})
+}
+
+func TestUpdateAndDeleteAsset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAssetRepo := mocks.NewMockAssetRepository(ctrl)
+	h := NewAssetHandler(mockAssetRepo)
+
+	t.Run("Update", func(t *testing.T) {
+		mockAssetRepo.EXPECT().GetAssetByID(gomock.Any(), 1).Return(newTestAsset(), nil)
+		mockAssetRepo.EXPECT().UpdateAsset(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, asset *model.Asset) error {
+			assert.Equal(t, 1, asset.ID)
+			assert.Equal(t, 22000000.0, asset.MaxOTR)
+			return nil
+		})
+
+		body := `{"category": "motorcycle", "brand": "Honda", "model": "Vario 160", "min_otr": 19000000, "max_otr": 22000000, "max_finance_percent": 80, "allowed_tenors": [1, 2], "active": true}`
+		req, _ := http.NewRequest("PUT", "/admin/assets/1", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		recorder := httptest.NewRecorder()
+		h.UpdateAsset(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("Update to an existing asset", func(t *testing.T) {
+		mockAssetRepo.EXPECT().GetAssetByID(gomock.Any(), 1).Return(newTestAsset(), nil)
+		mockAssetRepo.EXPECT().UpdateAsset(gomock.Any(), gomock.Any()).Return(util.ErrDuplicate)
+
+		body := `{"category": "motorcycle", "brand": "Honda", "model": "Beat", "min_otr": 17000000, "max_otr": 19000000, "max_finance_percent": 80, "allowed_tenors": [1, 2], "active": true}`
+		req, _ := http.NewRequest("PUT", "/admin/assets/1", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		recorder := httptest.NewRecorder()
+		h.UpdateAsset(recorder, req)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), string(apierror.CodeAssetExists))
+	})
+
+	t.Run("Delete deactivates", func(t *testing.T) {
+		mockAssetRepo.EXPECT().DeactivateAsset(gomock.Any(), 1).Return(true, nil)
+
+		req, _ := http.NewRequest("DELETE", "/admin/assets/1", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		recorder := httptest.NewRecorder()
+		h.DeleteAsset(recorder, req)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("Delete unknown asset", func(t *testing.T) {
+		mockAssetRepo.EXPECT().DeactivateAsset(gomock.Any(), 2).Return(false, nil)
+
+		req, _ := http.NewRequest("DELETE", "/admin/assets/2", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "2"})
+		recorder := httptest.NewRecorder()
+		h.DeleteAsset(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -1,163 +1,191 @@
 package handler
 
 import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
 	"alif-sigmatech/model"
-	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
 	"alif-sigmatech/util"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthHandler struct {
-	CustomerRepo  repository.CustomerRepository
-	JWTSecret     []byte
-	EncryptionKey []byte
+	// Auth checks credentials and manages passwords
+	Auth service.AuthService
+	// Customers registers new consumers
+	Customers service.CustomerService
+	Keys      *util.KeySet
 }
 
+// Lifetimes of the tokens issued by AuthHandler
+const (
+	accessTokenTTL = 30 * time.Minute
+	mfaTokenTTL    = 5 * time.Minute
+)
+
 // NewAuthHandler creates a new instance of AuthHandler
-func NewAuthHandler(repo repository.CustomerRepository, jwtSecret []byte,
-	EncryptionKey []byte) *AuthHandler {
+func NewAuthHandler(auth service.AuthService, customers service.CustomerService, keys *util.KeySet) *AuthHandler {
 	return &AuthHandler{
-		CustomerRepo:  repo,
-		JWTSecret:     jwtSecret,
-		EncryptionKey: EncryptionKey,
+		Auth:      auth,
+		Customers: customers,
+		Keys:      keys,
 	}
 }
 
 // RegisterCustomer handles registration of a new consumer
 func (h *AuthHandler) RegisterCustomer(w http.ResponseWriter, r *http.Request) {
-	var customer model.Customer
-	err := json.NewDecoder(r.Body).Decode(&customer)
-	if err != nil {
-		logrus.Error(err)
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	var input service.RegisterCustomerInput
+	if !decodeJSON(w, r, &input) {
 		return
 	}
 
-	err = h.validateRegisterCustomerInput(customer)
+	customer, err := h.Customers.Register(r.Context(), input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeServiceError(w, r, err, "Failed to register consumer")
 		return
 	}
 
-	if customer.KTPPhoto != nil && len(customer.KTPPhoto) > 0 {
-		// Encrypt sensitive data before storing it on the database
-		encryptedKTPPhoto, err := util.EncryptData(customer.KTPPhoto, h.EncryptionKey)
-		if err != nil {
-			logrus.Error(err)
-			http.Error(w, "Failed to encrypt KTP photo", http.StatusInternalServerError)
-			return
-		}
-		customer.KTPPhoto = encryptedKTPPhoto
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var credentials model.AuthLogin
+	if !decodeJSON(w, r, &credentials) {
+		return
 	}
 
-	// Hash the password before storing it
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(customer.Password), bcrypt.DefaultCost)
+	result, err := h.Auth.Login(r.Context(), credentials, util.ClientIP(r))
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to log in")
 		return
 	}
-	customer.Password = string(hashedPassword)
 
-	cust, err := h.CustomerRepo.GetCustomerByNIK(customer.NIK)
+	var response model.LoginResponse
+	switch result.TokenPurpose {
+	case model.TokenPurposeMFAChallenge:
+		response.MFARequired = true
+		response.MFAToken, err = issueToken(h.Keys, result.Customer, result.TokenPurpose, mfaTokenTTL)
+	case model.TokenPurposeMFAEnrollment:
+		response.MFAEnrollmentRequired = true
+		response.MFAToken, err = issueToken(h.Keys, result.Customer, result.TokenPurpose, mfaTokenTTL)
+	default:
+		response.Token, err = issueToken(h.Keys, result.Customer, model.TokenPurposeAccess, accessTokenTTL)
+	}
 	if err != nil {
 		logrus.Error(err)
-		http.Error(w, "Failed to register consumer", http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to log in")
 		return
 	}
-	if cust != nil {
-		err := errors.New("NIK already exist")
-		logrus.Error(err)
-		http.Error(w, err.Error(), http.StatusConflict)
+
+	// Send the token to the client
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// issueToken signs a token for the customer that is valid for ttl and only accepted for purpose
+func issueToken(keys *util.KeySet, customer *model.Customer, purpose string, ttl time.Duration) (string, error) {
+	claims := &model.Claims{
+		CustomerID:   customer.ID,
+		NIK:          customer.NIK,
+		FullName:     customer.FullName,
+		Role:         customer.Role,
+		TokenVersion: customer.TokenVersion,
+		Purpose:      purpose,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    keys.Issuer,
+			Audience:  keys.Audience,
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+		},
+	}
+
+	return keys.Sign(claims)
+}
+
+// UnlockCustomer lets an officer clear the failed login counter of a customer's NIK
+func (h *AuthHandler) UnlockCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidCustomerID, "Invalid customer ID")
 		return
 	}
 
-	err = h.CustomerRepo.RegisterCustomer(&customer)
+	err = h.Auth.UnlockCustomer(r.Context(), id)
 	if err != nil {
-		logrus.Error(err)
-		http.Error(w, "Failed to register consumer", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to unlock customer")
 		return
 	}
-	customer.Password = "" // obfuscate
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(customer)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
-	var credentials model.AuthLogin
-	err := json.NewDecoder(r.Body).Decode(&credentials)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+// ChangePassword handles a password change by a logged in customer who knows the old password
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
 		return
 	}
 
-	// Fetch the customer by username
-	customer, err := h.CustomerRepo.GetCustomerByNIK(credentials.NIK)
+	var request model.ChangePasswordRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	err := h.Auth.ChangePassword(r.Context(), claims.CustomerID, request)
 	if err != nil {
-		logrus.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to change password")
 		return
 	}
-	if customer == nil {
-		fmt.Println("sinikah?")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode("Unauthorized")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword starts the password reset flow by sending a single-use token to the customer.
+// The response is the same whether or not the NIK is registered.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var request model.ForgotPasswordRequest
+	if !decodeJSON(w, r, &request) {
 		return
 	}
 
-	// Compare the provided password with the stored hashed password
-	err = bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(credentials.Password))
+	err := h.Auth.ForgotPassword(r.Context(), request)
 	if err != nil {
-		logrus.Error(err)
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode("Unauthorized")
+		writeServiceError(w, r, err, "Failed to request password reset")
 		return
 	}
 
-	// Create JWT token
-	expirationTime := time.Now().Add(30 * time.Minute)
-	claims := &model.Claims{
-		NIK:      customer.NIK,
-		FullName: customer.FullName,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and revokes all existing sessions
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var request model.ResetPasswordRequest
+	if !decodeJSON(w, r, &request) {
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(h.JWTSecret)
+	err := h.Auth.ResetPassword(r.Context(), request)
 	if err != nil {
-		logrus.Error(err)
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode("Something went wrong")
+		writeServiceError(w, r, err, "Failed to reset password")
 		return
 	}
 
-	// Send the token to the client
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": tokenString})
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *AuthHandler) validateRegisterCustomerInput(customer model.Customer) error {
-	if customer.NIK == "" {
-		return errors.New("NIK is required")
-	}
-	if customer.Password == "" {
-		return errors.New("Password is required")
-	}
-	if customer.FullName == "" {
-		return errors.New("FullName is required")
-	}
-	if customer.LegalName == "" {
-		return errors.New("LegalName is required")
-	}
-	return nil
+// hashToken returns the hex encoded SHA-256 hash under which a secret token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
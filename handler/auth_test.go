@@ -1,17 +1,24 @@
 package handler
 
 import (
+	"alif-sigmatech/apierror"
 	"alif-sigmatech/mocks"
 	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/util"
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type mockCustomerRepo struct {
@@ -29,7 +36,7 @@ func newMockCustomerRepo() *mockCustomerRepo {
 	}
 }
 
-func (m *mockCustomerRepo) GetCustomerByNIK(nik string) (*model.Customer, error) {
+func (m *mockCustomerRepo) GetCustomerByNIK(ctx context.Context, nik string) (*model.Customer, error) {
 	for _, customer := range m.customers {
 		if customer.NIK == nik {
 			return customer, nil
@@ -38,12 +45,12 @@ func (m *mockCustomerRepo) GetCustomerByNIK(nik string) (*model.Customer, error)
 	return nil, nil
 }
 
-func (m *mockCustomerRepo) RegisterCustomer(customer *model.Customer) error {
+func (m *mockCustomerRepo) RegisterCustomer(ctx context.Context, customer *model.Customer) error {
 	m.customers[customer.ID] = customer
 	return nil
 }
 
-func (m *mockCustomerRepo) GetCustomerByID(id int) (*model.Customer, error) {
+func (m *mockCustomerRepo) GetCustomerByID(ctx context.Context, id int) (*model.Customer, error) {
 	customer, exists := m.customers[id]
 	if !exists {
 		return nil, nil
@@ -51,37 +58,61 @@ func (m *mockCustomerRepo) GetCustomerByID(id int) (*model.Customer, error) {
 	return customer, nil
 }
 
-func hashPassword(password string) string {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		panic(err)
-	}
-	return string(hashedPassword)
+func (m *mockCustomerRepo) UpdateCustomerProfile(ctx context.Context, customer *model.Customer) error {
+	m.customers[customer.ID] = customer
+	return nil
+}
+
+func (m *mockCustomerRepo) UpdateCustomerIdentity(ctx context.Context, customer *model.Customer) error {
+	m.customers[customer.ID] = customer
+	return nil
+}
+
+func (m *mockCustomerRepo) UpdatePassword(ctx context.Context, id int, hashedPassword string) error {
+	m.customers[id].Password = hashedPassword
+	return nil
+}
+
+func (m *mockCustomerRepo) RevokeSessions(ctx context.Context, id int) error {
+	m.customers[id].TokenVersion++
+	return nil
+}
+
+func (m *mockCustomerRepo) UpdateMFA(ctx context.Context, id int, encryptedSecret []byte, enabled bool) error {
+	m.customers[id].MFASecret = encryptedSecret
+	m.customers[id].MFAEnabled = enabled
+	return nil
+}
+
+func newTestKeySet(t *testing.T) *util.KeySet {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	keys, err := util.NewKeySet("test-key", privateKey, "alif-sigmatech", "alif-sigmatech-api")
+	assert.NoError(t, err)
+	return keys
 }
 
 func TestRegisterCustomer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
 	handler := &AuthHandler{
-		CustomerRepo:  mockCustomerRepo,
-		EncryptionKey: []byte("test-key"),
-		JWTSecret:     []byte("test-secret"),
+		Customers: mockCustomers,
+		Keys:      newTestKeySet(t),
 	}
 
 	// Create a request body
-	customer := model.Customer{
-		NIK:       "182381283182",
+	input := service.RegisterCustomerInput{
+		NIK:       "3201010101010001",
 		FullName:  "Alif Coba",
 		LegalName: "John Doe",
-		Password:  "password",
-		KTPPhoto:  nil,
+		Password:  "Str0ngPassphrase",
 	}
-	body, _ := json.Marshal(customer)
+	body, _ := json.Marshal(input)
 
-	mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any()).Times(1)
-	mockCustomerRepo.EXPECT().RegisterCustomer(gomock.Any()).Times(1)
+	mockCustomers.EXPECT().Register(gomock.Any(), input).Return(&model.Customer{ID: 1, NIK: "3201010101010001"}, nil)
 
 	// Create a request
 	req, err := http.NewRequest("POST", "/auth/register", bytes.NewReader(body))
@@ -101,11 +132,10 @@ func TestLoginHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	mockAuth := mocks.NewMockAuthService(ctrl)
 	handler := &AuthHandler{
-		CustomerRepo:  mockCustomerRepo,
-		EncryptionKey: []byte("test-key"),
-		JWTSecret:     []byte("test-secret"),
+		Auth: mockAuth,
+		Keys: newTestKeySet(t),
 	}
 
 	// Create a request body
@@ -115,14 +145,15 @@ func TestLoginHandler(t *testing.T) {
 	}
 	body, _ := json.Marshal(credentials)
 
-	mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any()).Return(&model.Customer{
-		ID:       1,
-		Password: hashPassword("password"),
-	}, nil).Times(1)
+	mockAuth.EXPECT().Login(gomock.Any(), credentials, "10.0.0.1").Return(&service.LoginResult{
+		Customer:     &model.Customer{ID: 1},
+		TokenPurpose: model.TokenPurposeAccess,
+	}, nil)
 
 	// Create a request
 	req, err := http.NewRequest("POST", "/auth/login", bytes.NewReader(body))
 	assert.NoError(t, err)
+	req.RemoteAddr = "10.0.0.1:51234"
 
 	// Create a ResponseRecorder to record the response
 	rr := httptest.NewRecorder()
@@ -139,3 +170,176 @@ func TestLoginHandler(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Contains(t, response, "token")
 }
+
+func TestLoginHandlerErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuthService(ctrl)
+	handler := &AuthHandler{
+		Auth: mockAuth,
+		Keys: newTestKeySet(t),
+	}
+
+	login := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(model.AuthLogin{NIK: "222", Password: "WrongPassw0rd"})
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.LoginHandler(rr, req)
+		return rr
+	}
+
+	t.Run("Invalid credentials", func(t *testing.T) {
+		mockAuth.EXPECT().Login(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, &service.Error{
+			Kind: service.KindUnauthenticated, Code: apierror.CodeInvalidCredentials, Message: "Invalid NIK or password",
+		})
+
+		rr := login()
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"code":"invalid_credentials"`)
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		mockAuth.EXPECT().Login(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, &service.Error{
+			Kind: service.KindTooManyAttempts, Code: apierror.CodeTooManyLoginAttempts, Message: "Too many failed login attempts",
+			RetryAfter: 3500 * time.Millisecond,
+		})
+
+		rr := login()
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "4", rr.Header().Get("Retry-After"))
+	})
+}
+
+func TestUnlockCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuthService(ctrl)
+	handler := &AuthHandler{Auth: mockAuth}
+
+	unlock := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/admin/customers/"+id+"/unlock", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		handler.UnlockCustomer(rr, withClaims(req, 99, model.RoleOfficer))
+		return rr
+	}
+
+	mockAuth.EXPECT().UnlockCustomer(gomock.Any(), 2).Return(nil)
+	assert.Equal(t, http.StatusNoContent, unlock("2").Code)
+
+	mockAuth.EXPECT().UnlockCustomer(gomock.Any(), 3).Return(&service.Error{Kind: service.KindNotFound, Code: apierror.CodeCustomerNotFound})
+	assert.Equal(t, http.StatusNotFound, unlock("3").Code)
+}
+
+func TestRegisterCustomerWeakPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
+	handler := &AuthHandler{
+		Customers: mockCustomers,
+	}
+
+	mockCustomers.EXPECT().Register(gomock.Any(), gomock.Any()).Return(nil, &service.Error{
+		Kind:    service.KindValidation,
+		Message: "Password must be at least 10 characters",
+	})
+
+	body, _ := json.Marshal(service.RegisterCustomerInput{NIK: "3201010101010001", Password: "short1A"})
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(handler.RegisterCustomer).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Password must be at least 10 characters")
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuthService(ctrl)
+	handler := &AuthHandler{Auth: mockAuth}
+
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "Wrong old password",
+			err:                &service.Error{Kind: service.KindUnauthenticated, Code: apierror.CodeIncorrectPassword},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Weak new password",
+			err:                &service.Error{Kind: service.KindValidation, Code: apierror.CodeValidationFailed},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Success",
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+
+	request := model.ChangePasswordRequest{OldPassword: "OldPassw0rd!", NewPassword: "NewPassw0rd!!"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuth.EXPECT().ChangePassword(gomock.Any(), 1, request).Return(tt.err)
+
+			body, _ := json.Marshal(request)
+			req, _ := http.NewRequest("POST", "/auth/password/change", bytes.NewReader(body))
+			rr := httptest.NewRecorder()
+			handler.ChangePassword(rr, withClaims(req, 1, model.RoleCustomer))
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+		})
+	}
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuthService(ctrl)
+	handler := NewAuthHandler(mockAuth, nil, newTestKeySet(t))
+
+	t.Run("Forgot password is accepted", func(t *testing.T) {
+		mockAuth.EXPECT().ForgotPassword(gomock.Any(), model.ForgotPasswordRequest{NIK: "3201010101010001"}).Return(nil)
+
+		body, _ := json.Marshal(model.ForgotPasswordRequest{NIK: "3201010101010001"})
+		req, _ := http.NewRequest("POST", "/auth/password/forgot", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ForgotPassword(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+	})
+
+	reset := func(err error) *httptest.ResponseRecorder {
+		request := model.ResetPasswordRequest{Token: "token", NewPassword: "BrandNewPassw0rd"}
+		mockAuth.EXPECT().ResetPassword(gomock.Any(), request).Return(err)
+
+		body, _ := json.Marshal(request)
+		req, _ := http.NewRequest("POST", "/auth/password/reset", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ResetPassword(rr, req)
+		return rr
+	}
+
+	t.Run("Invalid token", func(t *testing.T) {
+		rr := reset(&service.Error{Kind: service.KindValidation, Code: apierror.CodeInvalidResetToken, Message: "Invalid or expired reset token"})
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), string(apierror.CodeInvalidResetToken))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		rr := reset(nil)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ContractHandler handles HTTP requests related to contract documents
+type ContractHandler struct {
+	TransactionRepo repository.TransactionRepository
+	ContractRepo    repository.ContractDocumentRepository
+	BlobStore       storage.BlobStore
+	EncryptionKey   []byte
+}
+
+// NewContractHandler creates a new instance of ContractHandler
+func NewContractHandler(transactionRepo repository.TransactionRepository, contractRepo repository.ContractDocumentRepository,
+	blobStore storage.BlobStore, encryptionKey []byte) *ContractHandler {
+	return &ContractHandler{
+		TransactionRepo: transactionRepo,
+		ContractRepo:    contractRepo,
+		BlobStore:       blobStore,
+		EncryptionKey:   encryptionKey,
+	}
+}
+
+// maxUserAgentLength is the length of the user agent column of accepted contract documents
+const maxUserAgentLength = 255
+
+// GetContractDocument returns the contract document of one of the logged in customer's transactions.
+// The X-Content-Hash header carries the hash the customer sends back to accept it.
+func (h *ContractHandler) GetContractDocument(w http.ResponseWriter, r *http.Request) {
+	_, document, ok := h.customerContract(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := service.LoadContractDocument(h.BlobStore, h.EncryptionKey, document)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get contract document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="contract-%d.pdf"`, document.TransactionID))
+	w.Header().Set("X-Content-Hash", document.ContentHash)
+	w.Write(data)
+}
+
+// AcceptContractDocument records that the logged in customer accepted the contract document of a
+// pending transaction, with the time, IP address and user agent of the acceptance
+func (h *ContractHandler) AcceptContractDocument(w http.ResponseWriter, r *http.Request) {
+	transaction, document, ok := h.customerContract(w, r)
+	if !ok {
+		return
+	}
+
+	var request model.AcceptContractRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	if transaction.Status != model.TransactionPending {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeTransactionNotPending, "Transaction is not pending confirmation")
+		return
+	}
+	if document.AcceptedAt != nil {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeContractAlreadyAccepted, "Contract document was already accepted")
+		return
+	}
+	contentHash := strings.ToLower(strings.TrimSpace(request.ContentHash))
+	if subtle.ConstantTimeCompare([]byte(contentHash), []byte(document.ContentHash)) != 1 {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeContentHashMismatch, "ContentHash does not match the contract document")
+		return
+	}
+
+	// The stored document must still be the one that was hashed
+	_, err := service.LoadContractDocument(h.BlobStore, h.EncryptionKey, document)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to accept contract document")
+		return
+	}
+
+	now := time.Now()
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	accepted, err := h.ContractRepo.AcceptContractDocument(r.Context(), document.ID, contentHash, util.ClientIP(r), userAgent, now)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to accept contract document")
+		return
+	}
+	if !accepted {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeContractAlreadyAccepted, "Contract document was already accepted")
+		return
+	}
+
+	document.AcceptedAt = &now
+	document.AcceptedHash = contentHash
+	document.AcceptedIP = util.ClientIP(r)
+	document.AcceptedUserAgent = userAgent
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(document)
+}
+
+// customerContract loads the transaction in the URL and its contract document. Transactions
+// of other customers are reported as missing.
+func (h *ContractHandler) customerContract(w http.ResponseWriter, r *http.Request) (*model.Transaction, *model.ContractDocument, bool) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return nil, nil, false
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidTransactionID, "Invalid transaction ID")
+		return nil, nil, false
+	}
+
+	transaction, err := h.TransactionRepo.GetTransactionByID(r.Context(), id)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get contract document")
+		return nil, nil, false
+	}
+	if transaction == nil || transaction.CustomerID != claims.CustomerID {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeTransactionNotFound, "Transaction not found")
+		return nil, nil, false
+	}
+
+	document, err := h.ContractRepo.GetContractDocumentByTransactionID(r.Context(), transaction.ID)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get contract document")
+		return nil, nil, false
+	}
+	if document == nil {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeContractDocumentNotFound, "Contract document not found")
+		return nil, nil, false
+	}
+
+	return transaction, document, true
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/storage"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEncryptionKey = []byte("0123456789abcdef")
+
+func newTestBlobStore(t *testing.T) storage.BlobStore {
+	blobStore, err := storage.NewFileSystemBlobStore(t.TempDir())
+	assert.NoError(t, err)
+	return blobStore
+}
+
+func TestAcceptContractDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockContractRepo := mocks.NewMockContractDocumentRepository(ctrl)
+	blobStore := newTestBlobStore(t)
+	h := NewContractHandler(mockTransactionRepo, mockContractRepo, blobStore, testEncryptionKey)
+
+	customer := model.Customer{ID: 1, LegalName: "Budi Santoso"}
+	pending := &model.Transaction{ID: 10, CustomerID: 1, ContractNumber: "KTR-001", Tenor: 1, Status: model.TransactionPending}
+	document, err := service.StoreContractDocument(blobStore, testEncryptionKey, customer, model.Limit{Tenor1: 500000}, *pending)
+	assert.NoError(t, err)
+	document.TransactionID = pending.ID
+
+	newRequest := func(method, path, body string, customerID int) *http.Request {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.RemoteAddr = "203.0.113.7:51234"
+		req.Header.Set("User-Agent", "SigmatechApp/2.1 (Android 15)")
+		req = mux.SetURLVars(req, map[string]string{"id": "10"})
+		return withClaims(req, customerID, model.RoleCustomer)
+	}
+
+	var contentHash string
+
+	t.Run("Get document", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending, nil)
+		mockContractRepo.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(document, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetContractDocument(recorder, newRequest("GET", "/fund/transaction/10/contract", "", 1))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(recorder.Body.String(), "%PDF-1.4"))
+		contentHash = recorder.Header().Get("X-Content-Hash")
+		assert.Equal(t, document.ContentHash, contentHash)
+	})
+
+	t.Run("Another customer's document", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetContractDocument(recorder, newRequest("GET", "/fund/transaction/10/contract", "", 2))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Hash of other terms", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending, nil)
+		mockContractRepo.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(document, nil)
+
+		recorder := httptest.NewRecorder()
+		h.AcceptContractDocument(recorder, newRequest("POST", "/fund/transaction/10/contract/accept", `{"content_hash": "`+strings.Repeat("0", 64)+`"}`, 1))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		unaccepted := *document
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending, nil)
+		mockContractRepo.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(&unaccepted, nil)
+		mockContractRepo.EXPECT().AcceptContractDocument(gomock.Any(), document.ID, contentHash, "203.0.113.7", "SigmatechApp/2.1 (Android 15)", gomock.Any()).Return(true, nil)
+
+		recorder := httptest.NewRecorder()
+		h.AcceptContractDocument(recorder, newRequest("POST", "/fund/transaction/10/contract/accept", `{"content_hash": "`+strings.ToUpper(contentHash)+`"}`, 1))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var accepted model.ContractDocument
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &accepted))
+		assert.NotNil(t, accepted.AcceptedAt)
+		assert.Equal(t, contentHash, accepted.AcceptedHash)
+		assert.Equal(t, "203.0.113.7", accepted.AcceptedIP)
+		assert.NotContains(t, recorder.Body.String(), "storage_key")
+	})
+
+	t.Run("Already accepted", func(t *testing.T) {
+		acceptedAt := time.Now()
+		accepted := *document
+		accepted.AcceptedAt = &acceptedAt
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending, nil)
+		mockContractRepo.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(&accepted, nil)
+
+		recorder := httptest.NewRecorder()
+		h.AcceptContractDocument(recorder, newRequest("POST", "/fund/transaction/10/contract/accept", `{"content_hash": "`+contentHash+`"}`, 1))
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("Tampered document", func(t *testing.T) {
+		assert.NoError(t, blobStore.Put(document.StorageKey, []byte("tampered"), "application/pdf"))
+		mockTransactionRepo.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending, nil)
+		mockContractRepo.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(document, nil)
+
+		recorder := httptest.NewRecorder()
+		h.AcceptContractDocument(recorder, newRequest("POST", "/fund/transaction/10/contract/accept", `{"content_hash": "`+contentHash+`"}`, 1))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+}
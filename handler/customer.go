@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/validation"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CustomerHandler handles HTTP requests related to a customer's own profile
+type CustomerHandler struct {
+	Customers service.CustomerService
+}
+
+// NewCustomerHandler creates a new instance of CustomerHandler
+func NewCustomerHandler(customers service.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		Customers: customers,
+	}
+}
+
+// profileFields are the customer fields accepted by UpdateProfile
+var profileFields = map[string]bool{
+	"salary":       true,
+	"address":      true,
+	"phone_number": true,
+	"language":     true,
+}
+
+// GetProfile returns the profile of the logged in customer
+func (h *CustomerHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	profile, err := h.Customers.GetProfile(r.Context(), claims.CustomerID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to get profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// UpdateProfile handles a partial update of the logged in customer's mutable fields
+func (h *CustomerHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if !decodeJSON(w, r, &fields) {
+		return
+	}
+	for field := range fields {
+		if service.IdentityFields[field] {
+			writeFieldError(w, r, apierror.CodeIdentityFieldImmutable,
+				validation.NewFieldError(field, apierror.CodeNotAllowed, "identity_field", nil))
+			return
+		}
+		if !profileFields[field] {
+			writeFieldError(w, r, apierror.CodeFieldNotEditable,
+				validation.NewFieldError(field, apierror.CodeNotAllowed, "not_editable", nil))
+			return
+		}
+	}
+
+	var update model.UpdateProfileRequest
+	payload, _ := json.Marshal(fields)
+	err := json.Unmarshal(payload, &update)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	profile, err := h.Customers.UpdateProfile(r.Context(), claims.CustomerID, update)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to update profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// CreateCorrectionRequest lets the logged in customer ask an officer to correct an identity field
+func (h *CustomerHandler) CreateCorrectionRequest(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var input service.CorrectionInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	request, err := h.Customers.RequestCorrection(r.Context(), claims.CustomerID, input)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to create correction request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+// ListCorrectionRequests returns correction requests for officers, pending ones by default
+func (h *CustomerHandler) ListCorrectionRequests(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.Customers.ListCorrectionRequests(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list correction requests")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// ReviewCorrectionRequest approves or rejects a pending correction request.
+// Approving applies the requested value to the customer's identity.
+func (h *CustomerHandler) ReviewCorrectionRequest(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidCorrectionID, "Invalid correction request ID")
+		return
+	}
+
+	var review model.ReviewCorrectionRequest
+	if !decodeJSON(w, r, &review) {
+		return
+	}
+
+	request, err := h.Customers.ReviewCorrectionRequest(r.Context(), claims.CustomerID, id, review)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to review correction request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func withClaims(req *http.Request, customerID int, role string) *http.Request {
+	return req.WithContext(middleware.WithClaims(req.Context(), &model.Claims{CustomerID: customerID, Role: role}))
+}
+
+func TestGetProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
+	h := NewCustomerHandler(mockCustomers)
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomers.EXPECT().GetProfile(gomock.Any(), 1).Return(&model.CustomerProfile{
+			ID:       1,
+			NIK:      "3201010101010001",
+			FullName: "Alif Coba",
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/customers/me", nil)
+		recorder := httptest.NewRecorder()
+		h.GetProfile(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "password")
+		assert.NotContains(t, recorder.Body.String(), "ktp_photo")
+		assert.NotContains(t, recorder.Body.String(), "selfie_photo")
+
+		var profile model.CustomerProfile
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &profile))
+		assert.Equal(t, "3201010101010001", profile.NIK)
+	})
+
+	t.Run("Customer not found", func(t *testing.T) {
+		mockCustomers.EXPECT().GetProfile(gomock.Any(), 2).Return(nil, &service.Error{Kind: service.KindNotFound, Message: "Customer not found"})
+
+		req, _ := http.NewRequest("GET", "/customers/me", nil)
+		recorder := httptest.NewRecorder()
+		h.GetProfile(recorder, withClaims(req, 2, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestGetProfileUsesRequestContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
+	h := NewCustomerHandler(mockCustomers)
+
+	// A client that went away cancels the query instead of leaving it running
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mockCustomers.EXPECT().GetProfile(gomock.Any(), 1).DoAndReturn(func(ctx context.Context, id int) (*model.CustomerProfile, error) {
+		return nil, ctx.Err()
+	})
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/customers/me", nil)
+	recorder := httptest.NewRecorder()
+	h.GetProfile(recorder, withClaims(req, 1, model.RoleCustomer))
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
+	h := NewCustomerHandler(mockCustomers)
+
+	tests := []struct {
+		name               string
+		body               string
+		setup              func()
+		expectedStatusCode int
+	}{
+		{
+			name: "Successful update",
+			body: `{"salary": 12000000, "address": "Jl. Sudirman 1", "phone_number": "+6281234567890"}`,
+			setup: func() {
+				mockCustomers.EXPECT().UpdateProfile(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, update model.UpdateProfileRequest) (*model.CustomerProfile, error) {
+					assert.Equal(t, 12000000.0, *update.Salary)
+					assert.Equal(t, "Jl. Sudirman 1", *update.Address)
+					assert.Equal(t, "+6281234567890", *update.PhoneNumber)
+					return &model.CustomerProfile{ID: 1, Salary: *update.Salary}, nil
+				})
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Identity field is immutable",
+			body:               `{"nik": "3201010101010002"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Unknown field",
+			body:               `{"role": "admin"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Rejected by the service",
+			body: `{"salary": -1}`,
+			setup: func() {
+				mockCustomers.EXPECT().UpdateProfile(gomock.Any(), 1, gomock.Any()).Return(nil, &service.Error{Kind: service.KindValidation, Message: "Salary cannot be negative"})
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			req, _ := http.NewRequest("PATCH", "/customers/me", bytes.NewBufferString(tt.body))
+			recorder := httptest.NewRecorder()
+			h.UpdateProfile(recorder, withClaims(req, 1, model.RoleCustomer))
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestCreateCorrectionRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
+	h := NewCustomerHandler(mockCustomers)
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomers.EXPECT().RequestCorrection(gomock.Any(), 1, service.CorrectionInput{
+			FieldName:      "legal_name",
+			RequestedValue: "Alif Ramdein",
+			Reason:         "Typo at registration",
+		}).Return(&model.CorrectionRequest{ID: 5, CustomerID: 1, Status: model.CorrectionPending}, nil)
+
+		body := `{"field_name": "legal_name", "requested_value": "Alif Ramdein", "reason": "Typo at registration"}`
+		req, _ := http.NewRequest("POST", "/customers/me/corrections", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.CreateCorrectionRequest(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), model.CorrectionPending)
+	})
+
+	t.Run("Field cannot be corrected", func(t *testing.T) {
+		mockCustomers.EXPECT().RequestCorrection(gomock.Any(), 1, gomock.Any()).Return(nil, &service.Error{Kind: service.KindValidation, Message: "salary cannot be corrected"})
+
+		body := `{"field_name": "salary", "requested_value": "1", "reason": "raise"}`
+		req, _ := http.NewRequest("POST", "/customers/me/corrections", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.CreateCorrectionRequest(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestReviewCorrectionRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomers := mocks.NewMockCustomerService(ctrl)
+	h := NewCustomerHandler(mockCustomers)
+
+	newRequest := func(id, body string) *http.Request {
+		req, _ := http.NewRequest("POST", "/admin/corrections/"+id+"/review", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		return withClaims(req, 99, model.RoleOfficer)
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		mockCustomers.EXPECT().ReviewCorrectionRequest(gomock.Any(), 99, 5, model.ReviewCorrectionRequest{Status: model.CorrectionApproved}).
+			Return(&model.CorrectionRequest{ID: 5, Status: model.CorrectionApproved}, nil)
+
+		recorder := httptest.NewRecorder()
+		h.ReviewCorrectionRequest(recorder, newRequest("5", `{"status": "approved"}`))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("Already reviewed", func(t *testing.T) {
+		mockCustomers.EXPECT().ReviewCorrectionRequest(gomock.Any(), 99, 8, gomock.Any()).
+			Return(nil, &service.Error{Kind: service.KindConflict, Message: "Correction request has already been reviewed"})
+
+		recorder := httptest.NewRecorder()
+		h.ReviewCorrectionRequest(recorder, newRequest("8", `{"status": "rejected"}`))
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.ReviewCorrectionRequest(recorder, newRequest("abc", `{"status": "approved"}`))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
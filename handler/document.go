@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// DocumentHandler handles HTTP requests related to customer identity documents
+type DocumentHandler struct {
+	CustomerRepo  repository.CustomerRepository
+	DocumentRepo  repository.DocumentRepository
+	BlobStore     storage.BlobStore
+	EncryptionKey []byte
+	ImageLimits   util.ImageLimits
+}
+
+// multipartOverhead is the room left for multipart boundaries and form fields on top of the image itself
+const multipartOverhead = 1 << 20
+
+// NewDocumentHandler creates a new instance of DocumentHandler
+func NewDocumentHandler(customerRepo repository.CustomerRepository,
+	documentRepo repository.DocumentRepository, blobStore storage.BlobStore,
+	encryptionKey []byte, imageLimits util.ImageLimits) *DocumentHandler {
+	return &DocumentHandler{
+		CustomerRepo:  customerRepo,
+		DocumentRepo:  documentRepo,
+		BlobStore:     blobStore,
+		EncryptionKey: encryptionKey,
+		ImageLimits:   imageLimits,
+	}
+}
+
+// UploadDocument handles a multipart upload of the logged in customer's KTP or selfie photo.
+// The image is validated, re-encoded to JPEG without metadata, and stored encrypted
+// in the blob store together with a thumbnail.
+func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.ImageLimits.MaxBytes+multipartOverhead)
+	err := r.ParseMultipartForm(h.ImageLimits.MaxBytes)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			apierror.Write(w, r, http.StatusRequestEntityTooLarge, apierror.CodeDocumentTooLarge, "Document is too large")
+			return
+		}
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidPayload, "Invalid multipart payload")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	documentType := r.FormValue("type")
+	if documentType != model.DocumentKTP && documentType != model.DocumentSelfie {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidDocumentType, "Document type must be ktp or selfie")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeDocumentRequired, "File is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.ImageLimits.MaxBytes+1))
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidPayload, "Failed to read document")
+		return
+	}
+	if int64(len(data)) > h.ImageLimits.MaxBytes {
+		apierror.Write(w, r, http.StatusRequestEntityTooLarge, apierror.CodeDocumentTooLarge, "Document is too large")
+		return
+	}
+
+	img, err := util.NormalizeImage(data, h.ImageLimits)
+	if err != nil {
+		if errors.Is(err, util.ErrUnsupportedImage) {
+			apierror.Write(w, r, http.StatusUnsupportedMediaType, apierror.CodeUnsupportedMediaType, err.Error())
+			return
+		}
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidDocument, err.Error())
+		return
+	}
+
+	encryptedImage, err := util.EncryptData(img.Data, h.EncryptionKey)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encrypt document")
+		return
+	}
+	encryptedThumbnail, err := util.EncryptData(img.Thumbnail, h.EncryptionKey)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to encrypt document")
+		return
+	}
+
+	now := time.Now()
+	document := &model.CustomerDocument{
+		CustomerID:   claims.CustomerID,
+		DocumentType: documentType,
+		StorageKey:   fmt.Sprintf("customers/%d/%s/%d.jpg", claims.CustomerID, documentType, now.UnixNano()),
+		ThumbnailKey: fmt.Sprintf("customers/%d/%s/%d_thumb.jpg", claims.CustomerID, documentType, now.UnixNano()),
+		ContentType:  util.ContentTypeJPEG,
+		Size:         len(img.Data),
+		Width:        img.Width,
+		Height:       img.Height,
+		CreatedAt:    now,
+	}
+
+	err = h.BlobStore.Put(document.StorageKey, encryptedImage, document.ContentType)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to store document")
+		return
+	}
+	err = h.BlobStore.Put(document.ThumbnailKey, encryptedThumbnail, document.ContentType)
+	if err != nil {
+		logrus.Error(err)
+		h.deleteBlobs(document.StorageKey)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to store document")
+		return
+	}
+
+	err = h.DocumentRepo.CreateDocument(r.Context(), document)
+	if err != nil {
+		logrus.Error(err)
+		h.deleteBlobs(document.StorageKey, document.ThumbnailKey)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to store document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(document)
+}
+
+// GetCustomerDocument decrypts and streams a customer's KTP or selfie photo to an officer.
+// Every successful access is recorded together with the reason given by the officer.
+func (h *DocumentHandler) GetCustomerDocument(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	customerID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidCustomerID, "Invalid customer ID")
+		return
+	}
+
+	documentType := vars["type"]
+	if documentType != model.DocumentKTP && documentType != model.DocumentSelfie {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidDocumentType, "Document type must be ktp or selfie")
+		return
+	}
+
+	reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+	if reason == "" {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeValidationFailed, "Reason is required")
+		return
+	}
+
+	customer, err := h.CustomerRepo.GetCustomerByID(r.Context(), customerID)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get customer document")
+		return
+	}
+	if customer == nil {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+		return
+	}
+
+	encrypted, err := h.loadEncryptedDocument(r.Context(), customerID, documentType)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get customer document")
+		return
+	}
+	if len(encrypted) == 0 {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeDocumentNotFound, "Document not found")
+		return
+	}
+
+	document, err := util.DecryptData(encrypted, h.EncryptionKey)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to decrypt document")
+		return
+	}
+
+	// Refuse to serve the document if the access cannot be audited
+	err = h.DocumentRepo.CreateAccessLog(r.Context(), &model.DocumentAccessLog{
+		CustomerID:   customerID,
+		DocumentType: documentType,
+		OfficerID:    claims.CustomerID,
+		Reason:       reason,
+		IPAddress:    util.ClientIP(r),
+		AccessedAt:   time.Now(),
+	})
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to get customer document")
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(document))
+	w.Header().Set("Content-Length", strconv.Itoa(len(document)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(document)
+}
+
+// loadEncryptedDocument reads the latest uploaded document from the blob store, or returns nil
+// when the customer has not uploaded one
+func (h *DocumentHandler) loadEncryptedDocument(ctx context.Context, customerID int, documentType string) ([]byte, error) {
+	document, err := h.DocumentRepo.GetLatestDocument(ctx, customerID, documentType)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, nil
+	}
+
+	data, err := h.BlobStore.Get(document.StorageKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// deleteBlobs removes blobs written for an upload that could not be completed
+func (h *DocumentHandler) deleteBlobs(keys ...string) {
+	for _, key := range keys {
+		if err := h.BlobStore.Delete(key); err != nil {
+			logrus.Error(err)
+		}
+	}
+}
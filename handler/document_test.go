@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCustomerDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encryptionKey := []byte("0123456789abcdef")
+	pngHeader := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	encrypted, err := util.EncryptData(pngHeader, encryptionKey)
+	assert.NoError(t, err)
+
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	mockDocumentRepo := mocks.NewMockDocumentRepository(ctrl)
+	blobStore, err := storage.NewFileSystemBlobStore(t.TempDir())
+	assert.NoError(t, err)
+	h := NewDocumentHandler(mockCustomerRepo, mockDocumentRepo, blobStore, encryptionKey, util.DefaultImageLimits())
+
+	officer := &model.Claims{CustomerID: 99, Role: model.RoleOfficer}
+
+	newRequest := func(id, documentType, reason string) *http.Request {
+		req, _ := http.NewRequest("GET", "/admin/customers/"+id+"/documents/"+documentType+"?reason="+reason, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id, "type": documentType})
+		return req.WithContext(middleware.WithClaims(req.Context(), officer))
+	}
+
+	t.Run("Success from blob store", func(t *testing.T) {
+		assert.NoError(t, blobStore.Put("customers/1/ktp/1.jpg", encrypted, util.ContentTypeJPEG))
+
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		mockDocumentRepo.EXPECT().GetLatestDocument(gomock.Any(), 1, model.DocumentKTP).Return(&model.CustomerDocument{
+			CustomerID: 1, DocumentType: model.DocumentKTP, StorageKey: "customers/1/ktp/1.jpg",
+		}, nil)
+		mockDocumentRepo.EXPECT().CreateAccessLog(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, accessLog *model.DocumentAccessLog) error {
+			assert.Equal(t, 1, accessLog.CustomerID)
+			assert.Equal(t, 99, accessLog.OfficerID)
+			assert.Equal(t, model.DocumentKTP, accessLog.DocumentType)
+			assert.Equal(t, "verification", accessLog.Reason)
+			return nil
+		})
+
+		recorder := httptest.NewRecorder()
+		h.GetCustomerDocument(recorder, newRequest("1", model.DocumentKTP, "verification"))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, pngHeader, recorder.Body.Bytes())
+	})
+
+	t.Run("Missing reason", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.GetCustomerDocument(recorder, newRequest("1", model.DocumentKTP, ""))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("Unknown document type", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.GetCustomerDocument(recorder, newRequest("1", "passport", "verification"))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("Customer not found", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 2).Return(nil, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetCustomerDocument(recorder, newRequest("2", model.DocumentSelfie, "verification"))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Document not uploaded", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		mockDocumentRepo.EXPECT().GetLatestDocument(gomock.Any(), 1, model.DocumentSelfie).Return(nil, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetCustomerDocument(recorder, newRequest("1", model.DocumentSelfie, "verification"))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Access log failure", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		mockDocumentRepo.EXPECT().GetLatestDocument(gomock.Any(), 1, model.DocumentKTP).Return(&model.CustomerDocument{
+			CustomerID: 1, DocumentType: model.DocumentKTP, StorageKey: "customers/1/ktp/1.jpg",
+		}, nil)
+		mockDocumentRepo.EXPECT().CreateAccessLog(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+
+		recorder := httptest.NewRecorder()
+		h.GetCustomerDocument(recorder, newRequest("1", model.DocumentKTP, "verification"))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "PNG")
+	})
+}
+
+func newMultipartUpload(t *testing.T, documentType string, file []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	assert.NoError(t, writer.WriteField("type", documentType))
+	part, err := writer.CreateFormFile("file", "photo")
+	assert.NoError(t, err)
+	_, err = part.Write(file)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req, _ := http.NewRequest("POST", "/customers/me/documents", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req.WithContext(middleware.WithClaims(req.Context(), &model.Claims{CustomerID: 1, Role: model.RoleCustomer}))
+}
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestUploadDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encryptionKey := []byte("0123456789abcdef")
+	mockDocumentRepo := mocks.NewMockDocumentRepository(ctrl)
+	blobStore, err := storage.NewFileSystemBlobStore(t.TempDir())
+	assert.NoError(t, err)
+
+	limits := util.DefaultImageLimits()
+	limits.MaxBytes = 1 << 20
+	h := NewDocumentHandler(mocks.NewMockCustomerRepository(ctrl), mockDocumentRepo, blobStore, encryptionKey, limits)
+
+	t.Run("Success", func(t *testing.T) {
+		var stored *model.CustomerDocument
+		mockDocumentRepo.EXPECT().CreateDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, document *model.CustomerDocument) error {
+			stored = document
+			return nil
+		})
+
+		recorder := httptest.NewRecorder()
+		h.UploadDocument(recorder, newMultipartUpload(t, model.DocumentKTP, encodeTestPNG(t, 640, 400)))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+
+		var response model.CustomerDocument
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, util.ContentTypeJPEG, response.ContentType)
+		assert.Equal(t, 640, response.Width)
+		assert.Equal(t, 400, response.Height)
+
+		// Both the image and its thumbnail are stored encrypted as JPEG
+		encrypted, err := blobStore.Get(stored.StorageKey)
+		assert.NoError(t, err)
+		decrypted, err := util.DecryptData(encrypted, encryptionKey)
+		assert.NoError(t, err)
+		contentType, err := util.DetectImageType(decrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, util.ContentTypeJPEG, contentType)
+
+		encrypted, err = blobStore.Get(stored.ThumbnailKey)
+		assert.NoError(t, err)
+		decrypted, err = util.DecryptData(encrypted, encryptionKey)
+		assert.NoError(t, err)
+		thumbnail, _, err := image.DecodeConfig(bytes.NewReader(decrypted))
+		assert.NoError(t, err)
+		assert.Equal(t, limits.ThumbnailSize, thumbnail.Width)
+	})
+
+	t.Run("Not an image", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.UploadDocument(recorder, newMultipartUpload(t, model.DocumentKTP, []byte("%PDF-1.4 not an image")))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+	})
+
+	t.Run("Image too small", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.UploadDocument(recorder, newMultipartUpload(t, model.DocumentSelfie, encodeTestPNG(t, 100, 100)))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("File too large", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.UploadDocument(recorder, newMultipartUpload(t, model.DocumentSelfie, make([]byte, limits.MaxBytes+1)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	})
+
+	t.Run("Unknown document type", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.UploadDocument(recorder, newMultipartUpload(t, "passport", encodeTestPNG(t, 640, 400)))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/service"
+	"alif-sigmatech/validation"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// serviceErrorStatus maps the kinds of service errors to the status codes reporting them
+var serviceErrorStatus = map[service.Kind]int{
+	service.KindValidation:      http.StatusBadRequest,
+	service.KindNotFound:        http.StatusNotFound,
+	service.KindConflict:        http.StatusConflict,
+	service.KindForbidden:       http.StatusForbidden,
+	service.KindLimitExceeded:   http.StatusBadRequest,
+	service.KindExpired:         http.StatusGone,
+	service.KindTooManyAttempts: http.StatusTooManyRequests,
+	service.KindUnauthenticated: http.StatusUnauthorized,
+}
+
+// writeServiceError reports an error returned by a service. Errors of the infrastructure are
+// logged and answered with the fallback message, as their details are not meant for clients.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var serviceErr *service.Error
+	if errors.As(err, &serviceErr) {
+		if status, ok := serviceErrorStatus[serviceErr.Kind]; ok {
+			if serviceErr.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(serviceErr.RetryAfter.Seconds()))))
+			}
+			apierror.Write(w, r, status, serviceErr.Code, serviceErr.Message, serviceErr.Fields...)
+			return
+		}
+	}
+
+	logrus.Error(err)
+	apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, fallback)
+}
+
+// writeFieldError reports a request rejected with code because one of its fields breaks a rule
+func writeFieldError(w http.ResponseWriter, r *http.Request, code apierror.Code, field apierror.FieldError) {
+	apierror.Write(w, r, http.StatusBadRequest, code, field.Message, field)
+}
+
+// writeValidationError reports the invalid fields of a request
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeValidationFailed, validationErr.Error(), validationErr.Fields...)
+		return
+	}
+	apierror.Write(w, r, http.StatusBadRequest, apierror.CodeValidationFailed, err.Error())
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteServiceError(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+		expectedCode       apierror.Code
+		expectedMessage    string
+	}{
+		{
+			name:               "Validation error",
+			err:                &service.Error{Kind: service.KindValidation, Code: apierror.CodeValidationFailed, Message: "Salary must not be negative"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       apierror.CodeValidationFailed,
+			expectedMessage:    "Salary must not be negative",
+		},
+		{
+			name:               "Limit exceeded",
+			err:                &service.Error{Kind: service.KindLimitExceeded, Code: apierror.CodeLimitExceeded, Message: "Transaction exceeds limit"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       apierror.CodeLimitExceeded,
+			expectedMessage:    "Transaction exceeds limit",
+		},
+		{
+			name:               "Code expired",
+			err:                &service.Error{Kind: service.KindExpired, Code: apierror.CodeConfirmationCodeExpired, Message: "Confirmation code has expired"},
+			expectedStatusCode: http.StatusGone,
+			expectedCode:       apierror.CodeConfirmationCodeExpired,
+			expectedMessage:    "Confirmation code has expired",
+		},
+		{
+			name:               "Infrastructure error is not disclosed",
+			err:                errors.New("dial tcp 10.0.0.5:3306: connection refused"),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCode:       apierror.CodeInternal,
+			expectedMessage:    "Failed to create limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/fund/limit", nil)
+			recorder := httptest.NewRecorder()
+			writeServiceError(recorder, req, tt.err, "Failed to create limit")
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+
+			var envelope apierror.Envelope
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.expectedCode, envelope.Error.Code)
+			assert.Equal(t, tt.expectedMessage, envelope.Error.Message)
+		})
+	}
+}
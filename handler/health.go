@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"alif-sigmatech/health"
+	"encoding/json"
+	"net/http"
+)
+
+// HealthHandler answers the probes of the orchestrator and reports the build being run
+type HealthHandler struct {
+	Checker *health.Checker
+	Build   health.BuildInfo
+}
+
+// NewHealthHandler creates a new instance of HealthHandler
+func NewHealthHandler(checker *health.Checker, build health.BuildInfo) *HealthHandler {
+	return &HealthHandler{
+		Checker: checker,
+		Build:   build,
+	}
+}
+
+// Healthz reports that the process is alive, without checking any dependency
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// Readyz reports whether every subsystem can serve requests, with 503 when one cannot or the
+// server is shutting down
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.Checker.Check(r.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	writeProbe(w, status, report)
+}
+
+// Version returns the commit, build time and Go version of the binary
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, h.Build)
+}
+
+func writeProbe(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"alif-sigmatech/health"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	build := health.BuildInfo{Commit: "0123abc", BuildTime: "2026-10-19T08:00:00Z", GoVersion: "go1.20"}
+
+	t.Run("Healthz", func(t *testing.T) {
+		checker := health.NewChecker()
+		checker.Register("database", func(ctx context.Context) error { return errors.New("connection refused") })
+
+		req, _ := http.NewRequest("GET", "/healthz", nil)
+		recorder := httptest.NewRecorder()
+		NewHealthHandler(checker, build).Healthz(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+	})
+
+	t.Run("Readyz", func(t *testing.T) {
+		checker := health.NewChecker()
+		checker.Register("database", func(ctx context.Context) error { return nil })
+		h := NewHealthHandler(checker, build)
+
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		recorder := httptest.NewRecorder()
+		h.Readyz(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"status":"ok","checks":{"database":"ok"}}`, recorder.Body.String())
+
+		checker.Register("schema", func(ctx context.Context) error { return errors.New("database schema is at version 0, expected 1") })
+		recorder = httptest.NewRecorder()
+		h.Readyz(recorder, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.JSONEq(t, `{"status":"failing","checks":{"database":"ok","schema":"failing"}}`, recorder.Body.String())
+		assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/version", nil)
+		recorder := httptest.NewRecorder()
+		NewHealthHandler(health.NewChecker(), build).Version(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var got health.BuildInfo
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+		assert.Equal(t, build, got)
+	})
+}
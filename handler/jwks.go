@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"alif-sigmatech/util"
+	"encoding/json"
+	"net/http"
+)
+
+// JWKSHandler publishes the public keys that verify tokens issued by this service
+type JWKSHandler struct {
+	Keys *util.KeySet
+}
+
+// NewJWKSHandler creates a new instance of JWKSHandler
+func NewJWKSHandler(keys *util.KeySet) *JWKSHandler {
+	return &JWKSHandler{
+		Keys: keys,
+	}
+}
+
+// GetJWKS returns the verification keys as a JSON Web Key Set
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.Keys.JWKS())
+}
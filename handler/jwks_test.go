@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	keys := newTestKeySet(t)
+	assert.NoError(t, keys.AddVerificationKey("retired-key", &rsaKey.PublicKey))
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	recorder := httptest.NewRecorder()
+	NewJWKSHandler(keys).GetJWKS(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var set util.JWKSet
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &set))
+	assert.Len(t, set.Keys, 2)
+	assert.Equal(t, "retired-key", set.Keys[0].KID)
+	assert.Equal(t, "RSA", set.Keys[0].KTY)
+	assert.Equal(t, "RS256", set.Keys[0].Alg)
+	assert.Equal(t, "AQAB", set.Keys[0].E)
+	assert.Equal(t, "test-key", set.Keys[1].KID)
+	assert.Equal(t, "OKP", set.Keys[1].KTY)
+	assert.Equal(t, "Ed25519", set.Keys[1].CRV)
+	assert.NotContains(t, recorder.Body.String(), `"d"`)
+}
+
+func TestKeySetCheck(t *testing.T) {
+	assert.NoError(t, newTestKeySet(t).Check(context.Background()))
+
+	var missing *util.KeySet
+	assert.EqualError(t, missing.Check(context.Background()), "no signing key loaded")
+}
+
+func TestParseTokenKeySelection(t *testing.T) {
+	customer := &model.Customer{ID: 1, NIK: "3201010101010001", Role: model.RoleCustomer}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	oldKeys, err := util.NewKeySet("old-key", rsaKey, "alif-sigmatech", "alif-sigmatech-api")
+	assert.NoError(t, err)
+
+	keys := newTestKeySet(t)
+	assert.NoError(t, keys.AddVerificationKey("old-key", &rsaKey.PublicKey))
+
+	t.Run("Current key", func(t *testing.T) {
+		token, err := issueToken(keys, customer, model.TokenPurposeAccess, accessTokenTTL)
+		assert.NoError(t, err)
+
+		claims, err := middleware.ParseToken(keys, token)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claims.CustomerID)
+	})
+
+	t.Run("Rotated out key is still accepted", func(t *testing.T) {
+		token, err := issueToken(oldKeys, customer, model.TokenPurposeAccess, accessTokenTTL)
+		assert.NoError(t, err)
+
+		_, err = middleware.ParseToken(keys, token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown key ID", func(t *testing.T) {
+		token, err := issueToken(newTestKeySet(t), customer, model.TokenPurposeAccess, accessTokenTTL)
+		assert.NoError(t, err)
+
+		_, err = middleware.ParseToken(oldKeys, token)
+		assert.Error(t, err)
+	})
+
+	t.Run("Algorithm does not match key", func(t *testing.T) {
+		// A token MACed with the RSA public key must not be accepted as RS256
+		claims := &model.Claims{CustomerID: 1, Purpose: model.TokenPurposeAccess, StandardClaims: jwt.StandardClaims{
+			Issuer: "alif-sigmatech", Audience: "alif-sigmatech-api", ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		}}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = "old-key"
+		tokenString, err := token.SignedString([]byte("forged"))
+		assert.NoError(t, err)
+
+		_, err = middleware.ParseToken(keys, tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong audience", func(t *testing.T) {
+		otherKeys := *keys
+		otherKeys.Audience = "another-service"
+		token, err := issueToken(&otherKeys, customer, model.TokenPurposeAccess, accessTokenTTL)
+		assert.NoError(t, err)
+
+		_, err = middleware.ParseToken(keys, token)
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong issuer", func(t *testing.T) {
+		otherKeys := *keys
+		otherKeys.Issuer = "someone-else"
+		token, err := issueToken(&otherKeys, customer, model.TokenPurposeAccess, accessTokenTTL)
+		assert.NoError(t, err)
+
+		_, err = middleware.ParseToken(keys, token)
+		assert.Error(t, err)
+	})
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"alif-sigmatech/mocks"
 	"alif-sigmatech/model"
+	"alif-sigmatech/service"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -9,53 +11,35 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
-// MockLimitRepo is a mock implementation of LimitRepo
-type MockLimitRepo struct {
-	CreateLimitFunc          func(limit *model.Limit) error
-	GetLimitByCustomerIDFunc func(customerID int) (*model.Limit, error)
-}
-
-func (m *MockLimitRepo) CreateLimit(limit *model.Limit) error {
-	if m.CreateLimitFunc != nil {
-		return m.CreateLimitFunc(limit)
-	}
-	return nil
-}
-
-func (m *MockLimitRepo) GetLimitByCustomerID(customerID int) (*model.Limit, error) {
-	if m.GetLimitByCustomerIDFunc != nil {
-		return m.GetLimitByCustomerIDFunc(customerID)
+func TestCreateLimit(t *testing.T) {
+	input := service.CreateLimitInput{
+		CustomerID: 1,
+		Tenor1:     1000,
+		Tenor2:     2000,
+		Tenor3:     3000,
+		Tenor4:     4000,
 	}
-	return nil, nil
-}
 
-func TestCreateLimit(t *testing.T) {
 	tests := []struct {
-		name                string
-		input               model.Limit
-		mockGetCustomerByID func(id int) (*model.Customer, error)
-		mockCreateLimit     func(limit *model.Limit) error
-		expectedStatusCode  int
-		expectedResponse    interface{}
+		name               string
+		limit              *model.Limit
+		err                error
+		expectedStatusCode int
+		expectedResponse   interface{}
 	}{
 		{
 			name: "Successful creation",
-			input: model.Limit{
+			limit: &model.Limit{
 				CustomerID: 1,
 				Tenor1:     1000,
 				Tenor2:     2000,
 				Tenor3:     3000,
 				Tenor4:     4000,
 			},
-			mockGetCustomerByID: func(id int) (*model.Customer, error) {
-				return &model.Customer{ID: 1}, nil
-			},
-			mockCreateLimit: func(limit *model.Limit) error {
-				return nil
-			},
 			expectedStatusCode: http.StatusCreated,
 			expectedResponse: model.Limit{
 				CustomerID: 1,
@@ -65,38 +49,14 @@ func TestCreateLimit(t *testing.T) {
 				Tenor4:     4000},
 		},
 		{
-			name: "Customer not found",
-			input: model.Limit{
-				CustomerID: 2,
-				Tenor1:     1000,
-				Tenor2:     2000,
-				Tenor3:     3000,
-				Tenor4:     4000,
-			},
-			mockGetCustomerByID: func(id int) (*model.Customer, error) {
-				return nil, nil
-			},
-			mockCreateLimit: func(limit *model.Limit) error {
-				return nil
-			},
+			name:               "Customer not found",
+			err:                &service.Error{Kind: service.KindNotFound, Message: "Customer not found"},
 			expectedStatusCode: http.StatusNotFound,
 			expectedResponse:   "Customer not found",
 		},
 		{
-			name: "Failed to create limit",
-			input: model.Limit{
-				CustomerID: 1,
-				Tenor1:     1000,
-				Tenor2:     2000,
-				Tenor3:     3000,
-				Tenor4:     4000,
-			},
-			mockGetCustomerByID: func(id int) (*model.Customer, error) {
-				return &model.Customer{ID: 1}, nil
-			},
-			mockCreateLimit: func(limit *model.Limit) error {
-				return errors.New("some error")
-			},
+			name:               "Failed to create limit",
+			err:                errors.New("some error"),
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedResponse:   "Failed to create limit",
 		},
@@ -104,17 +64,15 @@ func TestCreateLimit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockCustomerRepo := newMockCustomerRepo()
-			mockLimitRepo := &MockLimitRepo{
-				CreateLimitFunc: tt.mockCreateLimit,
-			}
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
 
-			handler := &LimitHandler{
-				CustomerRepo: mockCustomerRepo,
-				LimitRepo:    mockLimitRepo,
-			}
+			mockLimits := mocks.NewMockLimitService(ctrl)
+			mockLimits.EXPECT().CreateLimit(gomock.Any(), input).Return(tt.limit, tt.err)
+
+			handler := NewLimitHandler(mockLimits)
 
-			body, _ := json.Marshal(tt.input)
+			body, _ := json.Marshal(input)
 			req, err := http.NewRequest("POST", "/fund/limit", bytes.NewReader(body))
 			assert.NoError(t, err)
 
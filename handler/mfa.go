@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MFAHandler handles HTTP requests related to TOTP two-factor authentication
+type MFAHandler struct {
+	CustomerRepo     repository.CustomerRepository
+	MFARepo          repository.MFARepository
+	LoginAttemptRepo repository.LoginAttemptRepository
+	Throttle         util.LoginThrottlePolicy
+	Issuer           string
+	Keys             *util.KeySet
+	EncryptionKey    []byte
+}
+
+// recoveryCodeCount is the number of recovery codes handed out when MFA is enabled
+const recoveryCodeCount = 10
+
+// NewMFAHandler creates a new instance of MFAHandler
+func NewMFAHandler(customerRepo repository.CustomerRepository, mfaRepo repository.MFARepository,
+	loginAttemptRepo repository.LoginAttemptRepository, throttle util.LoginThrottlePolicy,
+	issuer string, keys *util.KeySet, encryptionKey []byte) *MFAHandler {
+	return &MFAHandler{
+		CustomerRepo:     customerRepo,
+		MFARepo:          mfaRepo,
+		LoginAttemptRepo: loginAttemptRepo,
+		Throttle:         throttle,
+		Issuer:           issuer,
+		Keys:             keys,
+		EncryptionKey:    encryptionKey,
+	}
+}
+
+// Enroll generates a new TOTP secret for the logged in customer and returns the
+// provisioning URI to be shown as a QR code. MFA is only enforced after Verify.
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	customer, err := h.CustomerRepo.GetCustomerByID(r.Context(), claims.CustomerID)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to enroll MFA")
+		return
+	}
+	if customer == nil {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+		return
+	}
+	if customer.MFAEnabled {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeMFAAlreadyEnabled, "MFA is already enabled")
+		return
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to enroll MFA")
+		return
+	}
+
+	encryptedSecret, err := util.EncryptData([]byte(secret), h.EncryptionKey)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to enroll MFA")
+		return
+	}
+
+	err = h.CustomerRepo.UpdateMFA(r.Context(), customer.ID, encryptedSecret, false)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to enroll MFA")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: util.TOTPProvisioningURI(h.Issuer, customer.NIK, secret),
+	})
+}
+
+// Verify confirms enrolment with a code from the authenticator app, enables MFA
+// and returns freshly generated recovery codes. When the request was made with an
+// enrolment token the login is completed with an access token.
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var request model.MFAVerifyRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	customer, err := h.CustomerRepo.GetCustomerByID(r.Context(), claims.CustomerID)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify MFA")
+		return
+	}
+	if customer == nil {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+		return
+	}
+	if customer.MFAEnabled {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeMFAAlreadyEnabled, "MFA is already enabled")
+		return
+	}
+	if len(customer.MFASecret) == 0 {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeMFAEnrollmentNotStarted, "MFA enrolment has not been started")
+		return
+	}
+
+	if !h.checkCode(w, r, customer, request.Code, "") {
+		return
+	}
+
+	err = h.CustomerRepo.UpdateMFA(r.Context(), customer.ID, customer.MFASecret, true)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify MFA")
+		return
+	}
+
+	recoveryCodes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify MFA")
+		return
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	err = h.MFARepo.ReplaceRecoveryCodes(r.Context(), customer.ID, hashes)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify MFA")
+		return
+	}
+
+	response := model.MFAVerifyResponse{RecoveryCodes: recoveryCodes}
+	if claims.Purpose == model.TokenPurposeMFAEnrollment {
+		response.Token, err = issueToken(h.Keys, customer, model.TokenPurposeAccess, accessTokenTTL)
+		if err != nil {
+			logrus.Error(err)
+			apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to verify MFA")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Login exchanges the MFA challenge token from LoginHandler and a TOTP or recovery code for an access token
+func (h *MFAHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var request model.MFALoginRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	claims, err := middleware.ParseToken(h.Keys, request.MFAToken, model.TokenPurposeMFAChallenge)
+	if err != nil {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidMFAToken, "Invalid MFA token")
+		return
+	}
+
+	customer, err := h.CustomerRepo.GetCustomerByID(r.Context(), claims.CustomerID)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to log in")
+		return
+	}
+	if customer == nil || !customer.MFAEnabled || customer.TokenVersion != claims.TokenVersion {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidMFAToken, "Invalid MFA token")
+		return
+	}
+
+	if !h.checkCode(w, r, customer, request.Code, request.RecoveryCode) {
+		metrics.Logins.WithLabelValues(metrics.LoginMFA, metrics.LoginFailure).Inc()
+		return
+	}
+
+	token, err := issueToken(h.Keys, customer, model.TokenPurposeAccess, accessTokenTTL)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to log in")
+		return
+	}
+	metrics.Logins.WithLabelValues(metrics.LoginMFA, metrics.LoginSuccess).Inc()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.LoginResponse{Token: token})
+}
+
+// checkCode verifies a TOTP code, or a recovery code when one is given, and
+// throttles repeated failures. It writes the error response and returns false
+// when the request must not continue.
+func (h *MFAHandler) checkCode(w http.ResponseWriter, r *http.Request, customer *model.Customer, code, recoveryCode string) bool {
+	key := "mfa:" + strconv.Itoa(customer.ID)
+
+	attempt, err := h.LoginAttemptRepo.GetLoginAttempt(r.Context(), key)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+		return false
+	}
+	if retryAfter := time.Until(h.Throttle.NextAllowedAt(attempt)); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		apierror.Write(w, r, http.StatusTooManyRequests, apierror.CodeTooManyMFAAttempts, "Too many failed MFA attempts")
+		return false
+	}
+
+	var valid bool
+	if recoveryCode != "" {
+		valid, err = h.MFARepo.ConsumeRecoveryCode(r.Context(), customer.ID, hashToken(normalizeRecoveryCode(recoveryCode)), time.Now())
+		if err != nil {
+			logrus.Error(err)
+			apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+			return false
+		}
+	} else {
+		secret, err := util.DecryptData(customer.MFASecret, h.EncryptionKey)
+		if err != nil {
+			logrus.Error(err)
+			apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+			return false
+		}
+		var step int64
+		step, valid = util.ValidateTOTP(string(secret), code, time.Now())
+		if valid {
+			// A code that was already used, e.g. one seen over the customer's shoulder, counts as a wrong code
+			valid, err = h.MFARepo.AcceptTOTPStep(r.Context(), customer.ID, step)
+			if err != nil {
+				logrus.Error(err)
+				apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+				return false
+			}
+		}
+	}
+
+	if !valid {
+		now := time.Now()
+		if err := h.LoginAttemptRepo.RecordLoginFailure(r.Context(), key, now, now.Add(-h.Throttle.ResetAfter)); err != nil {
+			logrus.Error(err)
+		}
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidMFACode, "Invalid MFA code")
+		return false
+	}
+
+	if err := h.LoginAttemptRepo.DeleteLoginAttempt(r.Context(), key); err != nil {
+		logrus.Error(err)
+	}
+	return true
+}
+
+// generateRecoveryCodes returns n random single-use codes formatted as XXXXX-XXXXX
+func generateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return nil, err
+		}
+		code := base32.StdEncoding.EncodeToString(b)[:10]
+		codes[i] = fmt.Sprintf("%s-%s", code[:5], code[5:])
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode makes recovery codes comparable regardless of case and separators
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
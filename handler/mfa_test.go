@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginHandlerMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := newTestKeySet(t)
+	mockAuth := mocks.NewMockAuthService(ctrl)
+	handler := &AuthHandler{
+		Auth: mockAuth,
+		Keys: keys,
+	}
+
+	login := func(purpose string) model.LoginResponse {
+		mockAuth.EXPECT().Login(gomock.Any(), gomock.Any(), gomock.Any()).Return(&service.LoginResult{
+			Customer:     &model.Customer{ID: 1, NIK: "111", Role: model.RoleOfficer},
+			TokenPurpose: purpose,
+		}, nil)
+
+		body, _ := json.Marshal(model.AuthLogin{NIK: "111", Password: "CorrectPassw0rd"})
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.LoginHandler(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response model.LoginResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("Enrolled customer gets a challenge", func(t *testing.T) {
+		response := login(model.TokenPurposeMFAChallenge)
+
+		assert.True(t, response.MFARequired)
+		assert.Empty(t, response.Token)
+
+		// The challenge token cannot be used as an access token
+		_, err := middleware.ParseToken(keys, response.MFAToken)
+		assert.Error(t, err)
+		_, err = middleware.ParseToken(keys, response.MFAToken, model.TokenPurposeMFAChallenge)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Officer without MFA must enrol", func(t *testing.T) {
+		response := login(model.TokenPurposeMFAEnrollment)
+
+		assert.True(t, response.MFAEnrollmentRequired)
+		assert.Empty(t, response.Token)
+		_, err := middleware.ParseToken(keys, response.MFAToken, model.TokenPurposeMFAEnrollment)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Customer without MFA gets an access token", func(t *testing.T) {
+		response := login(model.TokenPurposeAccess)
+
+		assert.NotEmpty(t, response.Token)
+		assert.False(t, response.MFARequired)
+	})
+}
+
+func TestMFAEnrollmentAndLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := newTestKeySet(t)
+	customerRepo := newMockCustomerRepo()
+	customerRepo.customers[1].NIK = "3201010101010001"
+	customerRepo.customers[1].Role = model.RoleOfficer
+	mockMFARepo := mocks.NewMockMFARepository(ctrl)
+	mockLoginAttemptRepo := mocks.NewMockLoginAttemptRepository(ctrl)
+	mockLoginAttemptRepo.EXPECT().GetLoginAttempt(gomock.Any(), "mfa:1").Return(nil, nil).AnyTimes()
+	mockLoginAttemptRepo.EXPECT().DeleteLoginAttempt(gomock.Any(), "mfa:1").Return(nil).AnyTimes()
+
+	h := NewMFAHandler(customerRepo, mockMFARepo, mockLoginAttemptRepo, util.DefaultNIKThrottlePolicy(),
+		"Sigmatech", keys, []byte("0123456789abcdef"))
+
+	enrollmentClaims := &model.Claims{CustomerID: 1, Role: model.RoleOfficer, Purpose: model.TokenPurposeMFAEnrollment}
+	withEnrollment := func(req *http.Request) *http.Request {
+		return req.WithContext(middleware.WithClaims(req.Context(), enrollmentClaims))
+	}
+
+	var enrollment model.MFAEnrollment
+	t.Run("Enroll", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/mfa/enroll", nil)
+		rr := httptest.NewRecorder()
+		h.Enroll(rr, withEnrollment(req))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &enrollment))
+		assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/Sigmatech:3201010101010001?")
+		assert.False(t, customerRepo.customers[1].MFAEnabled)
+		assert.NotContains(t, string(customerRepo.customers[1].MFASecret), enrollment.Secret)
+	})
+
+	verify := func(code string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(model.MFAVerifyRequest{Code: code})
+		req, _ := http.NewRequest("POST", "/auth/mfa/verify", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		h.Verify(rr, withEnrollment(req))
+		return rr
+	}
+
+	t.Run("Verify with wrong code", func(t *testing.T) {
+		mockLoginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), "mfa:1", gomock.Any(), gomock.Any()).Return(nil)
+
+		rr := verify("000000")
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.False(t, customerRepo.customers[1].MFAEnabled)
+	})
+
+	var recoveryHashes []string
+	t.Run("Verify enables MFA and completes the login", func(t *testing.T) {
+		mockMFARepo.EXPECT().ReplaceRecoveryCodes(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, customerID int, hashes []string) error {
+			recoveryHashes = hashes
+			return nil
+		})
+		mockMFARepo.EXPECT().AcceptTOTPStep(gomock.Any(), 1, gomock.Any()).Return(true, nil)
+
+		code, err := util.TOTPCode(enrollment.Secret, time.Now())
+		assert.NoError(t, err)
+		rr := verify(code)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, customerRepo.customers[1].MFAEnabled)
+
+		var response model.MFAVerifyResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Len(t, response.RecoveryCodes, recoveryCodeCount)
+		assert.Equal(t, hashToken(normalizeRecoveryCode(response.RecoveryCodes[0])), recoveryHashes[0])
+		_, err = middleware.ParseToken(keys, response.Token)
+		assert.NoError(t, err)
+	})
+
+	challenge, err := issueToken(keys, customerRepo.customers[1], model.TokenPurposeMFAChallenge, mfaTokenTTL)
+	assert.NoError(t, err)
+
+	mfaLogin := func(request model.MFALoginRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(request)
+		req, _ := http.NewRequest("POST", "/auth/login/mfa", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		h.Login(rr, req)
+		return rr
+	}
+
+	t.Run("Login with TOTP code", func(t *testing.T) {
+		code, err := util.TOTPCode(enrollment.Secret, time.Now())
+		assert.NoError(t, err)
+		mockMFARepo.EXPECT().AcceptTOTPStep(gomock.Any(), 1, gomock.Any()).Return(true, nil)
+
+		rr := mfaLogin(model.MFALoginRequest{MFAToken: challenge, Code: code})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response model.LoginResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		_, err = middleware.ParseToken(keys, response.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Login with a TOTP code already used", func(t *testing.T) {
+		code, err := util.TOTPCode(enrollment.Secret, time.Now())
+		assert.NoError(t, err)
+		mockMFARepo.EXPECT().AcceptTOTPStep(gomock.Any(), 1, gomock.Any()).Return(false, nil)
+		mockLoginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), "mfa:1", gomock.Any(), gomock.Any()).Return(nil)
+
+		rr := mfaLogin(model.MFALoginRequest{MFAToken: challenge, Code: code})
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Login with recovery code", func(t *testing.T) {
+		mockMFARepo.EXPECT().ConsumeRecoveryCode(gomock.Any(), 1, hashToken("ABCDEFGHIJ"), gomock.Any()).Return(true, nil)
+
+		rr := mfaLogin(model.MFALoginRequest{MFAToken: challenge, RecoveryCode: "abcde-fghij"})
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Access token cannot be used as challenge", func(t *testing.T) {
+		access, err := issueToken(keys, customerRepo.customers[1], model.TokenPurposeAccess, accessTokenTTL)
+		assert.NoError(t, err)
+
+		rr := mfaLogin(model.MFALoginRequest{MFAToken: access, Code: "123456"})
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// PartnerHandler handles HTTP requests related to partners and the consents customers give them
+type PartnerHandler struct {
+	PartnerRepo   repository.PartnerRepository
+	EncryptionKey []byte
+}
+
+// apiKeyPrefixLength is the number of leading API key characters kept in clear text to identify it
+const apiKeyPrefixLength = 10
+
+// NewPartnerHandler creates a new instance of PartnerHandler
+func NewPartnerHandler(partnerRepo repository.PartnerRepository, encryptionKey []byte) *PartnerHandler {
+	return &PartnerHandler{
+		PartnerRepo:   partnerRepo,
+		EncryptionKey: encryptionKey,
+	}
+}
+
+// CreatePartner registers a partner and returns its API key and signing secret.
+// Both are only shown in this response.
+func (h *PartnerHandler) CreatePartner(w http.ResponseWriter, r *http.Request) {
+	var request model.CreatePartnerRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	err := validateCreatePartnerInput(request)
+	if err != nil {
+		writeValidationError(w, r, err)
+		return
+	}
+
+	apiKey, err := util.RandomToken(24)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create partner")
+		return
+	}
+	apiKey = "pk_" + apiKey
+
+	signingSecret, err := util.RandomToken(32)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create partner")
+		return
+	}
+
+	encryptedSecret, err := util.EncryptData([]byte(signingSecret), h.EncryptionKey)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create partner")
+		return
+	}
+
+	partner := &model.Partner{
+		Name:          strings.TrimSpace(request.Name),
+		Channel:       request.Channel,
+		APIKeyPrefix:  apiKey[:apiKeyPrefixLength],
+		APIKeyHash:    hashToken(apiKey),
+		SigningSecret: encryptedSecret,
+		Active:        true,
+		CreatedAt:     time.Now(),
+	}
+
+	err = h.PartnerRepo.CreatePartner(r.Context(), partner)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create partner")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(model.PartnerCredentials{
+		Partner:       partner,
+		APIKey:        apiKey,
+		SigningSecret: signingSecret,
+	})
+}
+
+// GrantConsent lets the logged in customer allow a partner to book transactions on their behalf
+func (h *PartnerHandler) GrantConsent(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	var request model.GrantConsentRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	partner, err := h.PartnerRepo.GetPartnerByID(r.Context(), request.PartnerID)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to grant consent")
+		return
+	}
+	if partner == nil || !partner.Active {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodePartnerNotFound, "Partner not found")
+		return
+	}
+
+	consent := &model.PartnerConsent{
+		CustomerID: claims.CustomerID,
+		PartnerID:  partner.ID,
+		GrantedAt:  time.Now(),
+	}
+
+	err = h.PartnerRepo.GrantConsent(r.Context(), consent)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to grant consent")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(consent)
+}
+
+// RevokeConsent withdraws the logged in customer's consent for a partner
+func (h *PartnerHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	partnerID, err := strconv.Atoi(mux.Vars(r)["partner_id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidPartnerID, "Invalid partner ID")
+		return
+	}
+
+	revoked, err := h.PartnerRepo.RevokeConsent(r.Context(), claims.CustomerID, partnerID, time.Now())
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to revoke consent")
+		return
+	}
+	if !revoked {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeConsentNotFound, "Consent not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateCreatePartnerInput(request model.CreatePartnerRequest) error {
+	v := validation.New()
+	name := strings.TrimSpace(request.Name)
+	if v.Required("name", name) {
+		v.MaxLength("name", name, 100)
+	}
+	v.OneOf("channel", request.Channel, model.ChannelDealer, model.ChannelECommerce)
+	return v.Err()
+}
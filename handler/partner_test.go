@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePartner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encryptionKey := []byte("0123456789abcdef")
+	mockPartnerRepo := mocks.NewMockPartnerRepository(ctrl)
+	h := NewPartnerHandler(mockPartnerRepo, encryptionKey)
+
+	t.Run("Success", func(t *testing.T) {
+		var stored *model.Partner
+		mockPartnerRepo.EXPECT().CreatePartner(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, partner *model.Partner) error {
+			stored = partner
+			partner.ID = 3
+			return nil
+		})
+
+		req, _ := http.NewRequest("POST", "/admin/partners", bytes.NewBufferString(`{"name": "Dealer Motor Jaya", "channel": "dealer"}`))
+		recorder := httptest.NewRecorder()
+		h.CreatePartner(recorder, req)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "api_key_hash")
+
+		var credentials model.PartnerCredentials
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &credentials))
+		assert.Equal(t, hashToken(credentials.APIKey), stored.APIKeyHash)
+		assert.Equal(t, credentials.APIKey[:apiKeyPrefixLength], credentials.Partner.APIKeyPrefix)
+
+		// The signing secret is only stored encrypted
+		secret, err := util.DecryptData(stored.SigningSecret, encryptionKey)
+		assert.NoError(t, err)
+		assert.Equal(t, credentials.SigningSecret, string(secret))
+	})
+
+	t.Run("Unknown channel", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/admin/partners", bytes.NewBufferString(`{"name": "Partner", "channel": "app"}`))
+		recorder := httptest.NewRecorder()
+		h.CreatePartner(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestPartnerConsent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPartnerRepo := mocks.NewMockPartnerRepository(ctrl)
+	h := NewPartnerHandler(mockPartnerRepo, []byte("0123456789abcdef"))
+
+	t.Run("Grant", func(t *testing.T) {
+		mockPartnerRepo.EXPECT().GetPartnerByID(gomock.Any(), 3).Return(&model.Partner{ID: 3, Active: true}, nil)
+		mockPartnerRepo.EXPECT().GrantConsent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, consent *model.PartnerConsent) error {
+			assert.Equal(t, 1, consent.CustomerID)
+			assert.Equal(t, 3, consent.PartnerID)
+			return nil
+		})
+
+		req, _ := http.NewRequest("POST", "/customers/me/consents", bytes.NewBufferString(`{"partner_id": 3}`))
+		recorder := httptest.NewRecorder()
+		h.GrantConsent(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+
+	t.Run("Grant to inactive partner", func(t *testing.T) {
+		mockPartnerRepo.EXPECT().GetPartnerByID(gomock.Any(), 4).Return(&model.Partner{ID: 4}, nil)
+
+		req, _ := http.NewRequest("POST", "/customers/me/consents", bytes.NewBufferString(`{"partner_id": 4}`))
+		recorder := httptest.NewRecorder()
+		h.GrantConsent(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		mockPartnerRepo.EXPECT().RevokeConsent(gomock.Any(), 1, 3, gomock.Any()).Return(true, nil)
+
+		req, _ := http.NewRequest("DELETE", "/customers/me/consents/3", nil)
+		req = mux.SetURLVars(req, map[string]string{"partner_id": "3"})
+		recorder := httptest.NewRecorder()
+		h.RevokeConsent(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+}
+
+func TestPartnerMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	encryptionKey := []byte("0123456789abcdef")
+	encryptedSecret, err := util.EncryptData([]byte("signing-secret"), encryptionKey)
+	assert.NoError(t, err)
+
+	mockPartnerRepo := mocks.NewMockPartnerRepository(ctrl)
+	mockPartnerRepo.EXPECT().GetPartnerByAPIKeyHash(gomock.Any(), hashToken("pk_valid")).Return(&model.Partner{
+		ID: 3, Channel: model.ChannelDealer, SigningSecret: encryptedSecret, Active: true,
+	}, nil).AnyTimes()
+	mockPartnerRepo.EXPECT().GetPartnerByAPIKeyHash(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	// Stands in for the partner_nonce table, which refuses a nonce the partner already used
+	nonces := map[string]bool{}
+	mockPartnerRepo.EXPECT().RecordNonce(gomock.Any(), 3, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, partnerID int, nonce string, now, expiresAt time.Time) (bool, error) {
+			assert.Equal(t, 10*time.Minute, expiresAt.Sub(now))
+			if nonces[nonce] {
+				return false, nil
+			}
+			nonces[nonce] = true
+			return true, nil
+		}).AnyTimes()
+
+	var reachedBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partner, ok := middleware.GetPartner(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, 3, partner.ID)
+		body := new(bytes.Buffer)
+		body.ReadFrom(r.Body)
+		reachedBody = body.String()
+	})
+	handler := middleware.PartnerMiddleware(mockPartnerRepo, encryptionKey, 5*time.Minute)(next)
+
+	requests := 0
+	newSignedRequest := func(apiKey string, timestamp time.Time, nonce string, secret, body string) *http.Request {
+		req, _ := http.NewRequest("POST", "/partner/transactions", bytes.NewBufferString(body))
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		req.Header.Set(middleware.HeaderAPIKey, apiKey)
+		req.Header.Set(middleware.HeaderTimestamp, ts)
+		req.Header.Set(middleware.HeaderNonce, nonce)
+		req.Header.Set(middleware.HeaderSignature, util.SignRequest([]byte(secret), "POST", "/partner/transactions", ts, nonce, []byte(body)))
+		return req
+	}
+	newRequest := func(apiKey string, timestamp time.Time, secret, body string) *http.Request {
+		requests++
+		return newSignedRequest(apiKey, timestamp, "nonce-"+strconv.Itoa(requests), secret, body)
+	}
+
+	t.Run("Valid signature", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("pk_valid", time.Now(), "signing-secret", `{"customer_id": 1}`))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `{"customer_id": 1}`, reachedBody)
+	})
+
+	t.Run("Tampered body", func(t *testing.T) {
+		req := newRequest("pk_valid", time.Now(), "signing-secret", `{"customer_id": 1}`)
+		req.Body = http.NoBody
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("pk_valid", time.Now(), "guessed-secret", `{}`))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("Stale timestamp", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("pk_valid", time.Now().Add(-10*time.Minute), "signing-secret", `{}`))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("Replayed nonce", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newSignedRequest("pk_valid", time.Now(), "once", "signing-secret", `{}`))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newSignedRequest("pk_valid", time.Now(), "once", "signing-secret", `{}`))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), string(apierror.CodeReplayedRequest))
+	})
+
+	t.Run("Missing nonce", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newSignedRequest("pk_valid", time.Now(), "", "signing-secret", `{}`))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("Unknown API key", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, newRequest("pk_unknown", time.Now(), "signing-secret", `{}`))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+}
+
+func TestCreatePartnerTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactions := mocks.NewMockTransactionService(ctrl)
+	h := NewTransactionHandler(mockTransactions)
+
+	partner := &model.Partner{ID: 3, Channel: model.ChannelDealer, Active: true}
+	actor := service.Actor{Partner: partner}
+	newRequest := func(body string) *http.Request {
+		req, _ := http.NewRequest("POST", "/partner/transactions", bytes.NewBufferString(body))
+		return req.WithContext(middleware.WithPartner(req.Context(), partner))
+	}
+
+	t.Run("Consented customer", func(t *testing.T) {
+		mockTransactions.EXPECT().BookTransaction(gomock.Any(), actor, gomock.Any()).DoAndReturn(func(_ context.Context, _ service.Actor, input service.BookTransactionInput) (*model.Transaction, error) {
+			// Partners name the customer they book for
+			assert.Equal(t, 1, input.CustomerID)
+			return &model.Transaction{ID: 10, CustomerID: 1, Channel: model.ChannelDealer, PartnerID: &partner.ID, Status: model.TransactionPending}, nil
+		})
+
+		recorder := httptest.NewRecorder()
+		h.CreatePartnerTransaction(recorder, newRequest(`{"customer_id": 1, "asset_id": 1, "otr": 20000000, "down_payment": 4000000, "installment_amount": 300000, "tenor": 1}`))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+
+	t.Run("No consent", func(t *testing.T) {
+		mockTransactions.EXPECT().BookTransaction(gomock.Any(), actor, gomock.Any()).
+			Return(nil, &service.Error{Kind: service.KindForbidden, Message: "Customer has not consented to this partner"})
+
+		recorder := httptest.NewRecorder()
+		h.CreatePartnerTransaction(recorder, newRequest(`{"customer_id": 2, "installment_amount": 300000, "tenor": 1}`))
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("Confirm transaction of another partner", func(t *testing.T) {
+		mockTransactions.EXPECT().ConfirmTransaction(gomock.Any(), actor, 10, "123456").
+			Return(nil, &service.Error{Kind: service.KindNotFound, Message: "Transaction not found"})
+
+		req := newRequest(`{"code": "123456"}`)
+		req = mux.SetURLVars(req, map[string]string{"id": "10"})
+		recorder := httptest.NewRecorder()
+		h.ConfirmPartnerTransaction(recorder, req)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
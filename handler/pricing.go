@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/validation"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PricingHandler handles HTTP requests related to pricing rules
+type PricingHandler struct {
+	PricingRuleRepo repository.PricingRuleRepository
+}
+
+// NewPricingHandler creates a new instance of PricingHandler
+func NewPricingHandler(pricingRuleRepo repository.PricingRuleRepository) *PricingHandler {
+	return &PricingHandler{
+		PricingRuleRepo: pricingRuleRepo,
+	}
+}
+
+var pricingRuleCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{1,50}$`)
+
+// ListPricingRules returns every version of the pricing rules, optionally of a single code
+func (h *PricingHandler) ListPricingRules(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(r.URL.Query().Get("code"))
+
+	rules, err := h.PricingRuleRepo.ListPricingRules(r.Context(), code)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to list pricing rules")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// CreatePricingRule stores a new version of a pricing rule. The previous version
+// of the same code stops applying once the new one takes effect.
+func (h *PricingHandler) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	var rule model.PricingRule
+	if !decodeJSON(w, r, &rule) {
+		return
+	}
+
+	now := time.Now()
+	rule.ID = 0
+	rule.Version = 0
+	rule.Code = strings.ToUpper(strings.TrimSpace(rule.Code))
+	rule.AssetCategory = strings.ToLower(strings.TrimSpace(rule.AssetCategory))
+	rule.PromoCode = strings.ToUpper(strings.TrimSpace(rule.PromoCode))
+	rule.CreatedAt = now
+	if rule.EffectiveFrom.IsZero() {
+		rule.EffectiveFrom = now
+	}
+
+	err := validatePricingRuleInput(rule)
+	if err != nil {
+		writeValidationError(w, r, err)
+		return
+	}
+
+	err = h.PricingRuleRepo.CreatePricingRule(r.Context(), &rule)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create pricing rule")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func validatePricingRuleInput(rule model.PricingRule) error {
+	v := validation.New()
+	v.Check(pricingRuleCodePattern.MatchString(rule.Code), "code", apierror.CodeInvalidFormat, "code",
+		i18n.Params{"min": "1", "max": "50"})
+	v.Check(rule.Tenor >= 0 && rule.Tenor <= 4, "tenor", apierror.CodeOutOfRange, "tenor_filter", nil)
+	v.NotNegative("admin_fee", rule.AdminFee)
+	v.Between("admin_fee_percent", rule.AdminFeePercent, 0, 100)
+	v.Between("monthly_interest_percent", rule.MonthlyInterestPercent, 0, 100)
+	if rule.EffectiveTo != nil {
+		v.Check(rule.EffectiveTo.After(rule.EffectiveFrom), "effective_to", apierror.CodeOutOfRange, "after",
+			i18n.Params{"other": "EffectiveFrom"})
+	}
+	return v.Err()
+}
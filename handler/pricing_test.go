@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePricingRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPricingRuleRepo := mocks.NewMockPricingRuleRepository(ctrl)
+	h := NewPricingHandler(mockPricingRuleRepo)
+
+	t.Run("Success", func(t *testing.T) {
+		mockPricingRuleRepo.EXPECT().CreatePricingRule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rule *model.PricingRule) error {
+			assert.Equal(t, "MOTOR-PROMO", rule.Code)
+			assert.Equal(t, "motorcycle", rule.AssetCategory)
+			assert.False(t, rule.EffectiveFrom.IsZero())
+			rule.Version = 3
+			return nil
+		})
+
+		body := `{"code": "motor-promo", "asset_category": "Motorcycle", "admin_fee": 50000, "monthly_interest_percent": 1.5, "version": 99}`
+		req, _ := http.NewRequest("POST", "/admin/pricing-rules", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.CreatePricingRule(recorder, req)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+
+		var rule model.PricingRule
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rule))
+		assert.Equal(t, 3, rule.Version)
+	})
+
+	t.Run("Effective period ends before it starts", func(t *testing.T) {
+		body := `{"code": "DEFAULT", "effective_from": "2026-02-01T00:00:00Z", "effective_to": "2026-01-01T00:00:00Z"}`
+		req, _ := http.NewRequest("POST", "/admin/pricing-rules", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.CreatePricingRule(recorder, req)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// PromotionHandler handles HTTP requests related to promotion campaigns
+type PromotionHandler struct {
+	PromotionRepo repository.PromotionRepository
+}
+
+// NewPromotionHandler creates a new instance of PromotionHandler
+func NewPromotionHandler(promotionRepo repository.PromotionRepository) *PromotionHandler {
+	return &PromotionHandler{
+		PromotionRepo: promotionRepo,
+	}
+}
+
+var voucherCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,50}$`)
+
+// ListCampaigns returns every campaign with its budget and redemption counters
+func (h *PromotionHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.PromotionRepo.ListCampaigns(r.Context())
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to list campaigns")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaigns)
+}
+
+// CreateCampaign starts a campaign whose voucher code customers can redeem on new transactions
+func (h *PromotionHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	var campaign model.Campaign
+	if !decodeJSON(w, r, &campaign) {
+		return
+	}
+
+	now := time.Now()
+	campaign.ID = 0
+	campaign.Code = strings.ToUpper(strings.TrimSpace(campaign.Code))
+	campaign.Name = strings.TrimSpace(campaign.Name)
+	campaign.AssetCategory = strings.ToLower(strings.TrimSpace(campaign.AssetCategory))
+	campaign.Channel = strings.ToLower(strings.TrimSpace(campaign.Channel))
+	campaign.BudgetUsed = 0
+	campaign.Redemptions = 0
+	campaign.Active = true
+	campaign.CreatedAt = now
+	if campaign.StartsAt.IsZero() {
+		campaign.StartsAt = now
+	}
+
+	err := validateCampaignInput(campaign)
+	if err != nil {
+		writeValidationError(w, r, err)
+		return
+	}
+
+	existing, err := h.PromotionRepo.GetCampaignByCode(r.Context(), campaign.Code)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create campaign")
+		return
+	}
+	if existing != nil {
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeVoucherCodeExists, "Voucher code already exists")
+		return
+	}
+
+	err = h.PromotionRepo.CreateCampaign(r.Context(), &campaign)
+	if errors.Is(err, util.ErrDuplicate) {
+		// Created concurrently since the lookup above
+		apierror.Write(w, r, http.StatusConflict, apierror.CodeVoucherCodeExists, "Voucher code already exists")
+		return
+	}
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to create campaign")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaign)
+}
+
+// DeactivateCampaign stops a campaign from accepting vouchers. Redemptions already made stay valid.
+func (h *PromotionHandler) DeactivateCampaign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidCampaignID, "Invalid campaign ID")
+		return
+	}
+
+	deactivated, err := h.PromotionRepo.DeactivateCampaign(r.Context(), id)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to deactivate campaign")
+		return
+	}
+	if !deactivated {
+		apierror.Write(w, r, http.StatusNotFound, apierror.CodeCampaignNotFound, "Campaign not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateCampaignInput(campaign model.Campaign) error {
+	v := validation.New()
+	v.Check(voucherCodePattern.MatchString(campaign.Code), "code", apierror.CodeInvalidFormat, "code",
+		i18n.Params{"min": "3", "max": "50"})
+	if v.Required("name", campaign.Name) {
+		v.MaxLength("name", campaign.Name, 100)
+	}
+	validAdminFeeDiscount := v.Between("admin_fee_discount_percent", campaign.AdminFeeDiscountPercent, 0, 100)
+	validInterestDiscount := v.Between("interest_discount_percent", campaign.InterestDiscountPercent, 0, 100)
+	if validAdminFeeDiscount && validInterestDiscount {
+		v.Check(campaign.AdminFeeDiscountPercent != 0 || campaign.InterestDiscountPercent != 0, "admin_fee_discount_percent",
+			apierror.CodeRequired, "discount", nil)
+	}
+	v.Check(campaign.Tenor >= 0 && campaign.Tenor <= 4, "tenor", apierror.CodeOutOfRange, "tenor_filter", nil)
+	if campaign.Channel != "" {
+		v.Check(campaign.Channel == model.ChannelApp || campaign.Channel == model.ChannelDealer || campaign.Channel == model.ChannelECommerce,
+			"channel", apierror.CodeNotAllowed, "channel_filter", nil)
+	}
+	v.NotNegative("min_financed_amount", campaign.MinFinancedAmount)
+	v.NotNegative("budget", campaign.Budget)
+	v.NotNegative("max_redemptions", float64(campaign.MaxRedemptions))
+	if campaign.EndsAt != nil {
+		v.Check(campaign.EndsAt.After(campaign.StartsAt), "ends_at", apierror.CodeOutOfRange, "after", i18n.Params{"other": "StartsAt"})
+	}
+	return v.Err()
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCampaign() *model.Campaign {
+	return &model.Campaign{
+		ID:                      7,
+		Code:                    "ZEROFEE",
+		Name:                    "Zero admin fee",
+		AdminFeeDiscountPercent: 100,
+		InterestDiscountPercent: 50,
+		AssetCategory:           "motorcycle",
+		StartsAt:                time.Now().Add(-time.Hour),
+		Active:                  true,
+	}
+}
+
+func TestCreateCampaign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+	h := NewPromotionHandler(mockPromotionRepo)
+
+	t.Run("Success", func(t *testing.T) {
+		mockPromotionRepo.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(nil, nil)
+		mockPromotionRepo.EXPECT().CreateCampaign(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, campaign *model.Campaign) error {
+			assert.True(t, campaign.Active)
+			assert.Zero(t, campaign.Redemptions)
+			assert.False(t, campaign.StartsAt.IsZero())
+			campaign.ID = 7
+			return nil
+		})
+
+		body := `{"code": "zerofee", "name": "Zero admin fee", "admin_fee_discount_percent": 100, "channel": "App", "budget": 5000000, "redemptions": 10}`
+		req, _ := http.NewRequest("POST", "/admin/campaigns", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.CreateCampaign(recorder, req)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+
+		var campaign model.Campaign
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &campaign))
+		assert.Equal(t, 7, campaign.ID)
+		assert.Equal(t, "ZEROFEE", campaign.Code)
+		assert.Equal(t, model.ChannelApp, campaign.Channel)
+	})
+
+	t.Run("Duplicate code", func(t *testing.T) {
+		mockPromotionRepo.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(newTestCampaign(), nil)
+
+		body := `{"code": "ZEROFEE", "name": "Zero admin fee", "admin_fee_discount_percent": 100}`
+		req, _ := http.NewRequest("POST", "/admin/campaigns", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
+		h.CreateCampaign(recorder, req)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "Invalid code", body: `{"code": "a b", "name": "Promo", "admin_fee_discount_percent": 100}`},
+		{name: "Missing name", body: `{"code": "PROMO", "admin_fee_discount_percent": 100}`},
+		{name: "No discount", body: `{"code": "PROMO", "name": "Promo"}`},
+		{name: "Discount above 100 percent", body: `{"code": "PROMO", "name": "Promo", "interest_discount_percent": 120}`},
+		{name: "Unknown channel", body: `{"code": "PROMO", "name": "Promo", "admin_fee_discount_percent": 100, "channel": "fax"}`},
+		{name: "Negative budget", body: `{"code": "PROMO", "name": "Promo", "admin_fee_discount_percent": 100, "budget": -1}`},
+		{name: "Ends before it starts", body: `{"code": "PROMO", "name": "Promo", "admin_fee_discount_percent": 100, "starts_at": "2026-02-01T00:00:00Z", "ends_at": "2026-01-01T00:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/admin/campaigns", bytes.NewBufferString(tt.body))
+			recorder := httptest.NewRecorder()
+			h.CreateCampaign(recorder, req)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
+
+func TestDeactivateCampaign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPromotionRepo := mocks.NewMockPromotionRepository(ctrl)
+	h := NewPromotionHandler(mockPromotionRepo)
+
+	mockPromotionRepo.EXPECT().DeactivateCampaign(gomock.Any(), 7).Return(true, nil)
+	mockPromotionRepo.EXPECT().DeactivateCampaign(gomock.Any(), 8).Return(false, nil)
+
+	for id, expected := range map[string]int{"7": http.StatusNoContent, "8": http.StatusNotFound} {
+		req, _ := http.NewRequest("DELETE", "/admin/campaigns/"+id, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		recorder := httptest.NewRecorder()
+		h.DeactivateCampaign(recorder, req)
+
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
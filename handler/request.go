@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/validation"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// unknownFieldPrefix starts the error the JSON decoder returns for a field the payload does not have
+const unknownFieldPrefix = "json: unknown field "
+
+// decodeJSON decodes the JSON body of the request into v and reports a body that cannot be decoded.
+// Fields v does not have are rejected, so misspelled or read-only fields are not silently ignored.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// writeDecodeError reports a request body that could not be decoded into the payload of the endpoint
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		writeValidationError(w, r, &validation.Error{Fields: []apierror.FieldError{
+			validation.NewFieldError(typeErr.Field, apierror.CodeInvalidFormat, jsonTypeName(typeErr.Type), nil),
+		}})
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		writeValidationError(w, r, &validation.Error{Fields: []apierror.FieldError{
+			apierror.NewFieldError(field, apierror.CodeUnknownField, "", i18n.Params{"field": field}),
+		}})
+	case errors.As(err, &maxBytesErr):
+		apierror.Write(w, r, http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Request payload is too large")
+	default:
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidPayload, "Invalid request payload")
+	}
+}
+
+// jsonTypeName names the JSON value a field of type t is decoded from, as the variant of the message reporting it
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "whole_number"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/statement"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// StatementHandler handles HTTP requests related to payments and statements of account
+type StatementHandler struct {
+	Statements service.StatementService
+}
+
+// NewStatementHandler creates a new instance of StatementHandler
+func NewStatementHandler(statements service.StatementService) *StatementHandler {
+	return &StatementHandler{
+		Statements: statements,
+	}
+}
+
+// Statement formats selected with the format query parameter
+const (
+	statementFormatCSV = "csv"
+	statementFormatPDF = "pdf"
+)
+
+var unsafeFilenameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// GetContractStatement returns the statement of account of one of the logged in customer's contracts
+func (h *StatementHandler) GetContractStatement(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	format, err := statementFormat(r)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeUnsupportedStatementFormat, err.Error())
+		return
+	}
+
+	contract, err := h.Statements.ContractStatement(r.Context(), claims.CustomerID, mux.Vars(r)["contract"])
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to get statement")
+		return
+	}
+
+	filename := "statement-" + unsafeFilenameCharacters.ReplaceAllString(contract.Transaction.ContractNumber, "_")
+	writeStatement(w, r, format, filename, func(buf *bytes.Buffer) error {
+		if format == statementFormatCSV {
+			return statement.WriteContractCSV(buf, *contract)
+		}
+		return statement.WriteContractPDF(buf, *contract)
+	})
+}
+
+// GetMonthlyStatement returns the logged in customer's statement for the month in the URL
+func (h *StatementHandler) GetMonthlyStatement(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.monthlyStatement(w, r, claims.CustomerID)
+}
+
+// AdminGetMonthlyStatement returns the statement of the customer in the URL for auditors
+func (h *StatementHandler) AdminGetMonthlyStatement(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidCustomerID, "Invalid customer ID")
+		return
+	}
+
+	h.monthlyStatement(w, r, customerID)
+}
+
+func (h *StatementHandler) monthlyStatement(w http.ResponseWriter, r *http.Request, customerID int) {
+	format, err := statementFormat(r)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeUnsupportedStatementFormat, err.Error())
+		return
+	}
+
+	period, err := time.Parse("2006-01", mux.Vars(r)["month"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidMonth, "Month must be formatted as YYYY-MM")
+		return
+	}
+
+	monthly, err := h.Statements.MonthlyStatement(r.Context(), customerID, period)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to get statement")
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s", customerID, period.Format("2006-01"))
+	writeStatement(w, r, format, filename, func(buf *bytes.Buffer) error {
+		if format == statementFormatCSV {
+			return statement.WriteMonthlyCSV(buf, *monthly)
+		}
+		return statement.WriteMonthlyPDF(buf, *monthly)
+	})
+}
+
+// RecordPayment records a payment received for a booked transaction. Payments cannot exceed
+// what remains payable on the contract.
+func (h *StatementHandler) RecordPayment(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidTransactionID, "Invalid transaction ID")
+		return
+	}
+
+	var payment model.Payment
+	if !decodeJSON(w, r, &payment) {
+		return
+	}
+
+	recorded, err := h.Statements.RecordPayment(r.Context(), claims.CustomerID, id, payment)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to record payment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(recorded)
+}
+
+func statementFormat(r *http.Request) (string, error) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		return statementFormatPDF, nil
+	case statementFormatCSV, statementFormatPDF:
+		return format, nil
+	default:
+		return "", errors.New("Format must be csv or pdf")
+	}
+}
+
+// writeStatement renders the statement before writing any header, so a rendering failure
+// still results in a proper error response
+func writeStatement(w http.ResponseWriter, r *http.Request, format, filename string, render func(*bytes.Buffer) error) {
+	var buf bytes.Buffer
+	err := render(&buf)
+	if err != nil {
+		logrus.Error(err)
+		apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to render statement")
+		return
+	}
+
+	contentType := "application/pdf"
+	if format == statementFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	w.Write(buf.Bytes())
+}
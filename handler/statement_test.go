@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"alif-sigmatech/statement"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBookedTransaction() *model.Transaction {
+	confirmedAt := time.Date(2026, 7, 15, 10, 0, 0, 0, time.UTC)
+	return &model.Transaction{ID: 10, CustomerID: 1, ContractNumber: "KTR/001", AssetName: "Honda Vario 160",
+		InstallmentAmount: 4500000, Tenor: 4, Status: model.TransactionConfirmed, ConfirmedAt: &confirmedAt, CreatedAt: confirmedAt}
+}
+
+func TestGetContractStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStatements := mocks.NewMockStatementService(ctrl)
+	h := NewStatementHandler(mockStatements)
+
+	newRequest := func(contract, format string) *http.Request {
+		req, _ := http.NewRequest("GET", "/fund/transaction/"+contract+"/statement?format="+format, nil)
+		req = mux.SetURLVars(req, map[string]string{"contract": contract})
+		return withClaims(req, 1, model.RoleCustomer)
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		contract := statement.BuildContract(*newBookedTransaction(), []model.Payment{
+			{TransactionID: 10, Amount: 4500000, Reference: "VA-0001", PaidAt: time.Date(2026, 8, 14, 9, 0, 0, 0, time.UTC)},
+		}, time.Now())
+		mockStatements.EXPECT().ContractStatement(gomock.Any(), 1, "KTR/001").Return(&contract, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetContractStatement(recorder, newRequest("KTR/001", "csv"))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="statement-KTR_001.csv"`, recorder.Header().Get("Content-Disposition"))
+		assert.True(t, strings.HasPrefix(recorder.Body.String(), "contract_number,date,description,amount_due,amount_paid,balance\nKTR/001,2026-08-14,Payment VA-0001,"))
+	})
+
+	t.Run("PDF by default", func(t *testing.T) {
+		contract := statement.BuildContract(*newBookedTransaction(), nil, time.Now())
+		mockStatements.EXPECT().ContractStatement(gomock.Any(), 1, "KTR/001").Return(&contract, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetContractStatement(recorder, newRequest("KTR/001", ""))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(recorder.Body.String(), "%PDF-1.4"))
+	})
+
+	t.Run("Unknown contract", func(t *testing.T) {
+		mockStatements.EXPECT().ContractStatement(gomock.Any(), 1, "KTR-404").Return(nil, &service.Error{
+			Kind: service.KindNotFound, Code: apierror.CodeContractNotFound, Message: "Contract not found",
+		})
+
+		recorder := httptest.NewRecorder()
+		h.GetContractStatement(recorder, newRequest("KTR-404", "csv"))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Pending contract", func(t *testing.T) {
+		mockStatements.EXPECT().ContractStatement(gomock.Any(), 1, "KTR/001").Return(nil, &service.Error{
+			Kind: service.KindConflict, Code: apierror.CodeContractNotBooked, Message: "Contract is not booked",
+		})
+
+		recorder := httptest.NewRecorder()
+		h.GetContractStatement(recorder, newRequest("KTR/001", "csv"))
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.GetContractStatement(recorder, newRequest("KTR/001", "xlsx"))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestGetMonthlyStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStatements := mocks.NewMockStatementService(ctrl)
+	h := NewStatementHandler(mockStatements)
+
+	newRequest := func(month string) *http.Request {
+		req, _ := http.NewRequest("GET", "/fund/statements/"+month+"?format=csv", nil)
+		req = mux.SetURLVars(req, map[string]string{"month": month})
+		return withClaims(req, 1, model.RoleCustomer)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		period := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		monthly := statement.BuildMonthly(model.Customer{ID: 1, FullName: "Budi"}, period, []model.Transaction{*newBookedTransaction()}, nil)
+		mockStatements.EXPECT().MonthlyStatement(gomock.Any(), 1, period).Return(&monthly, nil)
+
+		recorder := httptest.NewRecorder()
+		h.GetMonthlyStatement(recorder, newRequest("2026-10"))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `attachment; filename="statement-1-2026-10.csv"`, recorder.Header().Get("Content-Disposition"))
+		assert.Contains(t, recorder.Body.String(), "KTR/001,2026-10-01,Opening balance,0.00,0.00,9000000.00\n")
+		assert.Contains(t, recorder.Body.String(), "KTR/001,2026-10-31,Closing balance,0.00,0.00,13500000.00\n")
+	})
+
+	t.Run("Invalid month", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.GetMonthlyStatement(recorder, newRequest("October"))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+}
+
+func TestRecordPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStatements := mocks.NewMockStatementService(ctrl)
+	h := NewStatementHandler(mockStatements)
+
+	newRequest := func(body string) *http.Request {
+		req, _ := http.NewRequest("POST", "/admin/transactions/10/payments", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "10"})
+		return withClaims(req, 3, model.RoleOfficer)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockStatements.EXPECT().RecordPayment(gomock.Any(), 3, 10, model.Payment{Amount: 3000000, Reference: "VA-0002"}).
+			Return(&model.Payment{ID: 7, TransactionID: 10, Amount: 3000000, Reference: "VA-0002", RecordedBy: 3}, nil)
+
+		recorder := httptest.NewRecorder()
+		h.RecordPayment(recorder, newRequest(`{"amount": 3000000, "reference": "VA-0002"}`))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"id":7`)
+	})
+
+	t.Run("Exceeds outstanding", func(t *testing.T) {
+		mockStatements.EXPECT().RecordPayment(gomock.Any(), 3, 10, gomock.Any()).Return(nil, &service.Error{
+			Kind: service.KindValidation, Code: apierror.CodeAmountExceedsOutstanding, Message: "Amount must not exceed 3000000.00",
+		})
+
+		recorder := httptest.NewRecorder()
+		h.RecordPayment(recorder, newRequest(`{"amount": 3000000.01}`))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), string(apierror.CodeAmountExceedsOutstanding))
+	})
+
+	t.Run("Transaction not booked", func(t *testing.T) {
+		mockStatements.EXPECT().RecordPayment(gomock.Any(), 3, 10, gomock.Any()).Return(nil, &service.Error{
+			Kind: service.KindConflict, Code: apierror.CodeTransactionNotBooked, Message: "Transaction is not booked",
+		})
+
+		recorder := httptest.NewRecorder()
+		h.RecordPayment(recorder, newRequest(`{"amount": 100}`))
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+}
@@ -1,79 +1,415 @@
 package handler
 
 import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/middleware"
 	"alif-sigmatech/model"
-	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 // TransactionHandler handles HTTP requests related to Transaction
 type TransactionHandler struct {
-	TransactionRepo repository.TransactionRepository
-	LimitRepo       repository.LimitRepository
+	Transactions service.TransactionService
 }
 
 // NewTransactionHandler creates a new instance of TransactionHandler
-func NewTransactionHandler(repo repository.TransactionRepository,
-	limitRepo repository.LimitRepository) *TransactionHandler {
+func NewTransactionHandler(transactions service.TransactionService) *TransactionHandler {
 	return &TransactionHandler{
-		TransactionRepo: repo,
-		LimitRepo:       limitRepo,
+		Transactions: transactions,
 	}
 }
 
-// CreateTransaction handles the creation of a new transaction
+// CreateTransaction creates a pending transaction for the logged in customer and sends
+// the one-time code that books it through ConfirmTransaction
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
-	var transaction model.Transaction
-	err := json.NewDecoder(r.Body).Decode(&transaction)
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.bookTransaction(w, r, service.Actor{CustomerID: claims.CustomerID})
+}
+
+// CreatePartnerTransaction creates a pending transaction on behalf of a customer who consented
+// to the partner. The code confirming it is sent to the customer, who hands it to the partner.
+func (h *TransactionHandler) CreatePartnerTransaction(w http.ResponseWriter, r *http.Request) {
+	partner, ok := middleware.GetPartner(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.bookTransaction(w, r, service.Actor{Partner: partner})
+}
+
+func (h *TransactionHandler) bookTransaction(w http.ResponseWriter, r *http.Request, actor service.Actor) {
+	var input service.BookTransactionInput
+	if !decodeJSON(w, r, &input) {
+		return
+	}
+
+	transaction, err := h.Transactions.BookTransaction(r.Context(), actor, input)
 	if err != nil {
-		logrus.Error(err)
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		writeServiceError(w, r, err, "Failed to create transaction")
 		return
 	}
 
-	// Check customer limit
-	limit, err := h.LimitRepo.GetLimitByCustomerID(transaction.CustomerID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// ConfirmTransaction books a pending transaction of the logged in customer with the one-time code sent by CreateTransaction
+func (h *TransactionHandler) ConfirmTransaction(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.confirmTransaction(w, r, service.Actor{CustomerID: claims.CustomerID})
+}
+
+// ConfirmPartnerTransaction books a pending transaction the partner created with the code the customer received
+func (h *TransactionHandler) ConfirmPartnerTransaction(w http.ResponseWriter, r *http.Request) {
+	partner, ok := middleware.GetPartner(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.confirmTransaction(w, r, service.Actor{Partner: partner})
+}
+
+func (h *TransactionHandler) confirmTransaction(w http.ResponseWriter, r *http.Request, actor service.Actor) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		logrus.Error(err)
-		http.Error(w, "Failed to get customer limit", http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidTransactionID, "Invalid transaction ID")
 		return
 	}
-	if limit == nil {
-		http.Error(w, "Customer limit not found", http.StatusNotFound)
+
+	var request model.ConfirmTransactionRequest
+	if !decodeJSON(w, r, &request) {
 		return
 	}
 
-	if !isWithinLimit(transaction, limit) {
-		http.Error(w, "Transaction exceeds limit", http.StatusBadRequest)
+	transaction, err := h.Transactions.ConfirmTransaction(r.Context(), actor, id, request.Code)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to confirm transaction")
 		return
 	}
 
-	err = h.TransactionRepo.CreateTransaction(&transaction)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// CancelTransaction cancels a pending transaction of the logged in customer and releases its voucher
+func (h *TransactionHandler) CancelTransaction(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	h.cancelTransaction(w, r, service.Actor{CustomerID: claims.CustomerID})
+}
+
+// AdminCancelTransaction cancels a pending or booked transaction of any customer and releases its voucher
+func (h *TransactionHandler) AdminCancelTransaction(w http.ResponseWriter, r *http.Request) {
+	h.cancelTransaction(w, r, service.Actor{Officer: true})
+}
+
+func (h *TransactionHandler) cancelTransaction(w http.ResponseWriter, r *http.Request, actor service.Actor) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		logrus.Error(err)
-		http.Error(w, "Failed to create transaction", http.StatusInternalServerError)
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidTransactionID, "Invalid transaction ID")
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	transaction, err := h.Transactions.CancelTransaction(r.Context(), actor, id)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to cancel transaction")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(transaction)
 }
 
-// isWithinLimit checks if the transaction is within the customer's limit based on the tenor
-func isWithinLimit(transaction model.Transaction, limit *model.Limit) bool {
-	switch transaction.Tenor {
-	case 1:
-		return transaction.InstallmentAmount <= limit.Tenor1
-	case 2:
-		return transaction.InstallmentAmount <= limit.Tenor2
-	case 3:
-		return transaction.InstallmentAmount <= limit.Tenor3
-	case 4:
-		return transaction.InstallmentAmount <= limit.Tenor4
+// ListTransactions returns a page of the logged in customer's transactions
+func (h *TransactionHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+		return
+	}
+
+	filter, fields, err := parseTransactionQuery(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidQuery, err.Error())
+		return
+	}
+	filter.CustomerID = claims.CustomerID
+
+	h.listTransactions(w, r, filter, fields)
+}
+
+// AdminListTransactions returns a page of the transactions of every customer, optionally of one customer_id
+func (h *TransactionHandler) AdminListTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, fields, err := parseTransactionQuery(query)
+	if err != nil {
+		apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidQuery, err.Error())
+		return
+	}
+	if value := query.Get("customer_id"); value != "" {
+		filter.CustomerID, err = strconv.Atoi(value)
+		if err != nil || filter.CustomerID <= 0 {
+			apierror.Write(w, r, http.StatusBadRequest, apierror.CodeInvalidCustomerID, "Invalid customer_id")
+			return
+		}
+	}
+
+	h.listTransactions(w, r, filter, fields)
+}
+
+// Page sizes of transaction listings
+const (
+	defaultTransactionPageSize = 20
+	maxTransactionPageSize     = 100
+)
+
+// listTransactions writes the page of transactions selected by the filter, reduced to the
+// requested fields when there are any
+func (h *TransactionHandler) listTransactions(w http.ResponseWriter, r *http.Request, filter model.TransactionFilter, fields []string) {
+	transactions, more, err := h.Transactions.ListTransactions(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to list transactions")
+		return
+	}
+
+	var page model.TransactionPage
+	if more {
+		page.NextCursor = encodeTransactionCursor(filter, transactions[len(transactions)-1])
+	}
+
+	page.Transactions = transactions
+	if len(fields) > 0 {
+		selected, err := selectTransactionFields(transactions, fields)
+		if err != nil {
+			logrus.Error(err)
+			apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Failed to list transactions")
+			return
+		}
+		page.Transactions = selected
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseTransactionQuery reads the filters, sort, cursor, page size and fields of a transaction listing.
+// Dates are RFC 3339 timestamps or plain dates; a plain "to" date includes the whole day.
+func parseTransactionQuery(query url.Values) (model.TransactionFilter, []string, error) {
+	filter := model.TransactionFilter{
+		Status: query.Get("status"),
+		Sort:   model.TransactionSortCreatedAt,
+		Limit:  defaultTransactionPageSize,
+	}
+
+	switch filter.Status {
+	case "", model.TransactionPending, model.TransactionConfirmed, model.TransactionCancelled:
 	default:
-		return false
+		return filter, nil, errors.New("Invalid status")
+	}
+
+	var err error
+	for name, target := range map[string]*int{"tenor": &filter.Tenor, "asset_id": &filter.AssetID, "partner_id": &filter.PartnerID, "limit": &filter.Limit} {
+		if value := query.Get(name); value != "" {
+			*target, err = strconv.Atoi(value)
+			if err != nil || *target <= 0 {
+				return filter, nil, fmt.Errorf("Invalid %s", name)
+			}
+		}
+	}
+	if filter.Limit > maxTransactionPageSize {
+		return filter, nil, fmt.Errorf("limit must be at most %d", maxTransactionPageSize)
 	}
+
+	for name, target := range map[string]*float64{"min_otr": &filter.MinOTR, "max_otr": &filter.MaxOTR} {
+		if value := query.Get(name); value != "" {
+			*target, err = strconv.ParseFloat(value, 64)
+			if err != nil || *target < 0 {
+				return filter, nil, fmt.Errorf("Invalid %s", name)
+			}
+		}
+	}
+	if filter.MaxOTR != 0 && filter.MinOTR > filter.MaxOTR {
+		return filter, nil, errors.New("min_otr must not exceed max_otr")
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, _, err := parseTransactionDate(value)
+		if err != nil {
+			return filter, nil, errors.New("Invalid from")
+		}
+		filter.CreatedFrom = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, dateOnly, err := parseTransactionDate(value)
+		if err != nil {
+			return filter, nil, errors.New("Invalid to")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.CreatedTo = &to
+	}
+
+	if value := query.Get("sort"); value != "" {
+		filter.Descending = strings.HasPrefix(value, "-")
+		filter.Sort = strings.TrimPrefix(value, "-")
+		if _, ok := transactionSortValue(filter.Sort, model.Transaction{}); !ok {
+			return filter, nil, fmt.Errorf("Cannot sort by %s", filter.Sort)
+		}
+	} else {
+		filter.Descending = true
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		filter.After, err = decodeTransactionCursor(value, filter)
+		if err != nil {
+			return filter, nil, errors.New("Invalid cursor")
+		}
+	}
+
+	var fields []string
+	if value := query.Get("fields"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if !transactionFields[field] {
+				return filter, nil, fmt.Errorf("Unknown field %s", field)
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	return filter, fields, nil
+}
+
+func parseTransactionDate(value string) (time.Time, bool, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err == nil {
+		return date, true, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	return timestamp, false, err
+}
+
+// transactionFields are the JSON fields a listing can be reduced to
+var transactionFields = map[string]bool{
+	"id": true, "customer_id": true, "contract_number": true, "otr": true, "admin_fee": true,
+	"installment_amount": true, "interest_amount": true, "asset_id": true, "asset_name": true,
+	"down_payment": true, "tenor": true, "status": true, "channel": true, "partner_id": true,
+	"promo_code": true, "discount_amount": true, "pricing_rule_id": true, "pricing_rule_version": true,
+	"otp_expires_at": true, "confirmed_at": true, "cancelled_at": true, "created_at": true,
+}
+
+// selectTransactionFields reduces the transactions to the given JSON fields
+func selectTransactionFields(transactions []model.Transaction, fields []string) ([]map[string]interface{}, error) {
+	selected := make([]map[string]interface{}, 0, len(transactions))
+	for _, transaction := range transactions {
+		data, err := json.Marshal(transaction)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]interface{}
+		err = json.Unmarshal(data, &all)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				values[field] = value
+			}
+		}
+		selected = append(selected, values)
+	}
+	return selected, nil
+}
+
+// transactionCursor is the opaque cursor handed to clients. It records the sort it was
+// issued for so it cannot be replayed against a different ordering.
+type transactionCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// transactionSortValue returns the value of the sort column of the transaction, as encoded in cursors
+func transactionSortValue(sort string, transaction model.Transaction) (string, bool) {
+	switch sort {
+	case model.TransactionSortCreatedAt:
+		return transaction.CreatedAt.UTC().Format(time.RFC3339Nano), true
+	case model.TransactionSortOTR:
+		return strconv.FormatFloat(transaction.OTR, 'f', -1, 64), true
+	case model.TransactionSortInstallmentAmount:
+		return strconv.FormatFloat(transaction.InstallmentAmount, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+func transactionSortKey(filter model.TransactionFilter) string {
+	if filter.Descending {
+		return "-" + filter.Sort
+	}
+	return filter.Sort
+}
+
+func encodeTransactionCursor(filter model.TransactionFilter, last model.Transaction) string {
+	value, _ := transactionSortValue(filter.Sort, last)
+	data, _ := json.Marshal(transactionCursor{Sort: transactionSortKey(filter), Value: value, ID: last.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTransactionCursor(encoded string, filter model.TransactionFilter) (*model.TransactionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor transactionCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Sort != transactionSortKey(filter) || cursor.ID <= 0 {
+		return nil, errors.New("cursor was issued for another sort")
+	}
+
+	var value interface{}
+	if filter.Sort == model.TransactionSortCreatedAt {
+		value, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	} else {
+		value, err = strconv.ParseFloat(cursor.Value, 64)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransactionCursor{Value: value, ID: cursor.ID}, nil
 }
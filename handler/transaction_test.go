@@ -1,151 +1,344 @@
 package handler
 
 import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/mocks"
 	"alif-sigmatech/model"
+	"alif-sigmatech/service"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
-
-	"alif-sigmatech/mocks"
 )
 
 func TestCreateTransaction(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockLimitRepo := mocks.NewMockLimitRepository(ctrl)
-	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockTransactions := mocks.NewMockTransactionService(ctrl)
+	h := NewTransactionHandler(mockTransactions)
 
-	h := NewTransactionHandler(mockTransactionRepo, mockLimitRepo)
+	body := `{"customer_id": 2, "contract_number": "KTR-001", "asset_id": 1, "otr": 20000000, "down_payment": 4000000, "installment_amount": 300000, "tenor": 1}`
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequest("POST", "/fund/transaction", bytes.NewBufferString(body))
+		return withClaims(req, 1, model.RoleCustomer)
+	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockLimit := &model.Limit{
-			CustomerID: 1,
-			Tenor1:     500000,
-			Tenor2:     700000,
-			Tenor3:     900000,
-			Tenor4:     1100000,
-		}
-
-		mockLimitRepo.EXPECT().GetLimitByCustomerID(1).Return(mockLimit, nil)
-		mockTransactionRepo.EXPECT().CreateTransaction(gomock.Any()).Return(nil)
+		// The customer comes from the token, not from the payload
+		mockTransactions.EXPECT().BookTransaction(gomock.Any(), service.Actor{CustomerID: 1}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ service.Actor, input service.BookTransactionInput) (*model.Transaction, error) {
+				assert.Equal(t, "KTR-001", input.ContractNumber)
+				assert.Equal(t, 1, input.AssetID)
+				assert.Equal(t, 20000000.0, input.OTR)
+				assert.Equal(t, 1, input.Tenor)
+				return &model.Transaction{ID: 10, CustomerID: 1, ContractNumber: input.ContractNumber, Status: model.TransactionPending}, nil
+			})
 
-		transaction := &model.Transaction{
-			CustomerID:        1,
-			InstallmentAmount: 300000,
-			Tenor:             1,
-		}
-
-		body, _ := json.Marshal(transaction)
-		req, _ := http.NewRequest("POST", "/transactions", bytes.NewBuffer(body))
 		recorder := httptest.NewRecorder()
-
-		h.CreateTransaction(recorder, req)
+		h.CreateTransaction(recorder, newRequest())
 
 		assert.Equal(t, http.StatusCreated, recorder.Code)
+
+		var response model.Transaction
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, model.TransactionPending, response.Status)
 	})
 
 	t.Run("Invalid payload", func(t *testing.T) {
-		req, _ := http.NewRequest("POST", "/transactions", bytes.NewBuffer([]byte("invalid json")))
+		req, _ := http.NewRequest("POST", "/fund/transaction", bytes.NewBufferString("invalid"))
 		recorder := httptest.NewRecorder()
-
-		h.CreateTransaction(recorder, req)
+		h.CreateTransaction(recorder, withClaims(req, 1, model.RoleCustomer))
 
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 	})
 
-	t.Run("Limit not found", func(t *testing.T) {
-		mockLimitRepo.EXPECT().GetLimitByCustomerID(1).Return(nil, nil)
+	t.Run("Unknown field", func(t *testing.T) {
+		// The status is set by the service, so a payload trying to set it is rejected
+		req, _ := http.NewRequest("POST", "/fund/transaction", bytes.NewBufferString(`{"contract_number": "KTR-001", "status": "confirmed"}`))
+		recorder := httptest.NewRecorder()
+		h.CreateTransaction(recorder, withClaims(req, 1, model.RoleCustomer))
 
-		transaction := &model.Transaction{
-			CustomerID:        1,
-			InstallmentAmount: 300000,
-			Tenor:             1,
-		}
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response apierror.Envelope
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, apierror.CodeValidationFailed, response.Error.Code)
+		assert.Equal(t, []apierror.FieldError{{Field: "status", Code: apierror.CodeUnknownField, Message: "Unknown field status"}}, response.Error.Fields)
+	})
 
-		body, _ := json.Marshal(transaction)
-		req, _ := http.NewRequest("POST", "/transactions", bytes.NewBuffer(body))
+	t.Run("Wrong type", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/fund/transaction", bytes.NewBufferString(`{"otr": "20000000"}`))
 		recorder := httptest.NewRecorder()
+		h.CreateTransaction(recorder, withClaims(req, 1, model.RoleCustomer))
 
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response apierror.Envelope
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, []apierror.FieldError{{Field: "otr", Code: apierror.CodeInvalidFormat, Message: "OTR must be a number"}}, response.Error.Fields)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/fund/transaction", bytes.NewBufferString(body))
+		recorder := httptest.NewRecorder()
 		h.CreateTransaction(recorder, req)
 
-		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 	})
 
-	t.Run("Transaction exceeds limit", func(t *testing.T) {
-		mockLimit := &model.Limit{
-			CustomerID: 1,
-			Tenor1:     100000,
-			Tenor2:     100000,
-			Tenor3:     100000,
-			Tenor4:     100000,
-		}
+	errorTests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+	}{
+		{name: "Limit not found", err: &service.Error{Kind: service.KindNotFound, Message: "Limit not found"}, expectedStatusCode: http.StatusNotFound},
+		{name: "Transaction exceeds limit", err: &service.Error{Kind: service.KindLimitExceeded, Message: "Transaction exceeds limit"}, expectedStatusCode: http.StatusBadRequest},
+		{name: "Voucher already used", err: &service.Error{Kind: service.KindConflict, Message: "Voucher has already been used"}, expectedStatusCode: http.StatusConflict},
+		{name: "Error from the repositories", err: errors.New("some error"), expectedStatusCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactions.EXPECT().BookTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, tt.err)
+
+			recorder := httptest.NewRecorder()
+			h.CreateTransaction(recorder, newRequest())
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedStatusCode == http.StatusInternalServerError {
+				assert.NotContains(t, recorder.Body.String(), "some error")
+			}
+		})
+	}
+}
 
-		mockLimitRepo.EXPECT().GetLimitByCustomerID(1).Return(mockLimit, nil)
+func TestConfirmTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-		transaction := &model.Transaction{
-			CustomerID:        1,
-			InstallmentAmount: 300000,
-			Tenor:             1,
-		}
+	mockTransactions := mocks.NewMockTransactionService(ctrl)
+	h := NewTransactionHandler(mockTransactions)
+
+	newRequest := func(id string) *http.Request {
+		req, _ := http.NewRequest("POST", "/fund/transaction/"+id+"/confirm", bytes.NewBufferString(`{"code": "123456"}`))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		return withClaims(req, 1, model.RoleCustomer)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		confirmedAt := time.Now()
+		mockTransactions.EXPECT().ConfirmTransaction(gomock.Any(), service.Actor{CustomerID: 1}, 10, "123456").
+			Return(&model.Transaction{ID: 10, CustomerID: 1, Status: model.TransactionConfirmed, ConfirmedAt: &confirmedAt}, nil)
 
-		body, _ := json.Marshal(transaction)
-		req, _ := http.NewRequest("POST", "/transactions", bytes.NewBuffer(body))
 		recorder := httptest.NewRecorder()
+		h.ConfirmTransaction(recorder, newRequest("10"))
 
-		h.CreateTransaction(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response model.Transaction
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, model.TransactionConfirmed, response.Status)
+		assert.NotNil(t, response.ConfirmedAt)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		h.ConfirmTransaction(recorder, newRequest("abc"))
 
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
 	})
 
-	t.Run("Error from GetLimitByCustomerID", func(t *testing.T) {
-		mockLimitRepo.EXPECT().GetLimitByCustomerID(1).Return(nil, errors.New("database error"))
+	errorTests := []struct {
+		name               string
+		kind               service.Kind
+		expectedStatusCode int
+	}{
+		{name: "Wrong code", kind: service.KindValidation, expectedStatusCode: http.StatusBadRequest},
+		{name: "Attempts exhausted", kind: service.KindTooManyAttempts, expectedStatusCode: http.StatusTooManyRequests},
+		{name: "Code expired", kind: service.KindExpired, expectedStatusCode: http.StatusGone},
+		{name: "Already confirmed", kind: service.KindConflict, expectedStatusCode: http.StatusConflict},
+		{name: "Transaction of another customer", kind: service.KindNotFound, expectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactions.EXPECT().ConfirmTransaction(gomock.Any(), gomock.Any(), 10, gomock.Any()).
+				Return(nil, &service.Error{Kind: tt.kind, Message: tt.name})
+
+			recorder := httptest.NewRecorder()
+			h.ConfirmTransaction(recorder, newRequest("10"))
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.name)
+		})
+	}
+}
 
-		transaction := &model.Transaction{
-			CustomerID:        1,
-			InstallmentAmount: 300000,
-			Tenor:             1,
-		}
+func TestCancelTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactions := mocks.NewMockTransactionService(ctrl)
+	h := NewTransactionHandler(mockTransactions)
+
+	newRequest := func(customerID int, role string) *http.Request {
+		req, _ := http.NewRequest("POST", "/fund/transaction/10/cancel", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "10"})
+		return withClaims(req, customerID, role)
+	}
+
+	t.Run("Customer cancels their own transaction", func(t *testing.T) {
+		mockTransactions.EXPECT().CancelTransaction(gomock.Any(), service.Actor{CustomerID: 1}, 10).
+			Return(&model.Transaction{ID: 10, Status: model.TransactionCancelled}, nil)
 
-		body, _ := json.Marshal(transaction)
-		req, _ := http.NewRequest("POST", "/transactions", bytes.NewBuffer(body))
 		recorder := httptest.NewRecorder()
+		h.CancelTransaction(recorder, newRequest(1, model.RoleCustomer))
 
-		h.CreateTransaction(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), model.TransactionCancelled)
+	})
 
-		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	t.Run("Officer cancels any transaction", func(t *testing.T) {
+		mockTransactions.EXPECT().CancelTransaction(gomock.Any(), service.Actor{Officer: true}, 10).
+			Return(&model.Transaction{ID: 10, Status: model.TransactionCancelled}, nil)
+
+		recorder := httptest.NewRecorder()
+		h.AdminCancelTransaction(recorder, newRequest(99, model.RoleOfficer))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
 	})
 
-	t.Run("Error from CreateTransaction", func(t *testing.T) {
-		mockLimit := &model.Limit{
-			CustomerID: 1,
-			Tenor1:     500000,
-			Tenor2:     700000,
-			Tenor3:     900000,
-			Tenor4:     1100000,
-		}
+	t.Run("Already cancelled", func(t *testing.T) {
+		mockTransactions.EXPECT().CancelTransaction(gomock.Any(), gomock.Any(), 10).
+			Return(nil, &service.Error{Kind: service.KindConflict, Message: "Transaction cannot be cancelled"})
+
+		recorder := httptest.NewRecorder()
+		h.CancelTransaction(recorder, newRequest(1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+}
+
+func TestListTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-		mockLimitRepo.EXPECT().GetLimitByCustomerID(1).Return(mockLimit, nil)
-		mockTransactionRepo.EXPECT().CreateTransaction(gomock.Any()).Return(errors.New("database error"))
+	mockTransactions := mocks.NewMockTransactionService(ctrl)
+	h := NewTransactionHandler(mockTransactions)
+
+	createdAt := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	transactions := []model.Transaction{
+		{ID: 3, CustomerID: 1, ContractNumber: "KTR-003", OTR: 20000000, Status: model.TransactionConfirmed, CreatedAt: createdAt.Add(2 * time.Hour)},
+		{ID: 2, CustomerID: 1, ContractNumber: "KTR-002", OTR: 19000000, Status: model.TransactionConfirmed, CreatedAt: createdAt.Add(time.Hour)},
+		{ID: 1, CustomerID: 1, ContractNumber: "KTR-001", OTR: 21000000, Status: model.TransactionConfirmed, CreatedAt: createdAt},
+	}
+
+	var nextCursor string
+
+	t.Run("First page of the customer's transactions", func(t *testing.T) {
+		mockTransactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter model.TransactionFilter) ([]model.Transaction, bool, error) {
+			// The customer comes from the token, not from the query
+			assert.Equal(t, 1, filter.CustomerID)
+			assert.Equal(t, model.TransactionConfirmed, filter.Status)
+			assert.Equal(t, 2, filter.Tenor)
+			assert.Equal(t, 19000000.0, filter.MinOTR)
+			assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedFrom)
+			assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), *filter.CreatedTo)
+			assert.Equal(t, model.TransactionSortCreatedAt, filter.Sort)
+			assert.True(t, filter.Descending)
+			assert.Nil(t, filter.After)
+			assert.Equal(t, 2, filter.Limit)
+			return transactions[:2], true, nil
+		})
+
+		req, _ := http.NewRequest("GET", "/fund/transactions?customer_id=2&status=confirmed&tenor=2&min_otr=19000000&from=2026-10-01&to=2026-10-31&limit=2", nil)
+		recorder := httptest.NewRecorder()
+		h.ListTransactions(recorder, withClaims(req, 1, model.RoleCustomer))
 
-		transaction := &model.Transaction{
-			CustomerID:        1,
-			InstallmentAmount: 300000,
-			Tenor:             1,
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var page struct {
+			Transactions []model.Transaction `json:"transactions"`
+			NextCursor   string              `json:"next_cursor"`
 		}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+		assert.Len(t, page.Transactions, 2)
+		assert.Equal(t, 2, page.Transactions[1].ID)
+		assert.NotEmpty(t, page.NextCursor)
+		nextCursor = page.NextCursor
+	})
 
-		body, _ := json.Marshal(transaction)
-		req, _ := http.NewRequest("POST", "/transactions", bytes.NewBuffer(body))
+	t.Run("Next page continues behind the cursor", func(t *testing.T) {
+		mockTransactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter model.TransactionFilter) ([]model.Transaction, bool, error) {
+			assert.Equal(t, 2, filter.After.ID)
+			assert.True(t, createdAt.Add(time.Hour).Equal(filter.After.Value.(time.Time)))
+			return transactions[2:], false, nil
+		})
+
+		req, _ := http.NewRequest("GET", "/fund/transactions?limit=2&cursor="+nextCursor, nil)
 		recorder := httptest.NewRecorder()
+		h.ListTransactions(recorder, withClaims(req, 1, model.RoleCustomer))
 
-		h.CreateTransaction(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "next_cursor")
+	})
+
+	t.Run("Field selection", func(t *testing.T) {
+		mockTransactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).Return(transactions[:1], false, nil)
 
-		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		req, _ := http.NewRequest("GET", "/fund/transactions?fields=id,contract_number", nil)
+		recorder := httptest.NewRecorder()
+		h.ListTransactions(recorder, withClaims(req, 1, model.RoleCustomer))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"transactions": [{"id": 3, "contract_number": "KTR-003"}]}`, recorder.Body.String())
+	})
+
+	t.Run("Officer filters by customer and sorts by OTR", func(t *testing.T) {
+		mockTransactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter model.TransactionFilter) ([]model.Transaction, bool, error) {
+			assert.Equal(t, 2, filter.CustomerID)
+			assert.Equal(t, 5, filter.PartnerID)
+			assert.Equal(t, model.TransactionSortOTR, filter.Sort)
+			assert.False(t, filter.Descending)
+			return []model.Transaction{}, false, nil
+		})
+
+		req, _ := http.NewRequest("GET", "/admin/transactions?customer_id=2&partner_id=5&sort=otr", nil)
+		recorder := httptest.NewRecorder()
+		h.AdminListTransactions(recorder, withClaims(req, 3, model.RoleOfficer))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"transactions": []}`, recorder.Body.String())
 	})
+
+	invalid := []struct {
+		name  string
+		query string
+	}{
+		{name: "Unknown status", query: "status=void"},
+		{name: "Page too large", query: "limit=500"},
+		{name: "Unsortable column", query: "sort=contract_number"},
+		{name: "Unknown field", query: "fields=id,otp_hash"},
+		{name: "Inverted amount range", query: "min_otr=20000000&max_otr=10000000"},
+		{name: "Invalid date", query: "from=yesterday"},
+		{name: "Malformed cursor", query: "cursor=abc"},
+		{name: "Cursor of another sort", query: "sort=otr&cursor=" + nextCursor},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/fund/transactions?"+tt.query, nil)
+			recorder := httptest.NewRecorder()
+			h.ListTransactions(recorder, withClaims(req, 1, model.RoleCustomer))
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
 }
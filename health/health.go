@@ -0,0 +1,137 @@
+// Package health tells an orchestrator whether the process can serve requests. Subsystems
+// register a check each, e.g. the database or the key material, and the readiness report runs
+// them all. Readiness fails as soon as the server starts shutting down, so no new requests are
+// routed to it while in-flight ones drain.
+package health
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Status of a check and of the whole report
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+	// StatusShuttingDown reports a server that is draining before it stops
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckTimeout bounds every check, so a hanging subsystem fails the probe instead of stalling it
+const CheckTimeout = 2 * time.Second
+
+// Check reports an error when its subsystem cannot serve requests
+type Check func(ctx context.Context) error
+
+// Report is the outcome of the readiness checks
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Ready reports whether every check passed
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs the readiness checks registered by subsystems
+type Checker struct {
+	mu       sync.RWMutex
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+// NewChecker creates a Checker without any checks
+func NewChecker() *Checker {
+	return &Checker{
+		checks: make(map[string]Check),
+	}
+}
+
+// Register adds the check of a subsystem, replacing the one registered under the same name
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Drain fails readiness from now on, for the graceful shutdown
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check runs every check concurrently. Errors are logged rather than reported, as the report
+// is served without authentication.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	checks := c.checks
+	c.mu.RUnlock()
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			errs[i] = check(ctx)
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]string)}
+	for i, name := range names {
+		if errs[i] != nil {
+			logrus.Warnf("Readiness check %s failed: %v", name, errs[i])
+			report.Checks[name] = StatusFailing
+			report.Status = StatusFailing
+			continue
+		}
+		report.Checks[name] = StatusOK
+	}
+	if c.draining.Load() {
+		report.Status = StatusShuttingDown
+	}
+	return report
+}
+
+// BuildInfo identifies the build of the running binary
+type BuildInfo struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Build returns the build info of the binary. Commit and build time are injected with -ldflags;
+// without a commit the one recorded by the Go toolchain is used, when there is one.
+func Build(commit string, buildTime string) BuildInfo {
+	info := BuildInfo{Commit: commit, BuildTime: buildTime, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok && info.Commit == "" {
+		for _, setting := range build.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Commit = setting.Value
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
@@ -0,0 +1,75 @@
+package health_test
+
+import (
+	"alif-sigmatech/health"
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+
+	t.Run("Every check passes", func(t *testing.T) {
+		checker := health.NewChecker()
+		checker.Register("database", ok)
+		checker.Register("keys", ok)
+
+		report := checker.Check(context.Background())
+
+		assert.True(t, report.Ready())
+		assert.Equal(t, health.Report{
+			Status: health.StatusOK,
+			Checks: map[string]string{"database": health.StatusOK, "keys": health.StatusOK},
+		}, report)
+	})
+
+	t.Run("A failing check fails readiness without exposing its error", func(t *testing.T) {
+		checker := health.NewChecker()
+		checker.Register("database", func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:3306: connection refused") })
+		checker.Register("keys", ok)
+
+		report := checker.Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, health.StatusFailing, report.Status)
+		assert.Equal(t, map[string]string{"database": health.StatusFailing, "keys": health.StatusOK}, report.Checks)
+	})
+
+	t.Run("A hanging check times out", func(t *testing.T) {
+		checker := health.NewChecker()
+		checker.Register("database", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		report := checker.Check(ctx)
+
+		assert.Equal(t, health.StatusFailing, report.Status)
+	})
+
+	t.Run("Draining fails readiness", func(t *testing.T) {
+		checker := health.NewChecker()
+		checker.Register("database", ok)
+		checker.Drain()
+
+		report := checker.Check(context.Background())
+
+		assert.False(t, report.Ready())
+		assert.Equal(t, health.StatusShuttingDown, report.Status)
+		assert.Equal(t, map[string]string{"database": health.StatusOK}, report.Checks)
+	})
+}
+
+func TestBuild(t *testing.T) {
+	info := health.Build("0123abc", "2026-10-19T08:00:00Z")
+
+	assert.Equal(t, health.BuildInfo{Commit: "0123abc", BuildTime: "2026-10-19T08:00:00Z", GoVersion: runtime.Version()}, info)
+	assert.Equal(t, "unknown", health.Build("", "").BuildTime)
+}
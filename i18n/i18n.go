@@ -0,0 +1,163 @@
+// Package i18n translates the messages reported to clients. Messages are templates in catalogs
+// shipped with the binary, one per language. Templates are keyed by error code, and by code and
+// variant, e.g. "out_of_range.between", for codes whose messages differ with the broken rule.
+// Placeholders such as {field} are filled from Params.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language identifies a catalog by its ISO 639-1 code
+type Language string
+
+// Supported languages
+const (
+	Indonesian Language = "id"
+	English    Language = "en"
+)
+
+// Default is the language of requests that do not ask for a supported one, as most customers are Indonesian
+const Default = Indonesian
+
+// Languages lists the supported languages
+var Languages = []Language{Indonesian, English}
+
+// Params fills the placeholders of a template
+type Params map[string]string
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[Language]map[string]string {
+	catalogs := make(map[Language]map[string]string)
+	for _, lang := range Languages {
+		data, err := locales.ReadFile("locales/" + string(lang) + ".json")
+		if err != nil {
+			panic(err)
+		}
+
+		var catalog map[string]string
+		err = json.Unmarshal(data, &catalog)
+		if err != nil {
+			panic(fmt.Sprintf("i18n: locales/%s.json: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+	}
+	return catalogs
+}
+
+// Key returns the key of the template of a code, or of one of its variants when variant is set
+func Key(code string, variant string) string {
+	if variant == "" {
+		return code
+	}
+	return code + "." + variant
+}
+
+// Translate fills the template of key in the language with params. A template missing from the
+// language falls back to English; ok is false when no catalog has it, in which case the key is returned.
+func Translate(lang Language, key string, params Params) (message string, ok bool) {
+	template, ok := catalogs[lang][key]
+	if !ok {
+		template, ok = catalogs[English][key]
+	}
+	if !ok {
+		return key, false
+	}
+
+	if len(params) == 0 {
+		return template, true
+	}
+	replacements := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(template), true
+}
+
+// Has reports whether the catalog of the language has a template for key
+func Has(lang Language, key string) bool {
+	_, ok := catalogs[lang][key]
+	return ok
+}
+
+// Keys returns the keys of the catalog of the language in order
+func Keys(lang Language) []string {
+	keys := make([]string, 0, len(catalogs[lang]))
+	for key := range catalogs[lang] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Parse returns the supported language of a language tag such as id-ID
+func Parse(tag string) (Language, bool) {
+	primary := strings.ToLower(strings.TrimSpace(strings.SplitN(tag, "-", 2)[0]))
+	if primary == "in" {
+		// Former code of Indonesian, still sent by some Android versions
+		primary = string(Indonesian)
+	}
+
+	for _, lang := range Languages {
+		if string(lang) == primary {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the supported language an Accept-Language header prefers most.
+// Among equally preferred languages the first listed wins.
+func Negotiate(acceptLanguage string) (Language, bool) {
+	var best Language
+	bestQuality := 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, quality := parseWeightedTag(part)
+		lang, ok := Parse(tag)
+		if ok && quality > bestQuality {
+			best = lang
+			bestQuality = quality
+		}
+	}
+	return best, bestQuality > 0
+}
+
+// parseWeightedTag splits an entry of Accept-Language such as "en-US;q=0.8" into its tag and quality
+func parseWeightedTag(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	quality := 1.0
+	for _, param := range params[1:] {
+		name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if found && strings.TrimSpace(name) == "q" {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return "", 0
+			}
+			quality = q
+		}
+	}
+	return strings.TrimSpace(params[0]), quality
+}
+
+type contextKey struct{}
+
+// WithLanguage returns a copy of ctx carrying the language of the request
+func WithLanguage(ctx context.Context, lang Language) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language of the request stored by WithLanguage
+func FromContext(ctx context.Context) (Language, bool) {
+	lang, ok := ctx.Value(contextKey{}).(Language)
+	return lang, ok
+}
@@ -0,0 +1,67 @@
+package i18n_test
+
+import (
+	"alif-sigmatech/i18n"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+func TestCatalogs(t *testing.T) {
+	english := i18n.Keys(i18n.English)
+	assert.NotEmpty(t, english)
+
+	for _, lang := range i18n.Languages {
+		t.Run(string(lang), func(t *testing.T) {
+			// Every language translates the same templates with the same placeholders
+			assert.Equal(t, english, i18n.Keys(lang))
+			for _, key := range english {
+				template, _ := i18n.Translate(lang, key, nil)
+				englishTemplate, _ := i18n.Translate(i18n.English, key, nil)
+				assert.ElementsMatch(t, placeholderPattern.FindAllString(englishTemplate, -1), placeholderPattern.FindAllString(template, -1), key)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	message, ok := i18n.Translate(i18n.Indonesian, i18n.Key("too_long", ""), i18n.Params{"field": "Address", "max": "255"})
+	assert.True(t, ok)
+	assert.Equal(t, "Address paling banyak 255 karakter", message)
+
+	message, ok = i18n.Translate(i18n.English, i18n.Key("out_of_range", "between"), i18n.Params{"field": "Tenor", "min": "1", "max": "4"})
+	assert.True(t, ok)
+	assert.Equal(t, "Tenor must be between 1 and 4", message)
+
+	message, ok = i18n.Translate(i18n.Indonesian, "no_such_code", nil)
+	assert.False(t, ok)
+	assert.Equal(t, "no_such_code", message)
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		lang   i18n.Language
+		ok     bool
+	}{
+		{header: "id-ID,id;q=0.9,en-US;q=0.8", lang: i18n.Indonesian, ok: true},
+		{header: "en-US,en;q=0.9", lang: i18n.English, ok: true},
+		{header: "fr-FR, en;q=0.5, id;q=0.7", lang: i18n.Indonesian, ok: true},
+		{header: "in-ID", lang: i18n.Indonesian, ok: true},
+		{header: "en, id", lang: i18n.English, ok: true},
+		{header: "id;q=0, en;q=0.1", lang: i18n.English, ok: true},
+		{header: "fr-FR, de", ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			lang, ok := i18n.Negotiate(tt.header)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.lang, lang)
+		})
+	}
+}
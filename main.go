@@ -1,80 +1,327 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/config"
 	"alif-sigmatech/handler"
+	"alif-sigmatech/health"
+	"alif-sigmatech/metrics"
 	"alif-sigmatech/middleware"
+	"alif-sigmatech/model"
+	"alif-sigmatech/notifier"
+	"alif-sigmatech/pricing"
 	"alif-sigmatech/repository"
+	"alif-sigmatech/server"
+	"alif-sigmatech/service"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+)
+
+// commit and buildTime identify the build, set with
+// -ldflags "-X main.commit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	commit    string
+	buildTime string
 )
 
 // AppConfig contains the application configurations
 type AppConfig struct {
-	DB            *sql.DB
-	jwtSecret     []byte
+	DB             *sql.DB
+	BlobStore      storage.BlobStore
+	Notifier       notifier.Notifier
+	PasswordPolicy util.PasswordPolicy
+	NIKThrottle    util.LoginThrottlePolicy
+	IPThrottle     util.LoginThrottlePolicy
+	MFAIssuer      string
+	// MFARequiredRoles must log in with a TOTP code
+	MFARequiredRoles []string
+	// OTRTolerancePercent is how far a transaction's OTR may deviate from the asset's reference price range
+	OTRTolerancePercent float64
+	// JWTKeys signs and verifies access tokens
+	JWTKeys *util.KeySet
+	// DBTimeouts bounds how long each repository operation may run
+	DBTimeouts repository.Timeouts
+	// DBMaxRetries and DBRetryDelay apply to transactions aborted by a deadlock
+	DBMaxRetries int
+	DBRetryDelay time.Duration
+	// PartnerSignatureMaxSkew is how old a partner's signed request may be
+	PartnerSignatureMaxSkew time.Duration
+	// Health collects the readiness checks of the subsystems
+	Health        *health.Checker
 	encryptionKey []byte
 }
 
 func main() {
-	err := godotenv.Load()
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	printConfig := flags.Bool("print-config", false, "print the configuration with secrets redacted and exit")
+	cfg, err := config.Load(flags, os.Args[1:], os.LookupEnv)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatal(err)
+	}
+	if *printConfig {
+		cfg.Dump(os.Stdout)
+		return
 	}
 
 	// Open a connection to the database
-	db, err := sql.Open("mysql", composeMySQLConnectionString())
+	db, err := sql.Open("mysql", cfg.DB.DSN())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = metrics.RegisterDB(db, cfg.DB.Name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Documents are kept outside MySQL in a blob store
+	blobStore, err := storage.NewFileSystemBlobStore(cfg.BlobStoreDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	messageNotifier, err := newNotifier(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	jwtKeys, err := util.LoadKeySet(cfg.JWT.SigningKeyFile, cfg.JWT.SigningKeyID, cfg.JWT.VerificationKeys, cfg.JWT.Issuer, cfg.JWT.Audience)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
 
 	// Initialize AppConfig with the database connection
 	appConfig := &AppConfig{
-		DB:            db,
-		jwtSecret:     []byte(os.Getenv("JWT_SECRET")),
-		encryptionKey: []byte(os.Getenv("ENCRYPTION_KEY")),
+		DB:                      db,
+		BlobStore:               blobStore,
+		Notifier:                messageNotifier,
+		PasswordPolicy:          cfg.Password,
+		NIKThrottle:             cfg.LoginNIK,
+		IPThrottle:              cfg.LoginIP,
+		MFAIssuer:               cfg.MFAIssuer,
+		OTRTolerancePercent:     cfg.AssetOTRTolerancePercent,
+		JWTKeys:                 jwtKeys,
+		DBTimeouts:              cfg.DB.Timeouts(),
+		DBMaxRetries:            cfg.DB.MaxRetries,
+		DBRetryDelay:            cfg.DB.RetryDelay,
+		PartnerSignatureMaxSkew: cfg.PartnerSignatureMaxSkew,
+		Health:                  health.NewChecker(),
+		encryptionKey:           []byte(cfg.EncryptionKey),
+	}
+
+	// Officers and admins can be forced to use MFA
+	if cfg.MFARequiredForStaff {
+		appConfig.MFARequiredRoles = []string{model.RoleOfficer, model.RoleAdmin}
 	}
 
 	// Initialize router
 	r := mux.NewRouter()
+	r.NotFoundHandler = http.HandlerFunc(apierror.NotFound)
+	r.MethodNotAllowedHandler = http.HandlerFunc(apierror.MethodNotAllowed)
 
 	// handlers
 	registerHandlers(r, appConfig)
 
-	// Start server
-	fmt.Println("Server started on port 8080")
-	http.ListenAndServe(":8080", r)
+	// The request ID, language, metrics and body limit wrap the router so unmatched routes get them too
+	limitBody := middleware.MaxBodySize(int64(cfg.HTTP.MaxBodyBytes), int64(cfg.HTTP.MaxUploadBytes))
+	srv, err := server.New(":"+strconv.Itoa(cfg.Port), cfg.HTTP, middleware.RequestID(middleware.Language(middleware.Metrics(r)(limitBody(r)))))
+	if err != nil {
+		db.Close()
+		log.Fatal(err)
+	}
+	// Readiness fails as soon as shutdown starts, the database pool is closed once in-flight requests are done with it
+	srv.OnDrain(appConfig.Health.Drain)
+	srv.OnShutdown(db.Close)
+
+	// Metrics are served on an internal listener of their own, stopped along with the API
+	if cfg.MetricsAddr != "" {
+		metricsConfig := cfg.HTTP
+		metricsConfig.TLSCertFile, metricsConfig.TLSKeyFile = "", ""
+		metricsConfig.ShutdownDelay = 0
+		metricsServer, err := server.New(cfg.MetricsAddr, metricsConfig, metrics.Handler())
+		if err != nil {
+			db.Close()
+			log.Fatal(err)
+		}
+		srv.Go(func(ctx context.Context) {
+			err := metricsServer.Run(ctx)
+			if err != nil {
+				logrus.Errorf("Metrics server stopped: %v", err)
+			}
+		})
+	}
+
+	// Start server, until SIGINT or SIGTERM drains it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = srv.Run(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 // registerHandlers registers all HTTP handlers
 func registerHandlers(r *mux.Router, appConfig *AppConfig) {
-	customerRepo := repository.NewMySQLCustomerRepository(appConfig.DB)
-	transactionRepo := repository.NewMySQLTransactionRepository(appConfig.DB)
-	limitRepo := repository.NewMySQLLimitRepository(appConfig.DB)
+	customerRepo := repository.NewMySQLCustomerRepository(appConfig.DB, appConfig.DBTimeouts)
+	transactionRepo := repository.NewMySQLTransactionRepository(appConfig.DB, appConfig.DBTimeouts)
+	limitRepo := repository.NewMySQLLimitRepository(appConfig.DB, appConfig.DBTimeouts)
+	documentRepo := repository.NewMySQLDocumentRepository(appConfig.DB, appConfig.DBTimeouts)
+	correctionRepo := repository.NewMySQLCorrectionRepository(appConfig.DB, appConfig.DBTimeouts)
+	passwordResetRepo := repository.NewMySQLPasswordResetRepository(appConfig.DB, appConfig.DBTimeouts)
+	loginAttemptRepo := repository.NewMySQLLoginAttemptRepository(appConfig.DB, appConfig.DBTimeouts)
+	mfaRepo := repository.NewMySQLMFARepository(appConfig.DB, appConfig.DBTimeouts)
+	partnerRepo := repository.NewMySQLPartnerRepository(appConfig.DB, appConfig.DBTimeouts)
+	assetRepo := repository.NewMySQLAssetRepository(appConfig.DB, appConfig.DBTimeouts)
+	pricingRuleRepo := repository.NewMySQLPricingRuleRepository(appConfig.DB, appConfig.DBTimeouts)
+	promotionRepo := repository.NewMySQLPromotionRepository(appConfig.DB, appConfig.DBTimeouts)
+	paymentRepo := repository.NewMySQLPaymentRepository(appConfig.DB, appConfig.DBTimeouts)
+	contractRepo := repository.NewMySQLContractDocumentRepository(appConfig.DB, appConfig.DBTimeouts)
+	schemaRepo := repository.NewMySQLSchemaRepository(appConfig.DB, appConfig.DBTimeouts)
+	unitOfWork := repository.NewMySQLUnitOfWork(appConfig.DB, appConfig.DBTimeouts, appConfig.DBMaxRetries, appConfig.DBRetryDelay)
+
+	statementService := service.NewStatementService(transactionRepo, customerRepo, paymentRepo, unitOfWork)
+	authService := service.NewAuthService(customerRepo, passwordResetRepo, loginAttemptRepo, unitOfWork, appConfig.Notifier,
+		appConfig.PasswordPolicy, appConfig.NIKThrottle, appConfig.IPThrottle, appConfig.MFARequiredRoles)
+	customerService := service.NewCustomerService(customerRepo, correctionRepo, unitOfWork, appConfig.PasswordPolicy)
+	limitService := service.NewLimitService(limitRepo, customerRepo)
+	transactionService := service.NewTransactionService(transactionRepo, limitRepo, customerRepo, partnerRepo, assetRepo,
+		promotionRepo, contractRepo, unitOfWork, pricing.NewEngine(pricingRuleRepo), appConfig.Notifier, appConfig.BlobStore,
+		appConfig.encryptionKey, appConfig.OTRTolerancePercent)
+
+	authHandler := handler.NewAuthHandler(authService, customerService, appConfig.JWTKeys)
+	mfaHandler := handler.NewMFAHandler(customerRepo, mfaRepo, loginAttemptRepo, appConfig.NIKThrottle,
+		appConfig.MFAIssuer, appConfig.JWTKeys, appConfig.encryptionKey)
+	transactionhHandler := handler.NewTransactionHandler(transactionService)
+	limitHandler := handler.NewLimitHandler(limitService)
+	customerHandler := handler.NewCustomerHandler(customerService)
+	documentHandler := handler.NewDocumentHandler(customerRepo, documentRepo, appConfig.BlobStore, appConfig.encryptionKey, util.DefaultImageLimits())
+	jwksHandler := handler.NewJWKSHandler(appConfig.JWTKeys)
+	partnerHandler := handler.NewPartnerHandler(partnerRepo, appConfig.encryptionKey)
+	assetHandler := handler.NewAssetHandler(assetRepo)
+	pricingHandler := handler.NewPricingHandler(pricingRuleRepo)
+	promotionHandler := handler.NewPromotionHandler(promotionRepo)
+	statementHandler := handler.NewStatementHandler(statementService)
+	contractHandler := handler.NewContractHandler(transactionRepo, contractRepo, appConfig.BlobStore, appConfig.encryptionKey)
 
-	authHandler := handler.NewAuthHandler(customerRepo, appConfig.jwtSecret, appConfig.encryptionKey)
-	transactionhHandler := handler.NewTransactionHandler(transactionRepo, limitRepo)
-	limitHandler := handler.NewLimitHandler(limitRepo, customerRepo)
+	// Every subsystem the API cannot serve without is checked for readiness
+	appConfig.Health.Register("database", schemaRepo.Ping)
+	appConfig.Health.Register("schema", schemaRepo.CheckSchemaVersion)
+	appConfig.Health.Register("jwt_keys", appConfig.JWTKeys.Check)
+	appConfig.Health.Register("encryption_key", func(ctx context.Context) error {
+		return util.CheckEncryptionKey(appConfig.encryptionKey)
+	})
+	healthHandler := handler.NewHealthHandler(appConfig.Health, health.Build(commit, buildTime))
+
+	r.HandleFunc("/healthz", healthHandler.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
+	r.HandleFunc("/version", healthHandler.Version).Methods("GET")
+
+	r.HandleFunc("/.well-known/jwks.json", jwksHandler.GetJWKS).Methods("GET")
 
 	r.HandleFunc("/auth/register", authHandler.RegisterCustomer).Methods("POST")
 	r.HandleFunc("/auth/login", authHandler.LoginHandler).Methods("POST")
+	r.HandleFunc("/auth/login/mfa", mfaHandler.Login).Methods("POST")
+	r.HandleFunc("/auth/password/forgot", authHandler.ForgotPassword).Methods("POST")
+	r.HandleFunc("/auth/password/reset", authHandler.ResetPassword).Methods("POST")
+
+	authenticate := func(next http.Handler) http.Handler {
+		return middleware.JWTMiddleware(appConfig.JWTKeys)(middleware.SessionMiddleware(customerRepo)(next))
+	}
+	r.Handle("/auth/password/change", authenticate(http.HandlerFunc(authHandler.ChangePassword))).Methods("POST")
+
+	// MFA enrolment also accepts the enrolment token handed out to staff who have not enrolled yet
+	mfaRouter := r.PathPrefix("/auth/mfa").Subrouter()
+	mfaRouter.Use(middleware.JWTMiddleware(appConfig.JWTKeys, model.TokenPurposeAccess, model.TokenPurposeMFAEnrollment))
+	mfaRouter.Use(middleware.SessionMiddleware(customerRepo))
+
+	mfaRouter.HandleFunc("/enroll", mfaHandler.Enroll).Methods("POST")
+	mfaRouter.HandleFunc("/verify", mfaHandler.Verify).Methods("POST")
 
 	fundRouter := r.PathPrefix("/fund").Subrouter()
-	fundRouter.Use(middleware.JWTMiddleware(appConfig.jwtSecret))
+	fundRouter.Use(middleware.JWTMiddleware(appConfig.JWTKeys))
+	fundRouter.Use(middleware.SessionMiddleware(customerRepo))
 
 	fundRouter.HandleFunc("/transaction", transactionhHandler.CreateTransaction).Methods("POST")
+	fundRouter.HandleFunc("/transactions", transactionhHandler.ListTransactions).Methods("GET")
+	fundRouter.HandleFunc("/transaction/{contract}/statement", statementHandler.GetContractStatement).Methods("GET")
+	fundRouter.HandleFunc("/statements/{month}", statementHandler.GetMonthlyStatement).Methods("GET")
+	fundRouter.HandleFunc("/transaction/{id:[0-9]+}/confirm", transactionhHandler.ConfirmTransaction).Methods("POST")
+	fundRouter.HandleFunc("/transaction/{id:[0-9]+}/cancel", transactionhHandler.CancelTransaction).Methods("POST")
+	fundRouter.HandleFunc("/transaction/{id:[0-9]+}/contract", contractHandler.GetContractDocument).Methods("GET")
+	fundRouter.HandleFunc("/transaction/{id:[0-9]+}/contract/accept", contractHandler.AcceptContractDocument).Methods("POST")
 	fundRouter.HandleFunc("/limit", limitHandler.CreateLimit).Methods("POST")
+	fundRouter.HandleFunc("/assets", assetHandler.ListAssets).Methods("GET")
+
+	customerRouter := r.PathPrefix("/customers").Subrouter()
+	customerRouter.Use(middleware.JWTMiddleware(appConfig.JWTKeys))
+	customerRouter.Use(middleware.SessionMiddleware(customerRepo))
+
+	customerRouter.HandleFunc("/me", customerHandler.GetProfile).Methods("GET")
+	customerRouter.HandleFunc("/me", customerHandler.UpdateProfile).Methods("PATCH")
+	customerRouter.HandleFunc("/me/corrections", customerHandler.CreateCorrectionRequest).Methods("POST")
+	customerRouter.HandleFunc("/me/documents", documentHandler.UploadDocument).Methods("POST")
+	customerRouter.HandleFunc("/me/consents", partnerHandler.GrantConsent).Methods("POST")
+	customerRouter.HandleFunc("/me/consents/{partner_id:[0-9]+}", partnerHandler.RevokeConsent).Methods("DELETE")
+
+	// Partners authenticate with an API key and sign every request instead of using a customer token
+	partnerRouter := r.PathPrefix("/partner").Subrouter()
+	partnerRouter.Use(middleware.PartnerMiddleware(partnerRepo, appConfig.encryptionKey, appConfig.PartnerSignatureMaxSkew))
+
+	partnerRouter.HandleFunc("/transactions", transactionhHandler.CreatePartnerTransaction).Methods("POST")
+	partnerRouter.HandleFunc("/transactions/{id:[0-9]+}/confirm", transactionhHandler.ConfirmPartnerTransaction).Methods("POST")
+
+	adminRouter := r.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.JWTMiddleware(appConfig.JWTKeys))
+	adminRouter.Use(middleware.SessionMiddleware(customerRepo))
+	adminRouter.Use(middleware.RequireRole(model.RoleOfficer, model.RoleAdmin))
+
+	adminRouter.HandleFunc("/customers/{id:[0-9]+}/documents/{type}", documentHandler.GetCustomerDocument).Methods("GET")
+	adminRouter.HandleFunc("/customers/{id:[0-9]+}/unlock", authHandler.UnlockCustomer).Methods("POST")
+	adminRouter.HandleFunc("/corrections", customerHandler.ListCorrectionRequests).Methods("GET")
+	adminRouter.HandleFunc("/corrections/{id:[0-9]+}/review", customerHandler.ReviewCorrectionRequest).Methods("POST")
+	adminRouter.HandleFunc("/assets", assetHandler.ListCatalogue).Methods("GET")
+	adminRouter.HandleFunc("/assets/{id:[0-9]+}", assetHandler.GetAsset).Methods("GET")
+	adminRouter.HandleFunc("/pricing-rules", pricingHandler.ListPricingRules).Methods("GET")
+	adminRouter.HandleFunc("/campaigns", promotionHandler.ListCampaigns).Methods("GET")
+	adminRouter.HandleFunc("/transactions", transactionhHandler.AdminListTransactions).Methods("GET")
+	adminRouter.HandleFunc("/transactions/{id:[0-9]+}/payments", statementHandler.RecordPayment).Methods("POST")
+	adminRouter.HandleFunc("/customers/{id:[0-9]+}/statements/{month}", statementHandler.AdminGetMonthlyStatement).Methods("GET")
+	adminRouter.HandleFunc("/transactions/{id:[0-9]+}/cancel", transactionhHandler.AdminCancelTransaction).Methods("POST")
+
+	// Partners, the asset catalogue, pricing rules and campaigns are maintained by admins only
+	adminOnly := middleware.RequireRole(model.RoleAdmin)
+	adminRouter.Handle("/partners", adminOnly(http.HandlerFunc(partnerHandler.CreatePartner))).Methods("POST")
+	adminRouter.Handle("/assets", adminOnly(http.HandlerFunc(assetHandler.CreateAsset))).Methods("POST")
+	adminRouter.Handle("/assets/{id:[0-9]+}", adminOnly(http.HandlerFunc(assetHandler.UpdateAsset))).Methods("PUT")
+	adminRouter.Handle("/assets/{id:[0-9]+}", adminOnly(http.HandlerFunc(assetHandler.DeleteAsset))).Methods("DELETE")
+	adminRouter.Handle("/pricing-rules", adminOnly(http.HandlerFunc(pricingHandler.CreatePricingRule))).Methods("POST")
+	adminRouter.Handle("/campaigns", adminOnly(http.HandlerFunc(promotionHandler.CreateCampaign))).Methods("POST")
+	adminRouter.Handle("/campaigns/{id:[0-9]+}", adminOnly(http.HandlerFunc(promotionHandler.DeactivateCampaign))).Methods("DELETE")
 }
 
-func composeMySQLConnectionString() string {
-	// "root:password@tcp(localhost:3306)/mydb",
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"))
+// newNotifier creates the notifier selected by NOTIFIER (console or file)
+func newNotifier(cfg *config.Config) (notifier.Notifier, error) {
+	switch cfg.Notifier {
+	case "console":
+		return notifier.NewConsoleNotifier(), nil
+	case "file":
+		return notifier.NewFileNotifier(cfg.NotifierFile)
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER %q", cfg.Notifier)
+	}
 }
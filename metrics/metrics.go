@@ -0,0 +1,114 @@
+// Package metrics holds the Prometheus metrics of the API, served at /metrics: requests by route,
+// the database connection pool, and business events such as registrations and booked contracts.
+// Metrics are recorded where the event happens, like logging, so they are package variables
+// rather than dependencies handed to every constructor.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sigmatech"
+
+// Results of a login
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+)
+
+// Methods of a login
+const (
+	LoginPassword = "password"
+	LoginMFA      = "mfa"
+)
+
+// Registry holds every metric of the API, along with the metrics of the Go runtime and the process
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts requests by method, route template and status code
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes how long requests take by method and route template
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests by method and route template.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route"})
+
+	// Registrations counts customers registered
+	Registrations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Customers registered.",
+	})
+
+	// Logins counts login attempts by method, password or mfa, and result, success or failure
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by method and result.",
+	}, []string{"method", "result"})
+
+	// ContractsBooked counts transactions confirmed by the customer by tenor
+	ContractsBooked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "contracts_booked_total",
+		Help:      "Contracts booked by tenor in months.",
+	}, []string{"tenor"})
+
+	// AmountFinanced sums the OTR less the down payment of booked contracts by tenor
+	AmountFinanced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "amount_financed_total",
+		Help:      "Amount financed by booked contracts, OTR less down payment, by tenor in months.",
+	}, []string{"tenor"})
+
+	// LimitRejections counts transactions refused for exceeding the customer's limit by tenor
+	LimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "limit_rejections_total",
+		Help:      "Transactions refused for exceeding the customer's limit by tenor in months.",
+	}, []string{"tenor"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		Registrations,
+		Logins,
+		ContractsBooked,
+		AmountFinanced,
+		LimitRejections,
+	)
+}
+
+// RegisterDB exports the statistics of the connection pool, such as open, in use and idle
+// connections and the time spent waiting for one
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Tenor is the label of a tenor
+func Tenor(tenor int) string {
+	return strconv.Itoa(tenor)
+}
@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/fund/transaction/{id:[0-9]+}/confirm", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}).Methods("POST")
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.HandleFunc("/assets", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	}).Methods("GET")
+	handler := middleware.Metrics(r)(r)
+
+	requests := func(method, route, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(method, route, status))
+	}
+	confirmed := requests("POST", "/fund/transaction/{id:[0-9]+}/confirm", "409")
+	listed := requests("GET", "/admin/assets", "200")
+	unmatched := requests("GET", "unmatched", "404")
+
+	for _, path := range []string{"/fund/transaction/1/confirm", "/fund/transaction/2/confirm"} {
+		req, _ := http.NewRequest("POST", path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	req, _ := http.NewRequest("GET", "/admin/assets", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest("GET", "/wp-login.php", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, confirmed+2, requests("POST", "/fund/transaction/{id:[0-9]+}/confirm", "409"))
+	assert.Equal(t, listed+1, requests("GET", "/admin/assets", "200"))
+	assert.Equal(t, unmatched+1, requests("GET", "unmatched", "404"))
+}
+
+func TestHandler(t *testing.T) {
+	metrics.Registrations.Inc()
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "sigmatech_registrations_total")
+	assert.Contains(t, recorder.Body.String(), "go_goroutines")
+}
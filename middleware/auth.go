@@ -1,6 +1,12 @@
 package middleware
 
 import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -8,20 +14,26 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func JWTMiddleware(secretKey []byte) func(http.Handler) http.Handler {
+type contextKey string
+
+const claimsContextKey contextKey = "claims"
+
+// JWTMiddleware authenticates requests with a bearer token issued for one of the
+// given purposes, defaulting to regular access tokens
+func JWTMiddleware(keys *util.KeySet, purposes ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get the Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeMissingAuthorization, "Authorization header is required")
 				return
 			}
 
 			// Check if the token starts with "Bearer "
 			tokenString := strings.TrimSpace(authHeader)
 			if !strings.HasPrefix(tokenString, "Bearer ") {
-				http.Error(w, "Authorization header format must be Bearer {token}", http.StatusUnauthorized)
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidAuthorization, "Authorization header format must be Bearer {token}")
 				return
 			}
 
@@ -29,23 +41,83 @@ func JWTMiddleware(secretKey []byte) func(http.Handler) http.Handler {
 			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
 			// Parse the token
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				// Validate the alg is what you expect
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, http.ErrAbortHandler
-				}
-				return secretKey, nil
-			})
-
-			// Check token validity
-			if err != nil || !token.Valid {
+			claims, err := ParseToken(keys, tokenString, purposes...)
+			if err != nil {
 				logrus.Error(err)
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidToken, "Invalid token")
 				return
 			}
 
 			// Pass the execution to the next handler
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// ParseToken validates a signed token against the verification key named by its
+// kid header and checks its issuer and audience. The token must have been issued
+// for one of the given purposes, defaulting to access tokens.
+func ParseToken(keys *util.KeySet, tokenString string, purposes ...string) (*model.Claims, error) {
+	if len(purposes) == 0 {
+		purposes = []string{model.TokenPurposeAccess}
+	}
+
+	claims := &model.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
+
+	// Check token validity
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if !claims.VerifyIssuer(keys.Issuer, true) {
+		return nil, fmt.Errorf("token issuer %q is not accepted", claims.Issuer)
+	}
+	if !claims.VerifyAudience(keys.Audience, true) {
+		return nil, fmt.Errorf("token audience %q is not accepted", claims.Audience)
+	}
+
+	for _, purpose := range purposes {
+		if claims.Purpose == purpose {
+			return claims, nil
+		}
+	}
+
+	return nil, fmt.Errorf("token purpose %q is not accepted here", claims.Purpose)
+}
+
+// RequireRole only lets through requests whose token carries one of the given roles.
+// It must be chained after JWTMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			apierror.Write(w, r, http.StatusForbidden, apierror.CodeForbidden, "Forbidden")
 		})
 	}
 }
+
+// WithClaims returns a copy of ctx carrying the authenticated user's claims
+func WithClaims(ctx context.Context, claims *model.Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+// GetClaims returns the authenticated user's claims stored by JWTMiddleware
+func GetClaims(ctx context.Context) (*model.Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*model.Claims)
+	return claims, ok
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+)
+
+// MaxBodySize bounds the body of every request, so a client cannot make a handler read without
+// end. Multipart uploads may be up to uploadLimit, handlers accepting them check their own limit.
+// Reading past the limit fails with an *http.MaxBytesError.
+func MaxBodySize(limit int64, uploadLimit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			max := limit
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType == "multipart/form-data" {
+				max = uploadLimit
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
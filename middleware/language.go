@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"alif-sigmatech/i18n"
+	"net/http"
+)
+
+// Language sets the language errors are reported in to the supported one Accept-Language prefers,
+// or to the default language when it names none. SessionMiddleware replaces it with the saved
+// preference of the customer.
+func Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang, ok := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		if !ok {
+			lang = i18n.Default
+		}
+
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLanguage(r.Context(), lang)))
+	})
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"alif-sigmatech/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute labels requests no route matches, so probing random paths cannot create series
+const unmatchedRoute = "unmatched"
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Metrics counts and times requests by the path template of the route of router they match,
+// e.g. /fund/transaction/{id:[0-9]+}/confirm, rather than by path
+func Metrics(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := unmatchedRoute
+			var match mux.RouteMatch
+			if router.Match(r, &match) && match.Route != nil {
+				if template, err := match.Route.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+			metrics.HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+		})
+	}
+}
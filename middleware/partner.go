@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Headers of a request signed by a partner
+const (
+	HeaderAPIKey    = "X-API-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// maxNonceLength bounds the X-Nonce of a signed request, e.g. a UUID or 32 random hex digits
+const maxNonceLength = 64
+
+const partnerContextKey contextKey = "partner"
+
+// maxSignedBodyBytes bounds the request body read to verify a signature
+const maxSignedBodyBytes = 1 << 20
+
+// PartnerMiddleware authenticates partner requests by API key and verifies their HMAC
+// signature. Requests whose X-Timestamp (unix seconds) is more than maxSkew away from
+// now are rejected so captured requests cannot be replayed later, and requests repeating
+// the X-Nonce of an earlier one within that window so they cannot be replayed meanwhile.
+// Nonces are remembered in the database, so every instance of the API rejects the repeats.
+func PartnerMiddleware(partnerRepo repository.PartnerRepository, encryptionKey []byte, maxSkew time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get(HeaderAPIKey)
+			if apiKey == "" {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeMissingAPIKey, "API key is required")
+				return
+			}
+
+			apiKeyHash := sha256.Sum256([]byte(apiKey))
+			partner, err := partnerRepo.GetPartnerByAPIKeyHash(r.Context(), hex.EncodeToString(apiKeyHash[:]))
+			if err != nil {
+				logrus.Error(err)
+				apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+				return
+			}
+			if partner == nil || !partner.Active {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidAPIKey, "Invalid API key")
+				return
+			}
+
+			timestamp := r.Header.Get(HeaderTimestamp)
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidTimestamp, "X-Timestamp must be a unix timestamp")
+				return
+			}
+			if skew := time.Since(time.Unix(unix, 0)); skew > maxSkew || skew < -maxSkew {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeTimestampOutsideWindow, "Request timestamp is outside the allowed window")
+				return
+			}
+
+			nonce := r.Header.Get(HeaderNonce)
+			if nonce == "" || len(nonce) > maxNonceLength {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidNonce, "X-Nonce is required and at most 64 characters long")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+			if err != nil {
+				apierror.Write(w, r, http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Request body is too large")
+				return
+			}
+
+			secret, err := util.DecryptData(partner.SigningSecret, encryptionKey)
+			if err != nil {
+				logrus.Error(err)
+				apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+				return
+			}
+
+			if !util.VerifyRequestSignature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body, r.Header.Get(HeaderSignature)) {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeInvalidSignature, "Invalid signature")
+				return
+			}
+
+			// Only signed requests record their nonce, so others cannot use up a partner's nonces. A
+			// request is accepted from maxSkew before its timestamp until maxSkew after it.
+			now := time.Now()
+			recorded, err := partnerRepo.RecordNonce(r.Context(), partner.ID, nonce, now, now.Add(2*maxSkew))
+			if err != nil {
+				logrus.Error(err)
+				apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+				return
+			}
+			if !recorded {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeReplayedRequest, "Request has already been received")
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r.WithContext(WithPartner(r.Context(), partner)))
+		})
+	}
+}
+
+// WithPartner returns a copy of ctx carrying the authenticated partner
+func WithPartner(ctx context.Context, partner *model.Partner) context.Context {
+	return context.WithValue(ctx, partnerContextKey, partner)
+}
+
+// GetPartner returns the authenticated partner stored by PartnerMiddleware
+func GetPartner(ctx context.Context) (*model.Partner, bool) {
+	partner, ok := ctx.Value(partnerContextKey).(*model.Partner)
+	return partner, ok
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"alif-sigmatech/util"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// RequestIDHeader carries the ID of a request in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern accepts the IDs a proxy or client may set, anything else is replaced
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request an ID, keeping the one set by a proxy in front of us when it is
+// well-formed. The ID is echoed in the response and reported in error bodies.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(util.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
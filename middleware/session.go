@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/repository"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SessionMiddleware rejects tokens issued before the customer's sessions were revoked,
+// e.g. by a password reset. It must be chained after JWTMiddleware.
+func SessionMiddleware(customerRepo repository.CustomerRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeUnauthorized, "Unauthorized")
+				return
+			}
+
+			customer, err := customerRepo.GetCustomerByID(r.Context(), claims.CustomerID)
+			if err != nil {
+				logrus.Error(err)
+				apierror.Write(w, r, http.StatusInternalServerError, apierror.CodeInternal, "Something went wrong")
+				return
+			}
+			if customer == nil || customer.TokenVersion != claims.TokenVersion {
+				apierror.Write(w, r, http.StatusUnauthorized, apierror.CodeSessionRevoked, "Session has been revoked")
+				return
+			}
+
+			// The language the customer chose in the app wins over the one of the device
+			if lang, ok := i18n.Parse(customer.Language); ok {
+				r = r.WithContext(i18n.WithLanguage(r.Context(), lang))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
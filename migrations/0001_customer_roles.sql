@@ -0,0 +1,14 @@
+-- Customer roles, so officers can view KTP and selfie documents, and the log of those views
+ALTER TABLE customer ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer' AFTER salary;
+
+CREATE TABLE document_access_log (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    officer_id INT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45),
+    accessed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (officer_id) REFERENCES customer(id)
+);
@@ -0,0 +1,15 @@
+-- Uploaded customer documents, stored in the blob store with their normalized metadata
+CREATE TABLE customer_document (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size INT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_customer_document_type (customer_id, document_type),
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
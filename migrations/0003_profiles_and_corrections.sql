@@ -0,0 +1,20 @@
+-- Profile fields customers edit themselves, and the corrections of identity fields officers approve
+ALTER TABLE customer
+    ADD COLUMN address VARCHAR(255) NOT NULL DEFAULT '' AFTER salary,
+    ADD COLUMN phone_number VARCHAR(20) NOT NULL DEFAULT '' AFTER address;
+
+CREATE TABLE correction_request (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    field_name VARCHAR(50) NOT NULL,
+    requested_value VARCHAR(255) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by INT,
+    review_note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP NULL,
+    INDEX idx_correction_request_status (status),
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (reviewed_by) REFERENCES customer(id)
+);
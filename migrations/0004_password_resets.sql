@@ -0,0 +1,12 @@
+-- Token versions, which revoke the sessions of a customer, and password reset tokens
+ALTER TABLE customer ADD COLUMN token_version INT NOT NULL DEFAULT 0 AFTER role;
+
+CREATE TABLE password_reset_token (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
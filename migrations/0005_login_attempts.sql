@@ -0,0 +1,6 @@
+-- Failed logins per NIK and IP address, which throttle further attempts
+CREATE TABLE login_attempt (
+    attempt_key VARCHAR(100) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,14 @@
+-- TOTP secrets of customers and their single-use recovery codes
+ALTER TABLE customer
+    ADD COLUMN mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE AFTER token_version,
+    ADD COLUMN mfa_secret VARBINARY(255) AFTER mfa_enabled;
+
+CREATE TABLE mfa_recovery_code (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_mfa_recovery_code (customer_id, code_hash),
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
@@ -0,0 +1,8 @@
+-- Transactions wait for a one-time code before they are booked. Transactions stored before
+-- were booked right away, so they default to confirmed.
+ALTER TABLE transaction
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'confirmed' AFTER tenor,
+    ADD COLUMN otp_hash CHAR(64) AFTER status,
+    ADD COLUMN otp_attempts INT NOT NULL DEFAULT 0 AFTER otp_hash,
+    ADD COLUMN otp_expires_at DATETIME AFTER otp_attempts,
+    ADD COLUMN confirmed_at DATETIME AFTER otp_expires_at;
@@ -0,0 +1,28 @@
+-- Partners booking through the signed partner API, the consent customers give them, and the
+-- channel each transaction came through
+CREATE TABLE partner (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    api_key_prefix VARCHAR(16) NOT NULL,
+    api_key_hash CHAR(64) NOT NULL UNIQUE,
+    signing_secret VARBINARY(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE partner_consent (
+    customer_id INT NOT NULL,
+    partner_id INT NOT NULL,
+    granted_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    PRIMARY KEY (customer_id, partner_id),
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (partner_id) REFERENCES partner(id)
+);
+
+ALTER TABLE transaction
+    ADD COLUMN channel VARCHAR(20) NOT NULL DEFAULT 'app' AFTER confirmed_at,
+    ADD COLUMN partner_id INT AFTER channel,
+    ADD FOREIGN KEY (partner_id) REFERENCES partner(id),
+    ADD INDEX idx_transaction_channel (channel, partner_id);
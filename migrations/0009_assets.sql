@@ -0,0 +1,20 @@
+-- The asset catalogue transactions finance, and the asset and down payment of each transaction
+CREATE TABLE asset (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    category VARCHAR(50) NOT NULL,
+    brand VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    min_otr DECIMAL(15, 2) NOT NULL,
+    max_otr DECIMAL(15, 2) NOT NULL,
+    max_finance_percent DECIMAL(5, 2) NOT NULL,
+    allowed_tenors VARCHAR(50) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE KEY uq_asset (category, brand, model)
+);
+
+ALTER TABLE transaction
+    ADD COLUMN asset_id INT AFTER interest_amount,
+    ADD COLUMN down_payment DECIMAL(15, 2) NOT NULL DEFAULT 0 AFTER asset_name,
+    ADD FOREIGN KEY (asset_id) REFERENCES asset(id);
@@ -0,0 +1,30 @@
+-- Risk grades of customers and the versioned pricing rules admin fees and interest come from
+ALTER TABLE customer ADD COLUMN risk_grade VARCHAR(10) NOT NULL DEFAULT '' AFTER role;
+
+CREATE TABLE pricing_rule (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    tenor INT NOT NULL DEFAULT 0,
+    asset_category VARCHAR(50) NOT NULL DEFAULT '',
+    partner_id INT,
+    risk_grade VARCHAR(10) NOT NULL DEFAULT '',
+    promo_code VARCHAR(50) NOT NULL DEFAULT '',
+    priority INT NOT NULL DEFAULT 0,
+    admin_fee DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    admin_fee_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    monthly_interest_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    effective_from DATETIME NOT NULL,
+    effective_to DATETIME,
+    created_at DATETIME NOT NULL,
+    UNIQUE KEY uq_pricing_rule_version (code, version),
+    FOREIGN KEY (partner_id) REFERENCES partner(id),
+    INDEX idx_pricing_rule_effective (effective_from, effective_to)
+);
+
+ALTER TABLE transaction
+    ADD COLUMN promo_code VARCHAR(50) NOT NULL DEFAULT '' AFTER partner_id,
+    ADD COLUMN pricing_rule_id INT AFTER promo_code,
+    ADD COLUMN pricing_rule_version INT AFTER pricing_rule_id,
+    ADD FOREIGN KEY (pricing_rule_id) REFERENCES pricing_rule(id);
@@ -0,0 +1,38 @@
+-- Promotion campaigns, the vouchers redeemed under them, and the cancellation of transactions
+ALTER TABLE transaction
+    ADD COLUMN cancelled_at DATETIME AFTER confirmed_at,
+    ADD COLUMN discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0 AFTER promo_code;
+
+CREATE TABLE promo_campaign (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    admin_fee_discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    interest_discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    tenor INT NOT NULL DEFAULT 0,
+    asset_category VARCHAR(50) NOT NULL DEFAULT '',
+    channel VARCHAR(20) NOT NULL DEFAULT '',
+    min_financed_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME,
+    budget DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    budget_used DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    max_redemptions INT NOT NULL DEFAULT 0,
+    redemptions INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE promo_redemption (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    customer_id INT NOT NULL,
+    transaction_id INT NOT NULL UNIQUE,
+    discount_amount DECIMAL(15, 2) NOT NULL,
+    redeemed_at DATETIME NOT NULL,
+    reversed_at DATETIME,
+    FOREIGN KEY (campaign_id) REFERENCES promo_campaign(id),
+    FOREIGN KEY (customer_id) REFERENCES customer(id),
+    FOREIGN KEY (transaction_id) REFERENCES transaction(id),
+    INDEX idx_promo_redemption_customer (campaign_id, customer_id)
+);
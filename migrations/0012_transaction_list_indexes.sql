@@ -0,0 +1,9 @@
+-- Listings are keyset paginated on (sort column, id), filtered by customer for /fund/transactions
+ALTER TABLE transaction
+    ADD INDEX idx_transaction_customer_created (customer_id, created_at, id),
+    ADD INDEX idx_transaction_customer_otr (customer_id, otr, id),
+    ADD INDEX idx_transaction_created (created_at, id),
+    ADD INDEX idx_transaction_otr (otr, id),
+    ADD INDEX idx_transaction_installment (installment_amount, id),
+    ADD INDEX idx_transaction_status_created (status, created_at, id),
+    ADD INDEX idx_transaction_partner_created (partner_id, created_at, id);
@@ -0,0 +1,15 @@
+-- Payments received for transactions, which statements of account are built from
+ALTER TABLE transaction ADD INDEX idx_transaction_contract (customer_id, contract_number);
+
+CREATE TABLE payment (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    paid_at DATETIME NOT NULL,
+    recorded_by INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transaction(id),
+    FOREIGN KEY (recorded_by) REFERENCES customer(id),
+    INDEX idx_payment_transaction (transaction_id, paid_at)
+);
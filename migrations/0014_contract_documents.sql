@@ -0,0 +1,16 @@
+-- Contract documents generated for transactions and their acceptance by the customer
+CREATE TABLE contract_document (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL UNIQUE,
+    customer_id INT NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    content_hash CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    accepted_at DATETIME,
+    accepted_hash CHAR(64),
+    accepted_ip VARCHAR(45),
+    accepted_user_agent VARCHAR(255),
+    FOREIGN KEY (transaction_id) REFERENCES transaction(id),
+    FOREIGN KEY (customer_id) REFERENCES customer(id)
+);
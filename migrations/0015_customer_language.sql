@@ -0,0 +1,2 @@
+-- The language customers are notified in
+ALTER TABLE customer ADD COLUMN language VARCHAR(5) NOT NULL DEFAULT '' AFTER phone_number;
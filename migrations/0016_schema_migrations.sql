@@ -0,0 +1,10 @@
+-- Tracks the migrations applied, which /readyz checks against repository.SchemaVersion. Migrations
+-- 1 to 15 predate this table, so it records them along with this one.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schema_migrations (version)
+VALUES (1), (2), (3), (4), (5), (6), (7), (8), (9), (10), (11), (12), (13), (14), (15), (16)
+ON DUPLICATE KEY UPDATE version = version;
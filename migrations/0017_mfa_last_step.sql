@@ -0,0 +1,4 @@
+-- Remembers the time step of the last TOTP code each customer used, so a code cannot be used twice
+ALTER TABLE customer ADD COLUMN mfa_last_step BIGINT AFTER mfa_secret;
+
+INSERT INTO schema_migrations (version) VALUES (17) ON DUPLICATE KEY UPDATE version = version;
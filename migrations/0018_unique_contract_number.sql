@@ -0,0 +1,9 @@
+-- A contract number identifies one transaction of the customer, so a cancelled retry cannot shadow
+-- the booked contract. This fails while a customer has two transactions with the same contract
+-- number; find them with
+--   SELECT customer_id, contract_number FROM transaction GROUP BY customer_id, contract_number HAVING COUNT(*) > 1;
+ALTER TABLE transaction
+    DROP INDEX idx_transaction_contract,
+    ADD UNIQUE KEY uq_transaction_contract (customer_id, contract_number);
+
+INSERT INTO schema_migrations (version) VALUES (18) ON DUPLICATE KEY UPDATE version = version;
@@ -0,0 +1,12 @@
+-- Nonces of signed partner requests are shared by every instance of the API, so a request
+-- replayed to another instance within the signature window is refused as well
+CREATE TABLE partner_nonce (
+    partner_id INT NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (partner_id, nonce),
+    INDEX idx_partner_nonce_expires (partner_id, expires_at),
+    FOREIGN KEY (partner_id) REFERENCES partner(id)
+);
+
+INSERT INTO schema_migrations (version) VALUES (19) ON DUPLICATE KEY UPDATE version = version;
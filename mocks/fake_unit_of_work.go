@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"alif-sigmatech/repository"
+	"context"
+)
+
+// FakeUnitOfWork is a repository.UnitOfWork for unit tests. It runs functions with the given
+// (usually mocked) repositories and counts how units of work ended instead of touching a database.
+// Like the MySQL implementation, a unit of work started inside another one only ends the outer one.
+type FakeUnitOfWork struct {
+	Repositories repository.Repositories
+	// Commits and Rollbacks count the outermost units of work that succeeded or failed
+	Commits   int
+	Rollbacks int
+	depth     int
+}
+
+// NewFakeUnitOfWork creates a new instance of FakeUnitOfWork
+func NewFakeUnitOfWork(repos repository.Repositories) *FakeUnitOfWork {
+	return &FakeUnitOfWork{
+		Repositories: repos,
+	}
+}
+
+// Do runs fn with the fake's repositories
+func (u *FakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos repository.Repositories) error) error {
+	u.depth++
+	err := fn(ctx, u.Repositories)
+	u.depth--
+
+	if u.depth == 0 {
+		if err != nil {
+			u.Rollbacks++
+		} else {
+			u.Commits++
+		}
+	}
+	return err
+}
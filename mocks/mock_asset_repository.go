@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/asset.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAssetRepository is a mock of AssetRepository interface.
+type MockAssetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssetRepositoryMockRecorder
+}
+
+// MockAssetRepositoryMockRecorder is the mock recorder for MockAssetRepository.
+type MockAssetRepositoryMockRecorder struct {
+	mock *MockAssetRepository
+}
+
+// NewMockAssetRepository creates a new mock instance.
+func NewMockAssetRepository(ctrl *gomock.Controller) *MockAssetRepository {
+	mock := &MockAssetRepository{ctrl: ctrl}
+	mock.recorder = &MockAssetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssetRepository) EXPECT() *MockAssetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAsset mocks base method.
+func (m *MockAssetRepository) CreateAsset(ctx context.Context, asset *model.Asset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAsset", ctx, asset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAsset indicates an expected call of CreateAsset.
+func (mr *MockAssetRepositoryMockRecorder) CreateAsset(ctx, asset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAsset", reflect.TypeOf((*MockAssetRepository)(nil).CreateAsset), ctx, asset)
+}
+
+// DeactivateAsset mocks base method.
+func (m *MockAssetRepository) DeactivateAsset(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateAsset", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateAsset indicates an expected call of DeactivateAsset.
+func (mr *MockAssetRepositoryMockRecorder) DeactivateAsset(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateAsset", reflect.TypeOf((*MockAssetRepository)(nil).DeactivateAsset), ctx, id)
+}
+
+// GetAssetByID mocks base method.
+func (m *MockAssetRepository) GetAssetByID(ctx context.Context, id int) (*model.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssetByID", ctx, id)
+	ret0, _ := ret[0].(*model.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssetByID indicates an expected call of GetAssetByID.
+func (mr *MockAssetRepositoryMockRecorder) GetAssetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetByID", reflect.TypeOf((*MockAssetRepository)(nil).GetAssetByID), ctx, id)
+}
+
+// ListAssets mocks base method.
+func (m *MockAssetRepository) ListAssets(ctx context.Context, category string, activeOnly bool) ([]model.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssets", ctx, category, activeOnly)
+	ret0, _ := ret[0].([]model.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAssets indicates an expected call of ListAssets.
+func (mr *MockAssetRepositoryMockRecorder) ListAssets(ctx, category, activeOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssets", reflect.TypeOf((*MockAssetRepository)(nil).ListAssets), ctx, category, activeOnly)
+}
+
+// UpdateAsset mocks base method.
+func (m *MockAssetRepository) UpdateAsset(ctx context.Context, asset *model.Asset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAsset", ctx, asset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAsset indicates an expected call of UpdateAsset.
+func (mr *MockAssetRepositoryMockRecorder) UpdateAsset(ctx, asset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAsset", reflect.TypeOf((*MockAssetRepository)(nil).UpdateAsset), ctx, asset)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/contract_document.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockContractDocumentRepository is a mock of ContractDocumentRepository interface.
+type MockContractDocumentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockContractDocumentRepositoryMockRecorder
+}
+
+// MockContractDocumentRepositoryMockRecorder is the mock recorder for MockContractDocumentRepository.
+type MockContractDocumentRepositoryMockRecorder struct {
+	mock *MockContractDocumentRepository
+}
+
+// NewMockContractDocumentRepository creates a new mock instance.
+func NewMockContractDocumentRepository(ctrl *gomock.Controller) *MockContractDocumentRepository {
+	mock := &MockContractDocumentRepository{ctrl: ctrl}
+	mock.recorder = &MockContractDocumentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContractDocumentRepository) EXPECT() *MockContractDocumentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AcceptContractDocument mocks base method.
+func (m *MockContractDocumentRepository) AcceptContractDocument(ctx context.Context, id int, contentHash, ip, userAgent string, acceptedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptContractDocument", ctx, id, contentHash, ip, userAgent, acceptedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptContractDocument indicates an expected call of AcceptContractDocument.
+func (mr *MockContractDocumentRepositoryMockRecorder) AcceptContractDocument(ctx, id, contentHash, ip, userAgent, acceptedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptContractDocument", reflect.TypeOf((*MockContractDocumentRepository)(nil).AcceptContractDocument), ctx, id, contentHash, ip, userAgent, acceptedAt)
+}
+
+// CreateContractDocument mocks base method.
+func (m *MockContractDocumentRepository) CreateContractDocument(ctx context.Context, document *model.ContractDocument) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateContractDocument", ctx, document)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateContractDocument indicates an expected call of CreateContractDocument.
+func (mr *MockContractDocumentRepositoryMockRecorder) CreateContractDocument(ctx, document interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContractDocument", reflect.TypeOf((*MockContractDocumentRepository)(nil).CreateContractDocument), ctx, document)
+}
+
+// GetContractDocumentByTransactionID mocks base method.
+func (m *MockContractDocumentRepository) GetContractDocumentByTransactionID(ctx context.Context, transactionID int) (*model.ContractDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractDocumentByTransactionID", ctx, transactionID)
+	ret0, _ := ret[0].(*model.ContractDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractDocumentByTransactionID indicates an expected call of GetContractDocumentByTransactionID.
+func (mr *MockContractDocumentRepositoryMockRecorder) GetContractDocumentByTransactionID(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractDocumentByTransactionID", reflect.TypeOf((*MockContractDocumentRepository)(nil).GetContractDocumentByTransactionID), ctx, transactionID)
+}
@@ -0,0 +1,136 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/correction.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCorrectionRepository is a mock of CorrectionRepository interface.
+type MockCorrectionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCorrectionRepositoryMockRecorder
+}
+
+// MockCorrectionRepositoryMockRecorder is the mock recorder for MockCorrectionRepository.
+type MockCorrectionRepositoryMockRecorder struct {
+	mock *MockCorrectionRepository
+}
+
+// NewMockCorrectionRepository creates a new mock instance.
+func NewMockCorrectionRepository(ctrl *gomock.Controller) *MockCorrectionRepository {
+	mock := &MockCorrectionRepository{ctrl: ctrl}
+	mock.recorder = &MockCorrectionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCorrectionRepository) EXPECT() *MockCorrectionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateCorrectionRequest mocks base method.
+func (m *MockCorrectionRepository) CreateCorrectionRequest(ctx context.Context, request *model.CorrectionRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCorrectionRequest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCorrectionRequest indicates an expected call of CreateCorrectionRequest.
+func (mr *MockCorrectionRepositoryMockRecorder) CreateCorrectionRequest(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCorrectionRequest", reflect.TypeOf((*MockCorrectionRepository)(nil).CreateCorrectionRequest), ctx, request)
+}
+
+// GetCorrectionRequestByID mocks base method.
+func (m *MockCorrectionRepository) GetCorrectionRequestByID(ctx context.Context, id int) (*model.CorrectionRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCorrectionRequestByID", ctx, id)
+	ret0, _ := ret[0].(*model.CorrectionRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCorrectionRequestByID indicates an expected call of GetCorrectionRequestByID.
+func (mr *MockCorrectionRepositoryMockRecorder) GetCorrectionRequestByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCorrectionRequestByID", reflect.TypeOf((*MockCorrectionRepository)(nil).GetCorrectionRequestByID), ctx, id)
+}
+
+// ListCorrectionRequests mocks base method.
+func (m *MockCorrectionRepository) ListCorrectionRequests(ctx context.Context, status string) ([]model.CorrectionRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCorrectionRequests", ctx, status)
+	ret0, _ := ret[0].([]model.CorrectionRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCorrectionRequests indicates an expected call of ListCorrectionRequests.
+func (mr *MockCorrectionRepositoryMockRecorder) ListCorrectionRequests(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCorrectionRequests", reflect.TypeOf((*MockCorrectionRepository)(nil).ListCorrectionRequests), ctx, status)
+}
+
+// UpdateCorrectionRequestReview mocks base method.
+func (m *MockCorrectionRepository) UpdateCorrectionRequestReview(ctx context.Context, request *model.CorrectionRequest) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCorrectionRequestReview", ctx, request)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCorrectionRequestReview indicates an expected call of UpdateCorrectionRequestReview.
+func (mr *MockCorrectionRepositoryMockRecorder) UpdateCorrectionRequestReview(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCorrectionRequestReview", reflect.TypeOf((*MockCorrectionRepository)(nil).UpdateCorrectionRequestReview), ctx, request)
+}
+
+// MockrowScanner is a mock of rowScanner interface.
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner.
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance.
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockrowScanner) Scan(dest ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockrowScannerMockRecorder) Scan(dest ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
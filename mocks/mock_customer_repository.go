@@ -6,6 +6,7 @@ package mocks
 
 import (
 	model "alif-sigmatech/model"
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,45 +36,115 @@ func (m *MockCustomerRepository) EXPECT() *MockCustomerRepositoryMockRecorder {
 }
 
 // GetCustomerByID mocks base method.
-func (m *MockCustomerRepository) GetCustomerByID(id int) (*model.Customer, error) {
+func (m *MockCustomerRepository) GetCustomerByID(ctx context.Context, id int) (*model.Customer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCustomerByID", id)
+	ret := m.ctrl.Call(m, "GetCustomerByID", ctx, id)
 	ret0, _ := ret[0].(*model.Customer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCustomerByID indicates an expected call of GetCustomerByID.
-func (mr *MockCustomerRepositoryMockRecorder) GetCustomerByID(id interface{}) *gomock.Call {
+func (mr *MockCustomerRepositoryMockRecorder) GetCustomerByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByID", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomerByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByID", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomerByID), ctx, id)
 }
 
 // GetCustomerByNIK mocks base method.
-func (m *MockCustomerRepository) GetCustomerByNIK(nik string) (*model.Customer, error) {
+func (m *MockCustomerRepository) GetCustomerByNIK(ctx context.Context, nik string) (*model.Customer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCustomerByNIK", nik)
+	ret := m.ctrl.Call(m, "GetCustomerByNIK", ctx, nik)
 	ret0, _ := ret[0].(*model.Customer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCustomerByNIK indicates an expected call of GetCustomerByNIK.
-func (mr *MockCustomerRepositoryMockRecorder) GetCustomerByNIK(nik interface{}) *gomock.Call {
+func (mr *MockCustomerRepositoryMockRecorder) GetCustomerByNIK(ctx, nik interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByNIK", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomerByNIK), nik)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByNIK", reflect.TypeOf((*MockCustomerRepository)(nil).GetCustomerByNIK), ctx, nik)
 }
 
 // RegisterCustomer mocks base method.
-func (m *MockCustomerRepository) RegisterCustomer(customer *model.Customer) error {
+func (m *MockCustomerRepository) RegisterCustomer(ctx context.Context, customer *model.Customer) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterCustomer", customer)
+	ret := m.ctrl.Call(m, "RegisterCustomer", ctx, customer)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RegisterCustomer indicates an expected call of RegisterCustomer.
-func (mr *MockCustomerRepositoryMockRecorder) RegisterCustomer(customer interface{}) *gomock.Call {
+func (mr *MockCustomerRepositoryMockRecorder) RegisterCustomer(ctx, customer interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterCustomer", reflect.TypeOf((*MockCustomerRepository)(nil).RegisterCustomer), customer)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterCustomer", reflect.TypeOf((*MockCustomerRepository)(nil).RegisterCustomer), ctx, customer)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockCustomerRepository) RevokeSessions(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockCustomerRepositoryMockRecorder) RevokeSessions(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockCustomerRepository)(nil).RevokeSessions), ctx, id)
+}
+
+// UpdateCustomerIdentity mocks base method.
+func (m *MockCustomerRepository) UpdateCustomerIdentity(ctx context.Context, customer *model.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomerIdentity", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomerIdentity indicates an expected call of UpdateCustomerIdentity.
+func (mr *MockCustomerRepositoryMockRecorder) UpdateCustomerIdentity(ctx, customer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomerIdentity", reflect.TypeOf((*MockCustomerRepository)(nil).UpdateCustomerIdentity), ctx, customer)
+}
+
+// UpdateCustomerProfile mocks base method.
+func (m *MockCustomerRepository) UpdateCustomerProfile(ctx context.Context, customer *model.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomerProfile", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomerProfile indicates an expected call of UpdateCustomerProfile.
+func (mr *MockCustomerRepositoryMockRecorder) UpdateCustomerProfile(ctx, customer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomerProfile", reflect.TypeOf((*MockCustomerRepository)(nil).UpdateCustomerProfile), ctx, customer)
+}
+
+// UpdateMFA mocks base method.
+func (m *MockCustomerRepository) UpdateMFA(ctx context.Context, id int, encryptedSecret []byte, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMFA", ctx, id, encryptedSecret, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMFA indicates an expected call of UpdateMFA.
+func (mr *MockCustomerRepositoryMockRecorder) UpdateMFA(ctx, id, encryptedSecret, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMFA", reflect.TypeOf((*MockCustomerRepository)(nil).UpdateMFA), ctx, id, encryptedSecret, enabled)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockCustomerRepository) UpdatePassword(ctx context.Context, id int, hashedPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, hashedPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockCustomerRepositoryMockRecorder) UpdatePassword(ctx, id, hashedPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockCustomerRepository)(nil).UpdatePassword), ctx, id, hashedPassword)
 }
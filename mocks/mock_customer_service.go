@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service/customer.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	service "alif-sigmatech/service"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCustomerService is a mock of CustomerService interface.
+type MockCustomerService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerServiceMockRecorder
+}
+
+// MockCustomerServiceMockRecorder is the mock recorder for MockCustomerService.
+type MockCustomerServiceMockRecorder struct {
+	mock *MockCustomerService
+}
+
+// NewMockCustomerService creates a new mock instance.
+func NewMockCustomerService(ctrl *gomock.Controller) *MockCustomerService {
+	mock := &MockCustomerService{ctrl: ctrl}
+	mock.recorder = &MockCustomerServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomerService) EXPECT() *MockCustomerServiceMockRecorder {
+	return m.recorder
+}
+
+// GetProfile mocks base method.
+func (m *MockCustomerService) GetProfile(ctx context.Context, customerID int) (*model.CustomerProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, customerID)
+	ret0, _ := ret[0].(*model.CustomerProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockCustomerServiceMockRecorder) GetProfile(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockCustomerService)(nil).GetProfile), ctx, customerID)
+}
+
+// ListCorrectionRequests mocks base method.
+func (m *MockCustomerService) ListCorrectionRequests(ctx context.Context, status string) ([]model.CorrectionRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCorrectionRequests", ctx, status)
+	ret0, _ := ret[0].([]model.CorrectionRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCorrectionRequests indicates an expected call of ListCorrectionRequests.
+func (mr *MockCustomerServiceMockRecorder) ListCorrectionRequests(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCorrectionRequests", reflect.TypeOf((*MockCustomerService)(nil).ListCorrectionRequests), ctx, status)
+}
+
+// Register mocks base method.
+func (m *MockCustomerService) Register(ctx context.Context, input service.RegisterCustomerInput) (*model.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, input)
+	ret0, _ := ret[0].(*model.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockCustomerServiceMockRecorder) Register(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockCustomerService)(nil).Register), ctx, input)
+}
+
+// RequestCorrection mocks base method.
+func (m *MockCustomerService) RequestCorrection(ctx context.Context, customerID int, input service.CorrectionInput) (*model.CorrectionRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestCorrection", ctx, customerID, input)
+	ret0, _ := ret[0].(*model.CorrectionRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestCorrection indicates an expected call of RequestCorrection.
+func (mr *MockCustomerServiceMockRecorder) RequestCorrection(ctx, customerID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestCorrection", reflect.TypeOf((*MockCustomerService)(nil).RequestCorrection), ctx, customerID, input)
+}
+
+// ReviewCorrectionRequest mocks base method.
+func (m *MockCustomerService) ReviewCorrectionRequest(ctx context.Context, reviewerID, id int, review model.ReviewCorrectionRequest) (*model.CorrectionRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewCorrectionRequest", ctx, reviewerID, id, review)
+	ret0, _ := ret[0].(*model.CorrectionRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewCorrectionRequest indicates an expected call of ReviewCorrectionRequest.
+func (mr *MockCustomerServiceMockRecorder) ReviewCorrectionRequest(ctx, reviewerID, id, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewCorrectionRequest", reflect.TypeOf((*MockCustomerService)(nil).ReviewCorrectionRequest), ctx, reviewerID, id, review)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockCustomerService) UpdateProfile(ctx context.Context, customerID int, update model.UpdateProfileRequest) (*model.CustomerProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, customerID, update)
+	ret0, _ := ret[0].(*model.CustomerProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockCustomerServiceMockRecorder) UpdateProfile(ctx, customerID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockCustomerService)(nil).UpdateProfile), ctx, customerID, update)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/document.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDocumentRepository is a mock of DocumentRepository interface.
+type MockDocumentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentRepositoryMockRecorder
+}
+
+// MockDocumentRepositoryMockRecorder is the mock recorder for MockDocumentRepository.
+type MockDocumentRepositoryMockRecorder struct {
+	mock *MockDocumentRepository
+}
+
+// NewMockDocumentRepository creates a new mock instance.
+func NewMockDocumentRepository(ctrl *gomock.Controller) *MockDocumentRepository {
+	mock := &MockDocumentRepository{ctrl: ctrl}
+	mock.recorder = &MockDocumentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentRepository) EXPECT() *MockDocumentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAccessLog mocks base method.
+func (m *MockDocumentRepository) CreateAccessLog(ctx context.Context, accessLog *model.DocumentAccessLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessLog", ctx, accessLog)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccessLog indicates an expected call of CreateAccessLog.
+func (mr *MockDocumentRepositoryMockRecorder) CreateAccessLog(ctx, accessLog interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessLog", reflect.TypeOf((*MockDocumentRepository)(nil).CreateAccessLog), ctx, accessLog)
+}
+
+// CreateDocument mocks base method.
+func (m *MockDocumentRepository) CreateDocument(ctx context.Context, document *model.CustomerDocument) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDocument", ctx, document)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDocument indicates an expected call of CreateDocument.
+func (mr *MockDocumentRepositoryMockRecorder) CreateDocument(ctx, document interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDocument", reflect.TypeOf((*MockDocumentRepository)(nil).CreateDocument), ctx, document)
+}
+
+// GetLatestDocument mocks base method.
+func (m *MockDocumentRepository) GetLatestDocument(ctx context.Context, customerID int, documentType string) (*model.CustomerDocument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestDocument", ctx, customerID, documentType)
+	ret0, _ := ret[0].(*model.CustomerDocument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestDocument indicates an expected call of GetLatestDocument.
+func (mr *MockDocumentRepositoryMockRecorder) GetLatestDocument(ctx, customerID, documentType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestDocument", reflect.TypeOf((*MockDocumentRepository)(nil).GetLatestDocument), ctx, customerID, documentType)
+}
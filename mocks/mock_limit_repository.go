@@ -6,6 +6,7 @@ package mocks
 
 import (
 	model "alif-sigmatech/model"
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,30 +36,45 @@ func (m *MockLimitRepository) EXPECT() *MockLimitRepositoryMockRecorder {
 }
 
 // CreateLimit mocks base method.
-func (m *MockLimitRepository) CreateLimit(limit *model.Limit) error {
+func (m *MockLimitRepository) CreateLimit(ctx context.Context, limit *model.Limit) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateLimit", limit)
+	ret := m.ctrl.Call(m, "CreateLimit", ctx, limit)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateLimit indicates an expected call of CreateLimit.
-func (mr *MockLimitRepositoryMockRecorder) CreateLimit(limit interface{}) *gomock.Call {
+func (mr *MockLimitRepositoryMockRecorder) CreateLimit(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimit", reflect.TypeOf((*MockLimitRepository)(nil).CreateLimit), limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimit", reflect.TypeOf((*MockLimitRepository)(nil).CreateLimit), ctx, limit)
 }
 
 // GetLimitByCustomerID mocks base method.
-func (m *MockLimitRepository) GetLimitByCustomerID(customerID int) (*model.Limit, error) {
+func (m *MockLimitRepository) GetLimitByCustomerID(ctx context.Context, customerID int) (*model.Limit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetLimitByCustomerID", customerID)
+	ret := m.ctrl.Call(m, "GetLimitByCustomerID", ctx, customerID)
 	ret0, _ := ret[0].(*model.Limit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetLimitByCustomerID indicates an expected call of GetLimitByCustomerID.
-func (mr *MockLimitRepositoryMockRecorder) GetLimitByCustomerID(customerID interface{}) *gomock.Call {
+func (mr *MockLimitRepositoryMockRecorder) GetLimitByCustomerID(ctx, customerID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimitByCustomerID", reflect.TypeOf((*MockLimitRepository)(nil).GetLimitByCustomerID), customerID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimitByCustomerID", reflect.TypeOf((*MockLimitRepository)(nil).GetLimitByCustomerID), ctx, customerID)
+}
+
+// GetLimitByCustomerIDForUpdate mocks base method.
+func (m *MockLimitRepository) GetLimitByCustomerIDForUpdate(ctx context.Context, customerID int) (*model.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimitByCustomerIDForUpdate", ctx, customerID)
+	ret0, _ := ret[0].(*model.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimitByCustomerIDForUpdate indicates an expected call of GetLimitByCustomerIDForUpdate.
+func (mr *MockLimitRepositoryMockRecorder) GetLimitByCustomerIDForUpdate(ctx, customerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimitByCustomerIDForUpdate", reflect.TypeOf((*MockLimitRepository)(nil).GetLimitByCustomerIDForUpdate), ctx, customerID)
 }
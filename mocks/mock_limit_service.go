@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service/limit.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	service "alif-sigmatech/service"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLimitService is a mock of LimitService interface.
+type MockLimitService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLimitServiceMockRecorder
+}
+
+// MockLimitServiceMockRecorder is the mock recorder for MockLimitService.
+type MockLimitServiceMockRecorder struct {
+	mock *MockLimitService
+}
+
+// NewMockLimitService creates a new mock instance.
+func NewMockLimitService(ctrl *gomock.Controller) *MockLimitService {
+	mock := &MockLimitService{ctrl: ctrl}
+	mock.recorder = &MockLimitServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLimitService) EXPECT() *MockLimitServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateLimit mocks base method.
+func (m *MockLimitService) CreateLimit(ctx context.Context, input service.CreateLimitInput) (*model.Limit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLimit", ctx, input)
+	ret0, _ := ret[0].(*model.Limit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLimit indicates an expected call of CreateLimit.
+func (mr *MockLimitServiceMockRecorder) CreateLimit(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimit", reflect.TypeOf((*MockLimitService)(nil).CreateLimit), ctx, input)
+}
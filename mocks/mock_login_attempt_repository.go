@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/login_attempt.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLoginAttemptRepository is a mock of LoginAttemptRepository interface.
+type MockLoginAttemptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepositoryMockRecorder
+}
+
+// MockLoginAttemptRepositoryMockRecorder is the mock recorder for MockLoginAttemptRepository.
+type MockLoginAttemptRepositoryMockRecorder struct {
+	mock *MockLoginAttemptRepository
+}
+
+// NewMockLoginAttemptRepository creates a new mock instance.
+func NewMockLoginAttemptRepository(ctrl *gomock.Controller) *MockLoginAttemptRepository {
+	mock := &MockLoginAttemptRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttemptRepository) EXPECT() *MockLoginAttemptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteLoginAttempt mocks base method.
+func (m *MockLoginAttemptRepository) DeleteLoginAttempt(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttempt", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginAttempt indicates an expected call of DeleteLoginAttempt.
+func (mr *MockLoginAttemptRepositoryMockRecorder) DeleteLoginAttempt(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempt", reflect.TypeOf((*MockLoginAttemptRepository)(nil).DeleteLoginAttempt), ctx, key)
+}
+
+// GetLoginAttempt mocks base method.
+func (m *MockLoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempt", ctx, key)
+	ret0, _ := ret[0].(*model.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempt indicates an expected call of GetLoginAttempt.
+func (mr *MockLoginAttemptRepositoryMockRecorder) GetLoginAttempt(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempt", reflect.TypeOf((*MockLoginAttemptRepository)(nil).GetLoginAttempt), ctx, key)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at, resetBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, key, at, resetBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockLoginAttemptRepositoryMockRecorder) RecordLoginFailure(ctx, key, at, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockLoginAttemptRepository)(nil).RecordLoginFailure), ctx, key, at, resetBefore)
+}
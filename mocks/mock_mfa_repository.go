@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/mfa.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMFARepository is a mock of MFARepository interface.
+type MockMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFARepositoryMockRecorder
+}
+
+// MockMFARepositoryMockRecorder is the mock recorder for MockMFARepository.
+type MockMFARepositoryMockRecorder struct {
+	mock *MockMFARepository
+}
+
+// NewMockMFARepository creates a new mock instance.
+func NewMockMFARepository(ctrl *gomock.Controller) *MockMFARepository {
+	mock := &MockMFARepository{ctrl: ctrl}
+	mock.recorder = &MockMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFARepository) EXPECT() *MockMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// AcceptTOTPStep mocks base method.
+func (m *MockMFARepository) AcceptTOTPStep(ctx context.Context, customerID int, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptTOTPStep", ctx, customerID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptTOTPStep indicates an expected call of AcceptTOTPStep.
+func (mr *MockMFARepositoryMockRecorder) AcceptTOTPStep(ctx, customerID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptTOTPStep", reflect.TypeOf((*MockMFARepository)(nil).AcceptTOTPStep), ctx, customerID, step)
+}
+
+// ConsumeRecoveryCode mocks base method.
+func (m *MockMFARepository) ConsumeRecoveryCode(ctx context.Context, customerID int, codeHash string, usedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeRecoveryCode", ctx, customerID, codeHash, usedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeRecoveryCode indicates an expected call of ConsumeRecoveryCode.
+func (mr *MockMFARepositoryMockRecorder) ConsumeRecoveryCode(ctx, customerID, codeHash, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeRecoveryCode", reflect.TypeOf((*MockMFARepository)(nil).ConsumeRecoveryCode), ctx, customerID, codeHash, usedAt)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, customerID int, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, customerID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockMFARepositoryMockRecorder) ReplaceRecoveryCodes(ctx, customerID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockMFARepository)(nil).ReplaceRecoveryCodes), ctx, customerID, codeHashes)
+}
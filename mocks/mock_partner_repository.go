@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/partner.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPartnerRepository is a mock of PartnerRepository interface.
+type MockPartnerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPartnerRepositoryMockRecorder
+}
+
+// MockPartnerRepositoryMockRecorder is the mock recorder for MockPartnerRepository.
+type MockPartnerRepositoryMockRecorder struct {
+	mock *MockPartnerRepository
+}
+
+// NewMockPartnerRepository creates a new mock instance.
+func NewMockPartnerRepository(ctrl *gomock.Controller) *MockPartnerRepository {
+	mock := &MockPartnerRepository{ctrl: ctrl}
+	mock.recorder = &MockPartnerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPartnerRepository) EXPECT() *MockPartnerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePartner mocks base method.
+func (m *MockPartnerRepository) CreatePartner(ctx context.Context, partner *model.Partner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePartner", ctx, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePartner indicates an expected call of CreatePartner.
+func (mr *MockPartnerRepositoryMockRecorder) CreatePartner(ctx, partner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePartner", reflect.TypeOf((*MockPartnerRepository)(nil).CreatePartner), ctx, partner)
+}
+
+// GetConsent mocks base method.
+func (m *MockPartnerRepository) GetConsent(ctx context.Context, customerID, partnerID int) (*model.PartnerConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsent", ctx, customerID, partnerID)
+	ret0, _ := ret[0].(*model.PartnerConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsent indicates an expected call of GetConsent.
+func (mr *MockPartnerRepositoryMockRecorder) GetConsent(ctx, customerID, partnerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsent", reflect.TypeOf((*MockPartnerRepository)(nil).GetConsent), ctx, customerID, partnerID)
+}
+
+// GetPartnerByAPIKeyHash mocks base method.
+func (m *MockPartnerRepository) GetPartnerByAPIKeyHash(ctx context.Context, apiKeyHash string) (*model.Partner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPartnerByAPIKeyHash", ctx, apiKeyHash)
+	ret0, _ := ret[0].(*model.Partner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPartnerByAPIKeyHash indicates an expected call of GetPartnerByAPIKeyHash.
+func (mr *MockPartnerRepositoryMockRecorder) GetPartnerByAPIKeyHash(ctx, apiKeyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartnerByAPIKeyHash", reflect.TypeOf((*MockPartnerRepository)(nil).GetPartnerByAPIKeyHash), ctx, apiKeyHash)
+}
+
+// GetPartnerByID mocks base method.
+func (m *MockPartnerRepository) GetPartnerByID(ctx context.Context, id int) (*model.Partner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPartnerByID", ctx, id)
+	ret0, _ := ret[0].(*model.Partner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPartnerByID indicates an expected call of GetPartnerByID.
+func (mr *MockPartnerRepositoryMockRecorder) GetPartnerByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartnerByID", reflect.TypeOf((*MockPartnerRepository)(nil).GetPartnerByID), ctx, id)
+}
+
+// GrantConsent mocks base method.
+func (m *MockPartnerRepository) GrantConsent(ctx context.Context, consent *model.PartnerConsent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantConsent", ctx, consent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantConsent indicates an expected call of GrantConsent.
+func (mr *MockPartnerRepositoryMockRecorder) GrantConsent(ctx, consent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantConsent", reflect.TypeOf((*MockPartnerRepository)(nil).GrantConsent), ctx, consent)
+}
+
+// RecordNonce mocks base method.
+func (m *MockPartnerRepository) RecordNonce(ctx context.Context, partnerID int, nonce string, now, expiresAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordNonce", ctx, partnerID, nonce, now, expiresAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordNonce indicates an expected call of RecordNonce.
+func (mr *MockPartnerRepositoryMockRecorder) RecordNonce(ctx, partnerID, nonce, now, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordNonce", reflect.TypeOf((*MockPartnerRepository)(nil).RecordNonce), ctx, partnerID, nonce, now, expiresAt)
+}
+
+// RevokeConsent mocks base method.
+func (m *MockPartnerRepository) RevokeConsent(ctx context.Context, customerID, partnerID int, revokedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeConsent", ctx, customerID, partnerID, revokedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeConsent indicates an expected call of RevokeConsent.
+func (mr *MockPartnerRepositoryMockRecorder) RevokeConsent(ctx, customerID, partnerID, revokedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeConsent", reflect.TypeOf((*MockPartnerRepository)(nil).RevokeConsent), ctx, customerID, partnerID, revokedAt)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/password_reset.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordResetRepository is a mock of PasswordResetRepository interface.
+type MockPasswordResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetRepositoryMockRecorder
+}
+
+// MockPasswordResetRepositoryMockRecorder is the mock recorder for MockPasswordResetRepository.
+type MockPasswordResetRepositoryMockRecorder struct {
+	mock *MockPasswordResetRepository
+}
+
+// NewMockPasswordResetRepository creates a new mock instance.
+func NewMockPasswordResetRepository(ctrl *gomock.Controller) *MockPasswordResetRepository {
+	mock := &MockPasswordResetRepository{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetRepository) EXPECT() *MockPasswordResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumePasswordResetToken mocks base method.
+func (m *MockPasswordResetRepository) ConsumePasswordResetToken(ctx context.Context, id int, usedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumePasswordResetToken", ctx, id, usedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumePasswordResetToken indicates an expected call of ConsumePasswordResetToken.
+func (mr *MockPasswordResetRepositoryMockRecorder) ConsumePasswordResetToken(ctx, id, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePasswordResetToken", reflect.TypeOf((*MockPasswordResetRepository)(nil).ConsumePasswordResetToken), ctx, id, usedAt)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockPasswordResetRepositoryMockRecorder) CreatePasswordResetToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockPasswordResetRepository)(nil).CreatePasswordResetToken), ctx, token)
+}
+
+// GetPasswordResetTokenByHash mocks base method.
+func (m *MockPasswordResetRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetTokenByHash indicates an expected call of GetPasswordResetTokenByHash.
+func (mr *MockPasswordResetRepositoryMockRecorder) GetPasswordResetTokenByHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetTokenByHash", reflect.TypeOf((*MockPasswordResetRepository)(nil).GetPasswordResetTokenByHash), ctx, tokenHash)
+}
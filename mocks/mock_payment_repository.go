@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/payment.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPaymentRepository is a mock of PaymentRepository interface.
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository.
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance.
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePayment mocks base method.
+func (m *MockPaymentRepository) CreatePayment(ctx context.Context, payment *model.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePayment", ctx, payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePayment indicates an expected call of CreatePayment.
+func (mr *MockPaymentRepositoryMockRecorder) CreatePayment(ctx, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockPaymentRepository)(nil).CreatePayment), ctx, payment)
+}
+
+// ListPaymentsByCustomer mocks base method.
+func (m *MockPaymentRepository) ListPaymentsByCustomer(ctx context.Context, customerID int, before time.Time) ([]model.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentsByCustomer", ctx, customerID, before)
+	ret0, _ := ret[0].([]model.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentsByCustomer indicates an expected call of ListPaymentsByCustomer.
+func (mr *MockPaymentRepositoryMockRecorder) ListPaymentsByCustomer(ctx, customerID, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentsByCustomer", reflect.TypeOf((*MockPaymentRepository)(nil).ListPaymentsByCustomer), ctx, customerID, before)
+}
+
+// ListPaymentsByTransaction mocks base method.
+func (m *MockPaymentRepository) ListPaymentsByTransaction(ctx context.Context, transactionID int) ([]model.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPaymentsByTransaction", ctx, transactionID)
+	ret0, _ := ret[0].([]model.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPaymentsByTransaction indicates an expected call of ListPaymentsByTransaction.
+func (mr *MockPaymentRepositoryMockRecorder) ListPaymentsByTransaction(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPaymentsByTransaction", reflect.TypeOf((*MockPaymentRepository)(nil).ListPaymentsByTransaction), ctx, transactionID)
+}
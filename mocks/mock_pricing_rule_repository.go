@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/pricing.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPricingRuleRepository is a mock of PricingRuleRepository interface.
+type MockPricingRuleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPricingRuleRepositoryMockRecorder
+}
+
+// MockPricingRuleRepositoryMockRecorder is the mock recorder for MockPricingRuleRepository.
+type MockPricingRuleRepositoryMockRecorder struct {
+	mock *MockPricingRuleRepository
+}
+
+// NewMockPricingRuleRepository creates a new mock instance.
+func NewMockPricingRuleRepository(ctrl *gomock.Controller) *MockPricingRuleRepository {
+	mock := &MockPricingRuleRepository{ctrl: ctrl}
+	mock.recorder = &MockPricingRuleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPricingRuleRepository) EXPECT() *MockPricingRuleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePricingRule mocks base method.
+func (m *MockPricingRuleRepository) CreatePricingRule(ctx context.Context, rule *model.PricingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePricingRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePricingRule indicates an expected call of CreatePricingRule.
+func (mr *MockPricingRuleRepositoryMockRecorder) CreatePricingRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePricingRule", reflect.TypeOf((*MockPricingRuleRepository)(nil).CreatePricingRule), ctx, rule)
+}
+
+// ListEffectivePricingRules mocks base method.
+func (m *MockPricingRuleRepository) ListEffectivePricingRules(ctx context.Context, at time.Time) ([]model.PricingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEffectivePricingRules", ctx, at)
+	ret0, _ := ret[0].([]model.PricingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEffectivePricingRules indicates an expected call of ListEffectivePricingRules.
+func (mr *MockPricingRuleRepositoryMockRecorder) ListEffectivePricingRules(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEffectivePricingRules", reflect.TypeOf((*MockPricingRuleRepository)(nil).ListEffectivePricingRules), ctx, at)
+}
+
+// ListPricingRules mocks base method.
+func (m *MockPricingRuleRepository) ListPricingRules(ctx context.Context, code string) ([]model.PricingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPricingRules", ctx, code)
+	ret0, _ := ret[0].([]model.PricingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPricingRules indicates an expected call of ListPricingRules.
+func (mr *MockPricingRuleRepositoryMockRecorder) ListPricingRules(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPricingRules", reflect.TypeOf((*MockPricingRuleRepository)(nil).ListPricingRules), ctx, code)
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository/promotion.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPromotionRepository is a mock of PromotionRepository interface.
+type MockPromotionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionRepositoryMockRecorder
+}
+
+// MockPromotionRepositoryMockRecorder is the mock recorder for MockPromotionRepository.
+type MockPromotionRepositoryMockRecorder struct {
+	mock *MockPromotionRepository
+}
+
+// NewMockPromotionRepository creates a new mock instance.
+func NewMockPromotionRepository(ctrl *gomock.Controller) *MockPromotionRepository {
+	mock := &MockPromotionRepository{ctrl: ctrl}
+	mock.recorder = &MockPromotionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotionRepository) EXPECT() *MockPromotionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateCampaign mocks base method.
+func (m *MockPromotionRepository) CreateCampaign(ctx context.Context, campaign *model.Campaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, campaign)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockPromotionRepositoryMockRecorder) CreateCampaign(ctx, campaign interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockPromotionRepository)(nil).CreateCampaign), ctx, campaign)
+}
+
+// DeactivateCampaign mocks base method.
+func (m *MockPromotionRepository) DeactivateCampaign(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateCampaign", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateCampaign indicates an expected call of DeactivateCampaign.
+func (mr *MockPromotionRepositoryMockRecorder) DeactivateCampaign(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateCampaign", reflect.TypeOf((*MockPromotionRepository)(nil).DeactivateCampaign), ctx, id)
+}
+
+// GetCampaignByCode mocks base method.
+func (m *MockPromotionRepository) GetCampaignByCode(ctx context.Context, code string) (*model.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaignByCode", ctx, code)
+	ret0, _ := ret[0].(*model.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaignByCode indicates an expected call of GetCampaignByCode.
+func (mr *MockPromotionRepositoryMockRecorder) GetCampaignByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaignByCode", reflect.TypeOf((*MockPromotionRepository)(nil).GetCampaignByCode), ctx, code)
+}
+
+// HasRedeemed mocks base method.
+func (m *MockPromotionRepository) HasRedeemed(ctx context.Context, campaignID, customerID int, at time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasRedeemed", ctx, campaignID, customerID, at)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasRedeemed indicates an expected call of HasRedeemed.
+func (mr *MockPromotionRepositoryMockRecorder) HasRedeemed(ctx, campaignID, customerID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasRedeemed", reflect.TypeOf((*MockPromotionRepository)(nil).HasRedeemed), ctx, campaignID, customerID, at)
+}
+
+// ListCampaigns mocks base method.
+func (m *MockPromotionRepository) ListCampaigns(ctx context.Context) ([]model.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCampaigns", ctx)
+	ret0, _ := ret[0].([]model.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCampaigns indicates an expected call of ListCampaigns.
+func (mr *MockPromotionRepositoryMockRecorder) ListCampaigns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCampaigns", reflect.TypeOf((*MockPromotionRepository)(nil).ListCampaigns), ctx)
+}
+
+// RedeemVoucher mocks base method.
+func (m *MockPromotionRepository) RedeemVoucher(ctx context.Context, redemption *model.PromoRedemption) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemVoucher", ctx, redemption)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedeemVoucher indicates an expected call of RedeemVoucher.
+func (mr *MockPromotionRepositoryMockRecorder) RedeemVoucher(ctx, redemption interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemVoucher", reflect.TypeOf((*MockPromotionRepository)(nil).RedeemVoucher), ctx, redemption)
+}
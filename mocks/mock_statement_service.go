@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service/statement.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	statement "alif-sigmatech/statement"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStatementService is a mock of StatementService interface.
+type MockStatementService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatementServiceMockRecorder
+}
+
+// MockStatementServiceMockRecorder is the mock recorder for MockStatementService.
+type MockStatementServiceMockRecorder struct {
+	mock *MockStatementService
+}
+
+// NewMockStatementService creates a new mock instance.
+func NewMockStatementService(ctrl *gomock.Controller) *MockStatementService {
+	mock := &MockStatementService{ctrl: ctrl}
+	mock.recorder = &MockStatementServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementService) EXPECT() *MockStatementServiceMockRecorder {
+	return m.recorder
+}
+
+// ContractStatement mocks base method.
+func (m *MockStatementService) ContractStatement(ctx context.Context, customerID int, contractNumber string) (*statement.Contract, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContractStatement", ctx, customerID, contractNumber)
+	ret0, _ := ret[0].(*statement.Contract)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContractStatement indicates an expected call of ContractStatement.
+func (mr *MockStatementServiceMockRecorder) ContractStatement(ctx, customerID, contractNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractStatement", reflect.TypeOf((*MockStatementService)(nil).ContractStatement), ctx, customerID, contractNumber)
+}
+
+// MonthlyStatement mocks base method.
+func (m *MockStatementService) MonthlyStatement(ctx context.Context, customerID int, period time.Time) (*statement.Monthly, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MonthlyStatement", ctx, customerID, period)
+	ret0, _ := ret[0].(*statement.Monthly)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MonthlyStatement indicates an expected call of MonthlyStatement.
+func (mr *MockStatementServiceMockRecorder) MonthlyStatement(ctx, customerID, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MonthlyStatement", reflect.TypeOf((*MockStatementService)(nil).MonthlyStatement), ctx, customerID, period)
+}
+
+// RecordPayment mocks base method.
+func (m *MockStatementService) RecordPayment(ctx context.Context, recordedBy, transactionID int, payment model.Payment) (*model.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPayment", ctx, recordedBy, transactionID, payment)
+	ret0, _ := ret[0].(*model.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPayment indicates an expected call of RecordPayment.
+func (mr *MockStatementServiceMockRecorder) RecordPayment(ctx, recordedBy, transactionID, payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPayment", reflect.TypeOf((*MockStatementService)(nil).RecordPayment), ctx, recordedBy, transactionID, payment)
+}
@@ -6,7 +6,9 @@ package mocks
 
 import (
 	model "alif-sigmatech/model"
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,16 +36,121 @@ func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecor
 	return m.recorder
 }
 
+// CancelTransaction mocks base method.
+func (m *MockTransactionRepository) CancelTransaction(ctx context.Context, id int, cancellableStatuses []string, cancelledAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTransaction", ctx, id, cancellableStatuses, cancelledAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelTransaction indicates an expected call of CancelTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) CancelTransaction(ctx, id, cancellableStatuses, cancelledAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).CancelTransaction), ctx, id, cancellableStatuses, cancelledAt)
+}
+
+// ConfirmTransaction mocks base method.
+func (m *MockTransactionRepository) ConfirmTransaction(ctx context.Context, id int, confirmedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTransaction", ctx, id, confirmedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTransaction indicates an expected call of ConfirmTransaction.
+func (mr *MockTransactionRepositoryMockRecorder) ConfirmTransaction(ctx, id, confirmedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).ConfirmTransaction), ctx, id, confirmedAt)
+}
+
 // CreateTransaction mocks base method.
-func (m *MockTransactionRepository) CreateTransaction(transaction *model.Transaction) error {
+func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, transaction *model.Transaction) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransaction", transaction)
+	ret := m.ctrl.Call(m, "CreateTransaction", ctx, transaction)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateTransaction indicates an expected call of CreateTransaction.
-func (mr *MockTransactionRepositoryMockRecorder) CreateTransaction(transaction interface{}) *gomock.Call {
+func (mr *MockTransactionRepositoryMockRecorder) CreateTransaction(ctx, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).CreateTransaction), ctx, transaction)
+}
+
+// GetTransactionByContractNumber mocks base method.
+func (m *MockTransactionRepository) GetTransactionByContractNumber(ctx context.Context, customerID int, contractNumber string) (*model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionByContractNumber", ctx, customerID, contractNumber)
+	ret0, _ := ret[0].(*model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionByContractNumber indicates an expected call of GetTransactionByContractNumber.
+func (mr *MockTransactionRepositoryMockRecorder) GetTransactionByContractNumber(ctx, customerID, contractNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionByContractNumber", reflect.TypeOf((*MockTransactionRepository)(nil).GetTransactionByContractNumber), ctx, customerID, contractNumber)
+}
+
+// GetTransactionByID mocks base method.
+func (m *MockTransactionRepository) GetTransactionByID(ctx context.Context, id int) (*model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionByID", ctx, id)
+	ret0, _ := ret[0].(*model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionByID indicates an expected call of GetTransactionByID.
+func (mr *MockTransactionRepositoryMockRecorder) GetTransactionByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionByID", reflect.TypeOf((*MockTransactionRepository)(nil).GetTransactionByID), ctx, id)
+}
+
+// GetTransactionByIDForUpdate mocks base method.
+func (m *MockTransactionRepository) GetTransactionByIDForUpdate(ctx context.Context, id int) (*model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(*model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionByIDForUpdate indicates an expected call of GetTransactionByIDForUpdate.
+func (mr *MockTransactionRepositoryMockRecorder) GetTransactionByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionByIDForUpdate", reflect.TypeOf((*MockTransactionRepository)(nil).GetTransactionByIDForUpdate), ctx, id)
+}
+
+// ListTransactions mocks base method.
+func (m *MockTransactionRepository) ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, filter)
+	ret0, _ := ret[0].([]model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockTransactionRepositoryMockRecorder) ListTransactions(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockTransactionRepository)(nil).ListTransactions), ctx, filter)
+}
+
+// ReserveOTPAttempt mocks base method.
+func (m *MockTransactionRepository) ReserveOTPAttempt(ctx context.Context, id, maxAttempts int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveOTPAttempt", ctx, id, maxAttempts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveOTPAttempt indicates an expected call of ReserveOTPAttempt.
+func (mr *MockTransactionRepositoryMockRecorder) ReserveOTPAttempt(ctx, id, maxAttempts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).CreateTransaction), transaction)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveOTPAttempt", reflect.TypeOf((*MockTransactionRepository)(nil).ReserveOTPAttempt), ctx, id, maxAttempts)
 }
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service/transaction.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	model "alif-sigmatech/model"
+	service "alif-sigmatech/service"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTransactionService is a mock of TransactionService interface.
+type MockTransactionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionServiceMockRecorder
+}
+
+// MockTransactionServiceMockRecorder is the mock recorder for MockTransactionService.
+type MockTransactionServiceMockRecorder struct {
+	mock *MockTransactionService
+}
+
+// NewMockTransactionService creates a new mock instance.
+func NewMockTransactionService(ctrl *gomock.Controller) *MockTransactionService {
+	mock := &MockTransactionService{ctrl: ctrl}
+	mock.recorder = &MockTransactionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionService) EXPECT() *MockTransactionServiceMockRecorder {
+	return m.recorder
+}
+
+// BookTransaction mocks base method.
+func (m *MockTransactionService) BookTransaction(ctx context.Context, actor service.Actor, input service.BookTransactionInput) (*model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookTransaction", ctx, actor, input)
+	ret0, _ := ret[0].(*model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookTransaction indicates an expected call of BookTransaction.
+func (mr *MockTransactionServiceMockRecorder) BookTransaction(ctx, actor, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookTransaction", reflect.TypeOf((*MockTransactionService)(nil).BookTransaction), ctx, actor, input)
+}
+
+// CancelTransaction mocks base method.
+func (m *MockTransactionService) CancelTransaction(ctx context.Context, actor service.Actor, id int) (*model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTransaction", ctx, actor, id)
+	ret0, _ := ret[0].(*model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelTransaction indicates an expected call of CancelTransaction.
+func (mr *MockTransactionServiceMockRecorder) CancelTransaction(ctx, actor, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTransaction", reflect.TypeOf((*MockTransactionService)(nil).CancelTransaction), ctx, actor, id)
+}
+
+// ConfirmTransaction mocks base method.
+func (m *MockTransactionService) ConfirmTransaction(ctx context.Context, actor service.Actor, id int, code string) (*model.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTransaction", ctx, actor, id, code)
+	ret0, _ := ret[0].(*model.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTransaction indicates an expected call of ConfirmTransaction.
+func (mr *MockTransactionServiceMockRecorder) ConfirmTransaction(ctx, actor, id, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTransaction", reflect.TypeOf((*MockTransactionService)(nil).ConfirmTransaction), ctx, actor, id, code)
+}
+
+// ListTransactions mocks base method.
+func (m *MockTransactionService) ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, filter)
+	ret0, _ := ret[0].([]model.Transaction)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockTransactionServiceMockRecorder) ListTransactions(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockTransactionService)(nil).ListTransactions), ctx, filter)
+}
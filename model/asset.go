@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Asset is a catalogue entry a transaction can finance
+type Asset struct {
+	ID       int    `json:"id"`
+	Category string `json:"category"`
+	Brand    string `json:"brand"`
+	Model    string `json:"model"`
+	// MinOTR and MaxOTR are the reference on-the-road price range of the asset
+	MinOTR float64 `json:"min_otr"`
+	MaxOTR float64 `json:"max_otr"`
+	// MaxFinancePercent is the share of the OTR that may be financed, the rest is the minimum down payment
+	MaxFinancePercent float64   `json:"max_finance_percent"`
+	AllowedTenors     []int     `json:"allowed_tenors"`
+	Active            bool      `json:"active"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Name is the asset name recorded on transactions
+func (a *Asset) Name() string {
+	return a.Brand + " " + a.Model
+}
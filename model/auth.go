@@ -1,6 +1,27 @@
 package model
 
-import "github.com/golang-jwt/jwt"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Purposes a signed token can be issued for
+const (
+	// TokenPurposeAccess grants access to the API
+	TokenPurposeAccess = "access"
+	// TokenPurposeMFAChallenge proves the password was correct and may only be exchanged for an access token with a TOTP code
+	TokenPurposeMFAChallenge = "mfa_challenge"
+	// TokenPurposeMFAEnrollment may only be used to enrol in MFA when the account's role requires it
+	TokenPurposeMFAEnrollment = "mfa_enrollment"
+)
+
+// Roles a customer account can hold
+const (
+	RoleCustomer = "customer"
+	RoleOfficer  = "officer"
+	RoleAdmin    = "admin"
+)
 
 type AuthLogin struct {
 	NIK      string `json:"nik"`
@@ -8,7 +29,75 @@ type AuthLogin struct {
 }
 
 type Claims struct {
-	NIK      string `json:"nik"`
-	FullName string `json:"full_name"`
+	CustomerID int    `json:"customer_id"`
+	NIK        string `json:"nik"`
+	FullName   string `json:"full_name"`
+	Role       string `json:"role"`
+	// TokenVersion must match the customer's current token version, which is
+	// bumped to revoke every session issued before it
+	TokenVersion int    `json:"token_version"`
+	Purpose      string `json:"purpose"`
 	jwt.StandardClaims
 }
+
+// LoginResponse either carries an access token or asks the client to complete MFA with MFAToken
+type LoginResponse struct {
+	Token                 string `json:"token,omitempty"`
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+}
+
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAVerifyRequest struct {
+	Code string `json:"code"`
+}
+
+// MFAVerifyResponse returns the recovery codes once; Token is set when enrolment completed a login
+type MFAVerifyResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token,omitempty"`
+}
+
+// MFALoginRequest completes a login with either a TOTP code or a recovery code
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type ForgotPasswordRequest struct {
+	NIK string `json:"nik"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// PasswordResetToken is a single-use token allowing a customer to set a new password.
+// Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID         int
+	CustomerID int
+	TokenHash  string
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	CreatedAt  time.Time
+}
+
+// LoginAttempt counts consecutive failed logins for a NIK or a client IP
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ContractDocument is the agreement rendered for a transaction. The customer accepts it before the
+// transaction can be confirmed; the acceptance records the hash of the exact document shown.
+type ContractDocument struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	CustomerID    int    `json:"customer_id"`
+	Template      string `json:"template"`
+	// ContentHash is the hex SHA-256 hash of the rendered PDF
+	ContentHash string     `json:"content_hash"`
+	StorageKey  string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	// AcceptedHash is the hash the customer accepted, as sent by their device
+	AcceptedHash      string `json:"accepted_hash,omitempty"`
+	AcceptedIP        string `json:"accepted_ip,omitempty"`
+	AcceptedUserAgent string `json:"accepted_user_agent,omitempty"`
+}
+
+type AcceptContractRequest struct {
+	ContentHash string `json:"content_hash"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type Customer struct {
 	ID          int     `json:"id"`
 	NIK         string  `json:"nik"`
@@ -9,6 +11,64 @@ type Customer struct {
 	BirthPlace  string  `json:"birth_place"`
 	BirthDate   string  `json:"birth_date"`
 	Salary      float64 `json:"salary"`
-	KTPPhoto    []byte  `json:"ktp_photo"`
-	SelfiePhoto []byte  `json:"selfie_photo"`
+	Address     string  `json:"address"`
+	PhoneNumber string  `json:"phone_number"`
+	// Language is the language the customer chose for messages, empty to follow the device
+	Language string `json:"language"`
+	Role     string `json:"role"`
+	// RiskGrade is assigned by credit scoring and selects the pricing rules applying to the customer
+	RiskGrade    string `json:"-"`
+	TokenVersion int    `json:"-"`
+	MFAEnabled   bool   `json:"-"`
+	MFASecret    []byte `json:"-"`
+}
+
+// CustomerProfile is the view of a customer returned to the customer themselves
+type CustomerProfile struct {
+	ID          int     `json:"id"`
+	NIK         string  `json:"nik"`
+	FullName    string  `json:"full_name"`
+	LegalName   string  `json:"legal_name"`
+	BirthPlace  string  `json:"birth_place"`
+	BirthDate   string  `json:"birth_date"`
+	Salary      float64 `json:"salary"`
+	Address     string  `json:"address"`
+	PhoneNumber string  `json:"phone_number"`
+	Language    string  `json:"language"`
+}
+
+// UpdateProfileRequest holds the fields a customer may change without officer approval.
+// Nil fields are left untouched.
+type UpdateProfileRequest struct {
+	Salary      *float64 `json:"salary"`
+	Address     *string  `json:"address"`
+	PhoneNumber *string  `json:"phone_number"`
+	Language    *string  `json:"language"`
+}
+
+// Statuses of a correction request
+const (
+	CorrectionPending  = "pending"
+	CorrectionApproved = "approved"
+	CorrectionRejected = "rejected"
+)
+
+// CorrectionRequest asks an officer to change an identity field of a customer
+type CorrectionRequest struct {
+	ID             int        `json:"id"`
+	CustomerID     int        `json:"customer_id"`
+	FieldName      string     `json:"field_name"`
+	RequestedValue string     `json:"requested_value"`
+	Reason         string     `json:"reason"`
+	Status         string     `json:"status"`
+	ReviewedBy     *int       `json:"reviewed_by,omitempty"`
+	ReviewNote     string     `json:"review_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// ReviewCorrectionRequest is an officer's decision on a correction request
+type ReviewCorrectionRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
 }
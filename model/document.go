@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Identity documents a customer submits during onboarding
+const (
+	DocumentKTP    = "ktp"
+	DocumentSelfie = "selfie"
+)
+
+// DocumentAccessLog records an officer viewing a customer's identity document
+type DocumentAccessLog struct {
+	ID           int       `json:"id"`
+	CustomerID   int       `json:"customer_id"`
+	DocumentType string    `json:"document_type"`
+	OfficerID    int       `json:"officer_id"`
+	Reason       string    `json:"reason"`
+	IPAddress    string    `json:"ip_address"`
+	AccessedAt   time.Time `json:"accessed_at"`
+}
+
+// CustomerDocument is the metadata of an identity document kept in the blob store
+type CustomerDocument struct {
+	ID           int       `json:"id"`
+	CustomerID   int       `json:"customer_id"`
+	DocumentType string    `json:"document_type"`
+	StorageKey   string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"content_type"`
+	Size         int       `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"created_at"`
+}
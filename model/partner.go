@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Channels a transaction can originate from
+const (
+	// ChannelApp is a transaction booked by the customer in our own app
+	ChannelApp       = "app"
+	ChannelDealer    = "dealer"
+	ChannelECommerce = "ecommerce"
+)
+
+// Partner is a dealer or e-commerce merchant booking transactions on behalf of customers
+type Partner struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Channel string `json:"channel"`
+	// APIKeyPrefix identifies the API key in listings without revealing it
+	APIKeyPrefix string `json:"api_key_prefix"`
+	APIKeyHash   string `json:"-"`
+	// SigningSecret is the encrypted secret the partner signs requests with
+	SigningSecret []byte    `json:"-"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PartnerCredentials are handed out once when a partner is created
+type PartnerCredentials struct {
+	Partner       *Partner `json:"partner"`
+	APIKey        string   `json:"api_key"`
+	SigningSecret string   `json:"signing_secret"`
+}
+
+// PartnerConsent records a customer allowing a partner to book transactions on their behalf
+type PartnerConsent struct {
+	CustomerID int        `json:"customer_id"`
+	PartnerID  int        `json:"partner_id"`
+	GrantedAt  time.Time  `json:"granted_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreatePartnerRequest struct {
+	Name    string `json:"name"`
+	Channel string `json:"channel"`
+}
+
+type GrantConsentRequest struct {
+	PartnerID int `json:"partner_id"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Payment is an installment payment received for a booked transaction
+type Payment struct {
+	ID            int     `json:"id"`
+	TransactionID int     `json:"transaction_id"`
+	Amount        float64 `json:"amount"`
+	// Reference identifies the payment at the bank or payment channel
+	Reference  string    `json:"reference"`
+	PaidAt     time.Time `json:"paid_at"`
+	RecordedBy int       `json:"recorded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// PricingRule prices transactions matching its criteria during its effective period.
+// Rules are never edited: a change creates a new version of the same code, and
+// transactions record the rule version that priced them.
+type PricingRule struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	// Criteria; zero values match every transaction
+	Tenor         int    `json:"tenor,omitempty"`
+	AssetCategory string `json:"asset_category,omitempty"`
+	PartnerID     *int   `json:"partner_id,omitempty"`
+	RiskGrade     string `json:"risk_grade,omitempty"`
+	PromoCode     string `json:"promo_code,omitempty"`
+	// Priority breaks ties between matching rules with the same number of criteria
+	Priority int `json:"priority"`
+	// AdminFee is a flat fee added to AdminFeePercent of the financed amount
+	AdminFee        float64 `json:"admin_fee"`
+	AdminFeePercent float64 `json:"admin_fee_percent"`
+	// MonthlyInterestPercent is the flat interest charged on the financed amount per month of tenor
+	MonthlyInterestPercent float64    `json:"monthly_interest_percent"`
+	EffectiveFrom          time.Time  `json:"effective_from"`
+	EffectiveTo            *time.Time `json:"effective_to,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Campaign is a marketing promotion redeemed with its voucher code. The discounts are
+// taken off the admin fee and interest computed by the pricing rules.
+type Campaign struct {
+	ID int `json:"id"`
+	// Code is the voucher code customers enter
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// AdminFeeDiscountPercent of 100 waives the admin fee
+	AdminFeeDiscountPercent float64 `json:"admin_fee_discount_percent"`
+	InterestDiscountPercent float64 `json:"interest_discount_percent"`
+	// Eligibility; zero values match every transaction
+	Tenor             int        `json:"tenor,omitempty"`
+	AssetCategory     string     `json:"asset_category,omitempty"`
+	Channel           string     `json:"channel,omitempty"`
+	MinFinancedAmount float64    `json:"min_financed_amount,omitempty"`
+	StartsAt          time.Time  `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at,omitempty"`
+	// Budget caps the total discount granted and MaxRedemptions the number of vouchers
+	// redeemed; zero means unlimited
+	Budget         float64   `json:"budget"`
+	BudgetUsed     float64   `json:"budget_used"`
+	MaxRedemptions int       `json:"max_redemptions"`
+	Redemptions    int       `json:"redemptions"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PromoRedemption records a voucher redeemed for a transaction. It is reversed when the
+// transaction is cancelled, which gives the customer the voucher and the campaign its budget back.
+type PromoRedemption struct {
+	ID             int        `json:"id"`
+	CampaignID     int        `json:"campaign_id"`
+	CustomerID     int        `json:"customer_id"`
+	TransactionID  int        `json:"transaction_id"`
+	DiscountAmount float64    `json:"discount_amount"`
+	RedeemedAt     time.Time  `json:"redeemed_at"`
+	ReversedAt     *time.Time `json:"reversed_at,omitempty"`
+}
@@ -1,5 +1,14 @@
 package model
 
+import "time"
+
+// Statuses of a transaction. A contract is only booked once the customer confirmed it with a one-time code.
+const (
+	TransactionPending   = "pending"
+	TransactionConfirmed = "confirmed"
+	TransactionCancelled = "cancelled"
+)
+
 type Transaction struct {
 	ID                int     `json:"id"`
 	CustomerID        int     `json:"customer_id"`
@@ -8,6 +17,68 @@ type Transaction struct {
 	AdminFee          float64 `json:"admin_fee"`
 	InstallmentAmount float64 `json:"installment_amount"`
 	InterestAmount    float64 `json:"interest_amount"`
+	AssetID           int     `json:"asset_id"`
 	AssetName         string  `json:"asset_name"`
+	DownPayment       float64 `json:"down_payment"`
 	Tenor             int     `json:"tenor"`
+	Status            string  `json:"status"`
+	// Channel and PartnerID record who originated the transaction
+	Channel   string `json:"channel"`
+	PartnerID *int   `json:"partner_id,omitempty"`
+	// PromoCode is the voucher code redeemed for the transaction and DiscountAmount what it took off the price
+	PromoCode      string  `json:"promo_code,omitempty"`
+	DiscountAmount float64 `json:"discount_amount,omitempty"`
+	// PricingRuleID and PricingRuleVersion record the rule that computed the admin fee and interest
+	PricingRuleID      *int `json:"pricing_rule_id,omitempty"`
+	PricingRuleVersion int  `json:"pricing_rule_version,omitempty"`
+	// OTPHash is the SHA-256 hash of the one-time code confirming the transaction
+	OTPHash      string     `json:"-"`
+	OTPAttempts  int        `json:"-"`
+	OTPExpiresAt *time.Time `json:"otp_expires_at,omitempty"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type ConfirmTransactionRequest struct {
+	Code string `json:"code"`
+}
+
+// Columns transactions can be sorted by
+const (
+	TransactionSortCreatedAt         = "created_at"
+	TransactionSortOTR               = "otr"
+	TransactionSortInstallmentAmount = "installment_amount"
+)
+
+// TransactionFilter selects a page of transactions. Zero values do not filter.
+type TransactionFilter struct {
+	CustomerID int
+	Status     string
+	Tenor      int
+	AssetID    int
+	PartnerID  int
+	// CreatedFrom is inclusive and CreatedTo exclusive
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinOTR      float64
+	MaxOTR      float64
+	Sort        string
+	Descending  bool
+	// After continues the listing behind the last transaction of the previous page
+	After *TransactionCursor
+	Limit int
+}
+
+// TransactionCursor is the position of a transaction in a sorted listing: the value of
+// the sort column, with the ID breaking ties
+type TransactionCursor struct {
+	Value interface{}
+	ID    int
+}
+
+// TransactionPage is a page of a transaction listing. NextCursor is empty on the last page.
+type TransactionPage struct {
+	Transactions interface{} `json:"transactions"`
+	NextCursor   string      `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is a notification addressed to a single customer
+type Message struct {
+	CustomerID int    `json:"customer_id"`
+	Recipient  string `json:"recipient"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+}
+
+// Notifier delivers messages to customers, e.g. by SMS, e-mail or push
+type Notifier interface {
+	Notify(message Message) error
+}
+
+// ConsoleNotifier is a Notifier implementation writing messages to the application log.
+// It is meant for local development only.
+type ConsoleNotifier struct{}
+
+// NewConsoleNotifier creates a new instance of ConsoleNotifier
+func NewConsoleNotifier() *ConsoleNotifier {
+	return &ConsoleNotifier{}
+}
+
+// Notify logs the message
+func (n *ConsoleNotifier) Notify(message Message) error {
+	logrus.WithFields(logrus.Fields{
+		"customer_id": message.CustomerID,
+		"recipient":   message.Recipient,
+		"subject":     message.Subject,
+	}).Info(message.Body)
+	return nil
+}
+
+// FileNotifier is a Notifier implementation appending messages as JSON lines to a file
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a new instance of FileNotifier writing to path
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return &FileNotifier{
+		Path: path,
+	}, nil
+}
+
+type fileNotification struct {
+	Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Notify appends the message to the file
+func (n *FileNotifier) Notify(message Message) error {
+	line, err := json.Marshal(fileNotification{Message: message, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,123 @@
+// Package pdf writes text-only PDF documents without external dependencies.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Layout of the A4 pages in points. Documents are set in Courier so columns line up.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+	fontSize   = 9
+	lineHeight = 12
+)
+
+// LineWidth is the number of characters fitting on a line
+const LineWidth = 90
+
+// Document is a minimal PDF writer for text-only documents. It writes no timestamps or
+// IDs, so the same content always renders to the same bytes.
+type Document struct {
+	pages []*bytes.Buffer
+	y     int
+}
+
+// New creates an empty document with a single page
+func New() *Document {
+	doc := &Document{}
+	doc.newPage()
+	return doc
+}
+
+func (doc *Document) newPage() {
+	doc.pages = append(doc.pages, &bytes.Buffer{})
+	doc.y = pageHeight - margin
+}
+
+// Text adds a line of regular text, starting a new page when the current one is full
+func (doc *Document) Text(line string) {
+	doc.write("F1", line)
+}
+
+// Bold adds a line of bold text
+func (doc *Document) Bold(line string) {
+	doc.write("F2", line)
+}
+
+// Blank adds an empty line
+func (doc *Document) Blank() {
+	doc.y -= lineHeight
+}
+
+func (doc *Document) write(font, line string) {
+	// Leave room for the page footer
+	if doc.y < margin+2*lineHeight {
+		doc.newPage()
+	}
+	page := doc.pages[len(doc.pages)-1]
+	fmt.Fprintf(page, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, fontSize, margin, doc.y, escapeText(line))
+	doc.y -= lineHeight
+}
+
+// WriteTo writes the document with a page number footer on every page
+func (doc *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts; each page then
+	// takes a page object followed by its content stream
+	kids := make([]string, len(doc.pages))
+	for i := range doc.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(doc.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range doc.pages {
+		content := page.String() + fmt.Sprintf("BT /F1 %d Tf %d %d Td (%s) Tj ET\n", fontSize, margin, margin,
+			escapeText(fmt.Sprintf("Page %d of %d", i+1, len(doc.pages))))
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// escapeText escapes a string for a PDF literal. Characters outside printable ASCII,
+// which the standard fonts cannot show reliably, are replaced with a question mark.
+func escapeText(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < 32 || r > 126:
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
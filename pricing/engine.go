@@ -0,0 +1,146 @@
+// Package pricing computes the admin fee and interest of a transaction from the pricing rules in effect.
+package pricing
+
+import (
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoRule is returned when no pricing rule applies to a transaction
+var ErrNoRule = errors.New("no pricing rule applies to the transaction")
+
+// Request describes the transaction to price
+type Request struct {
+	Tenor          int
+	AssetCategory  string
+	PartnerID      *int
+	RiskGrade      string
+	PromoCode      string
+	FinancedAmount float64
+	At             time.Time
+}
+
+// Quote is the price of a transaction and the rule that computed it
+type Quote struct {
+	AdminFee       float64
+	InterestAmount float64
+	Rule           model.PricingRule
+}
+
+// Engine prices transactions with the rules stored in a PricingRuleRepository
+type Engine struct {
+	Rules repository.PricingRuleRepository
+}
+
+// NewEngine creates a new instance of Engine
+func NewEngine(rules repository.PricingRuleRepository) *Engine {
+	return &Engine{
+		Rules: rules,
+	}
+}
+
+// Quote prices the request with the most specific rule in effect at request.At
+func (e *Engine) Quote(ctx context.Context, request Request) (*Quote, error) {
+	rules, err := e.Rules.ListEffectivePricingRules(ctx, request.At)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := SelectRule(rules, request)
+	if rule == nil {
+		return nil, ErrNoRule
+	}
+
+	adminFee, interest := Price(*rule, request.FinancedAmount, request.Tenor)
+	return &Quote{AdminFee: adminFee, InterestAmount: interest, Rule: *rule}, nil
+}
+
+// SelectRule returns the matching rule with the most criteria set. Ties go to the
+// higher priority, then to the rule that took effect last, then to the newest rule.
+func SelectRule(rules []model.PricingRule, request Request) *model.PricingRule {
+	var selected *model.PricingRule
+	selectedSpecificity := -1
+
+	for i := range rules {
+		rule := &rules[i]
+		if !isEffective(*rule, request.At) || !matches(*rule, request) {
+			continue
+		}
+
+		specificity := specificity(*rule)
+		if selected == nil || specificity > selectedSpecificity ||
+			specificity == selectedSpecificity && outranks(*rule, *selected) {
+			selected = rule
+			selectedSpecificity = specificity
+		}
+	}
+
+	return selected
+}
+
+// Price computes the admin fee and the flat interest over the tenor, rounded to cents
+func Price(rule model.PricingRule, financedAmount float64, tenor int) (adminFee float64, interest float64) {
+	adminFee = rule.AdminFee + financedAmount*rule.AdminFeePercent/100
+	interest = financedAmount * rule.MonthlyInterestPercent / 100 * float64(tenor)
+	return util.RoundCents(adminFee), util.RoundCents(interest)
+}
+
+func isEffective(rule model.PricingRule, at time.Time) bool {
+	if at.Before(rule.EffectiveFrom) {
+		return false
+	}
+	return rule.EffectiveTo == nil || at.Before(*rule.EffectiveTo)
+}
+
+func matches(rule model.PricingRule, request Request) bool {
+	if rule.Tenor != 0 && rule.Tenor != request.Tenor {
+		return false
+	}
+	if rule.AssetCategory != "" && rule.AssetCategory != request.AssetCategory {
+		return false
+	}
+	if rule.PartnerID != nil && (request.PartnerID == nil || *rule.PartnerID != *request.PartnerID) {
+		return false
+	}
+	if rule.RiskGrade != "" && rule.RiskGrade != request.RiskGrade {
+		return false
+	}
+	if rule.PromoCode != "" && rule.PromoCode != request.PromoCode {
+		return false
+	}
+	return true
+}
+
+func specificity(rule model.PricingRule) int {
+	n := 0
+	if rule.Tenor != 0 {
+		n++
+	}
+	if rule.AssetCategory != "" {
+		n++
+	}
+	if rule.PartnerID != nil {
+		n++
+	}
+	if rule.RiskGrade != "" {
+		n++
+	}
+	if rule.PromoCode != "" {
+		n++
+	}
+	return n
+}
+
+func outranks(rule, other model.PricingRule) bool {
+	if rule.Priority != other.Priority {
+		return rule.Priority > other.Priority
+	}
+	if !rule.EffectiveFrom.Equal(other.EffectiveFrom) {
+		return rule.EffectiveFrom.After(other.EffectiveFrom)
+	}
+	return rule.ID > other.ID
+}
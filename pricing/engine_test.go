@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"alif-sigmatech/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectRule(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	lastMonth := now.AddDate(0, -1, 0)
+	nextMonth := now.AddDate(0, 1, 0)
+	partnerID := 3
+
+	rules := []model.PricingRule{
+		{ID: 1, Code: "DEFAULT", EffectiveFrom: lastMonth},
+		{ID: 2, Code: "MOTOR", AssetCategory: "motorcycle", EffectiveFrom: lastMonth},
+		{ID: 3, Code: "MOTOR-T4", AssetCategory: "motorcycle", Tenor: 4, EffectiveFrom: lastMonth},
+		{ID: 4, Code: "DEALER", PartnerID: &partnerID, EffectiveFrom: lastMonth},
+		{ID: 5, Code: "DEALER-BOOST", PartnerID: &partnerID, Priority: 10, EffectiveFrom: lastMonth},
+		{ID: 6, Code: "RISK-C", RiskGrade: "C", AssetCategory: "motorcycle", Tenor: 4, EffectiveFrom: lastMonth},
+		{ID: 7, Code: "PROMO", PromoCode: "RAMADAN", EffectiveFrom: lastMonth},
+		{ID: 8, Code: "FUTURE", AssetCategory: "car", EffectiveFrom: nextMonth},
+		{ID: 9, Code: "EXPIRED", AssetCategory: "electronics", EffectiveFrom: lastMonth, EffectiveTo: &now},
+	}
+
+	tests := []struct {
+		name       string
+		request    Request
+		expectedID int
+	}{
+		{name: "Fallback", request: Request{Tenor: 1, AssetCategory: "car"}, expectedID: 1},
+		{name: "Category", request: Request{Tenor: 1, AssetCategory: "motorcycle"}, expectedID: 2},
+		{name: "Category and tenor", request: Request{Tenor: 4, AssetCategory: "motorcycle"}, expectedID: 3},
+		{name: "Risk grade adds specificity", request: Request{Tenor: 4, AssetCategory: "motorcycle", RiskGrade: "C"}, expectedID: 6},
+		{name: "Priority breaks ties", request: Request{Tenor: 1, AssetCategory: "car", PartnerID: &partnerID}, expectedID: 5},
+		{name: "Promo code", request: Request{Tenor: 1, PromoCode: "RAMADAN"}, expectedID: 7},
+		{name: "Unknown promo code falls back", request: Request{Tenor: 1, PromoCode: "UNKNOWN"}, expectedID: 1},
+		{name: "Rule not yet effective", request: Request{Tenor: 1, AssetCategory: "car"}, expectedID: 1},
+		{name: "Rule no longer effective", request: Request{Tenor: 1, AssetCategory: "electronics"}, expectedID: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.At = now
+			rule := SelectRule(rules, tt.request)
+			if assert.NotNil(t, rule) {
+				assert.Equal(t, tt.expectedID, rule.ID)
+			}
+		})
+	}
+
+	t.Run("No rule applies", func(t *testing.T) {
+		assert.Nil(t, SelectRule(rules[1:3], Request{Tenor: 1, AssetCategory: "car", At: now}))
+	})
+}
+
+func TestPrice(t *testing.T) {
+	rule := model.PricingRule{AdminFee: 50000, AdminFeePercent: 1, MonthlyInterestPercent: 1.75}
+
+	adminFee, interest := Price(rule, 15000000, 3)
+
+	assert.Equal(t, 200000.0, adminFee)
+	assert.Equal(t, 787500.0, interest)
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// AssetRepository defines the interface for asset catalogue data access
+type AssetRepository interface {
+	CreateAsset(ctx context.Context, asset *model.Asset) error
+	GetAssetByID(ctx context.Context, id int) (*model.Asset, error)
+	ListAssets(ctx context.Context, category string, activeOnly bool) ([]model.Asset, error)
+	UpdateAsset(ctx context.Context, asset *model.Asset) error
+	DeactivateAsset(ctx context.Context, id int) (bool, error)
+}
+
+// MySQLAssetRepository is a repository implementation using MySQL
+type MySQLAssetRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLAssetRepository creates a new instance of MySQLAssetRepository
+func NewMySQLAssetRepository(db DBTX, timeouts Timeouts) *MySQLAssetRepository {
+	return &MySQLAssetRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+const assetColumns = "id, category, brand, model, min_otr, max_otr, max_finance_percent, allowed_tenors, active, created_at, updated_at"
+
+// CreateAsset stores a new catalogue entry
+func (repo *MySQLAssetRepository) CreateAsset(ctx context.Context, asset *model.Asset) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateAsset")
+	defer cancel()
+
+	query := "INSERT INTO asset (category, brand, model, min_otr, max_otr, max_finance_percent, allowed_tenors, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, asset.Category, asset.Brand, asset.Model, asset.MinOTR, asset.MaxOTR, asset.MaxFinancePercent, formatTenors(asset.AllowedTenors), asset.Active, asset.CreatedAt, asset.UpdatedAt)
+	if err != nil {
+		return util.CheckMySQLError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	asset.ID = int(id)
+
+	return nil
+}
+
+// GetAssetByID returns a catalogue entry or nil when it does not exist
+func (repo *MySQLAssetRepository) GetAssetByID(ctx context.Context, id int) (*model.Asset, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetAssetByID")
+	defer cancel()
+
+	query := "SELECT " + assetColumns + " FROM asset WHERE id = ?"
+
+	asset, err := scanAsset(repo.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No asset found with the given ID
+		}
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// ListAssets returns the catalogue ordered by category, brand and model. An empty category lists all of them.
+func (repo *MySQLAssetRepository) ListAssets(ctx context.Context, category string, activeOnly bool) ([]model.Asset, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListAssets")
+	defer cancel()
+
+	query := "SELECT " + assetColumns + " FROM asset WHERE (? = '' OR category = ?) AND (? = FALSE OR active = TRUE) ORDER BY category, brand, model"
+
+	rows, err := repo.DB.QueryContext(ctx, query, category, category, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := []model.Asset{}
+	for rows.Next() {
+		asset, err := scanAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, *asset)
+	}
+
+	return assets, rows.Err()
+}
+
+// UpdateAsset stores the changed fields of a catalogue entry
+func (repo *MySQLAssetRepository) UpdateAsset(ctx context.Context, asset *model.Asset) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "UpdateAsset")
+	defer cancel()
+
+	query := "UPDATE asset SET category = ?, brand = ?, model = ?, min_otr = ?, max_otr = ?, max_finance_percent = ?, allowed_tenors = ?, active = ?, updated_at = ? WHERE id = ?"
+	_, err := repo.DB.ExecContext(ctx, query, asset.Category, asset.Brand, asset.Model, asset.MinOTR, asset.MaxOTR, asset.MaxFinancePercent, formatTenors(asset.AllowedTenors), asset.Active, asset.UpdatedAt, asset.ID)
+	return util.CheckMySQLError(err)
+}
+
+// DeactivateAsset removes an asset from the catalogue while keeping it for existing transactions.
+// It reports false when the asset does not exist.
+func (repo *MySQLAssetRepository) DeactivateAsset(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "DeactivateAsset")
+	defer cancel()
+
+	query := "UPDATE asset SET active = FALSE WHERE id = ?"
+	result, err := repo.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func scanAsset(row rowScanner) (*model.Asset, error) {
+	var asset model.Asset
+	var allowedTenors string
+
+	err := row.Scan(
+		&asset.ID,
+		&asset.Category,
+		&asset.Brand,
+		&asset.Model,
+		&asset.MinOTR,
+		&asset.MaxOTR,
+		&asset.MaxFinancePercent,
+		&allowedTenors,
+		&asset.Active,
+		&asset.CreatedAt,
+		&asset.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	asset.AllowedTenors, err = parseTenors(allowedTenors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// formatTenors stores allowed tenors as a comma separated list
+func formatTenors(tenors []int) string {
+	values := make([]string, len(tenors))
+	for i, tenor := range tenors {
+		values[i] = strconv.Itoa(tenor)
+	}
+	return strings.Join(values, ",")
+}
+
+func parseTenors(value string) ([]int, error) {
+	tenors := []int{}
+	if value == "" {
+		return tenors, nil
+	}
+	for _, v := range strings.Split(value, ",") {
+		tenor, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		tenors = append(tenors, tenor)
+	}
+	return tenors, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ContractDocumentRepository defines the interface for contract document data access
+type ContractDocumentRepository interface {
+	CreateContractDocument(ctx context.Context, document *model.ContractDocument) error
+	GetContractDocumentByTransactionID(ctx context.Context, transactionID int) (*model.ContractDocument, error)
+	AcceptContractDocument(ctx context.Context, id int, contentHash, ip, userAgent string, acceptedAt time.Time) (bool, error)
+}
+
+// MySQLContractDocumentRepository is a repository implementation using MySQL
+type MySQLContractDocumentRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLContractDocumentRepository creates a new instance of MySQLContractDocumentRepository
+func NewMySQLContractDocumentRepository(db DBTX, timeouts Timeouts) *MySQLContractDocumentRepository {
+	return &MySQLContractDocumentRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// CreateContractDocument stores the metadata of a rendered contract document
+func (repo *MySQLContractDocumentRepository) CreateContractDocument(ctx context.Context, document *model.ContractDocument) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateContractDocument")
+	defer cancel()
+
+	query := "INSERT INTO contract_document (transaction_id, customer_id, template, content_hash, storage_key, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, document.TransactionID, document.CustomerID, document.Template, document.ContentHash, document.StorageKey, document.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	document.ID = int(id)
+
+	return nil
+}
+
+// GetContractDocumentByTransactionID returns the contract document of the transaction or nil when none was rendered
+func (repo *MySQLContractDocumentRepository) GetContractDocumentByTransactionID(ctx context.Context, transactionID int) (*model.ContractDocument, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetContractDocumentByTransactionID")
+	defer cancel()
+
+	query := "SELECT id, transaction_id, customer_id, template, content_hash, storage_key, created_at, accepted_at, accepted_hash, accepted_ip, accepted_user_agent FROM contract_document WHERE transaction_id = ?"
+
+	var document model.ContractDocument
+	var acceptedAt sql.NullTime
+	var acceptedHash, acceptedIP, acceptedUserAgent sql.NullString
+	err := repo.DB.QueryRowContext(ctx, query, transactionID).Scan(
+		&document.ID,
+		&document.TransactionID,
+		&document.CustomerID,
+		&document.Template,
+		&document.ContentHash,
+		&document.StorageKey,
+		&document.CreatedAt,
+		&acceptedAt,
+		&acceptedHash,
+		&acceptedIP,
+		&acceptedUserAgent,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No contract document rendered for the transaction
+		}
+		return nil, err
+	}
+
+	if acceptedAt.Valid {
+		document.AcceptedAt = &acceptedAt.Time
+	}
+	document.AcceptedHash = acceptedHash.String
+	document.AcceptedIP = acceptedIP.String
+	document.AcceptedUserAgent = acceptedUserAgent.String
+
+	return &document, nil
+}
+
+// AcceptContractDocument records the customer's acceptance. It reports false when the document
+// was already accepted or its content hash differs, so an acceptance is never overwritten.
+func (repo *MySQLContractDocumentRepository) AcceptContractDocument(ctx context.Context, id int, contentHash, ip, userAgent string, acceptedAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "AcceptContractDocument")
+	defer cancel()
+
+	query := "UPDATE contract_document SET accepted_at = ?, accepted_hash = ?, accepted_ip = ?, accepted_user_agent = ? WHERE id = ? AND content_hash = ? AND accepted_at IS NULL"
+	result, err := repo.DB.ExecContext(ctx, query, acceptedAt, contentHash, ip, userAgent, id, contentHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"database/sql"
+)
+
+// CorrectionRepository defines the interface for identity correction request data access
+type CorrectionRepository interface {
+	CreateCorrectionRequest(ctx context.Context, request *model.CorrectionRequest) error
+	GetCorrectionRequestByID(ctx context.Context, id int) (*model.CorrectionRequest, error)
+	ListCorrectionRequests(ctx context.Context, status string) ([]model.CorrectionRequest, error)
+	UpdateCorrectionRequestReview(ctx context.Context, request *model.CorrectionRequest) (bool, error)
+}
+
+// MySQLCorrectionRepository is a repository implementation using MySQL
+type MySQLCorrectionRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLCorrectionRepository creates a new instance of MySQLCorrectionRepository
+func NewMySQLCorrectionRepository(db DBTX, timeouts Timeouts) *MySQLCorrectionRepository {
+	return &MySQLCorrectionRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+const correctionRequestColumns = "id, customer_id, field_name, requested_value, reason, status, reviewed_by, review_note, created_at, reviewed_at"
+
+// CreateCorrectionRequest stores a new pending correction request
+func (repo *MySQLCorrectionRepository) CreateCorrectionRequest(ctx context.Context, request *model.CorrectionRequest) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateCorrectionRequest")
+	defer cancel()
+
+	query := "INSERT INTO correction_request (customer_id, field_name, requested_value, reason, status, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, request.CustomerID, request.FieldName, request.RequestedValue, request.Reason, request.Status, request.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	request.ID = int(id)
+
+	return nil
+}
+
+// GetCorrectionRequestByID returns a correction request or nil when it does not exist
+func (repo *MySQLCorrectionRepository) GetCorrectionRequestByID(ctx context.Context, id int) (*model.CorrectionRequest, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetCorrectionRequestByID")
+	defer cancel()
+
+	query := "SELECT " + correctionRequestColumns + " FROM correction_request WHERE id = ?"
+
+	request, err := scanCorrectionRequest(repo.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No correction request found with the given ID
+		}
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ListCorrectionRequests returns the correction requests with the given status, oldest first
+func (repo *MySQLCorrectionRepository) ListCorrectionRequests(ctx context.Context, status string) ([]model.CorrectionRequest, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListCorrectionRequests")
+	defer cancel()
+
+	query := "SELECT " + correctionRequestColumns + " FROM correction_request WHERE status = ? ORDER BY id"
+
+	rows, err := repo.DB.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []model.CorrectionRequest{}
+	for rows.Next() {
+		request, err := scanCorrectionRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+// UpdateCorrectionRequestReview stores the officer's decision on a pending correction request.
+// It reports false when the request was no longer pending, so it cannot be reviewed twice.
+func (repo *MySQLCorrectionRepository) UpdateCorrectionRequestReview(ctx context.Context, request *model.CorrectionRequest) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "UpdateCorrectionRequestReview")
+	defer cancel()
+
+	query := "UPDATE correction_request SET status = ?, reviewed_by = ?, review_note = ?, reviewed_at = ? WHERE id = ? AND status = ?"
+	result, err := repo.DB.ExecContext(ctx, query, request.Status, request.ReviewedBy, request.ReviewNote, request.ReviewedAt, request.ID, model.CorrectionPending)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCorrectionRequest(row rowScanner) (*model.CorrectionRequest, error) {
+	var request model.CorrectionRequest
+	var reviewedBy sql.NullInt64
+	var reviewedAt sql.NullTime
+
+	err := row.Scan(
+		&request.ID,
+		&request.CustomerID,
+		&request.FieldName,
+		&request.RequestedValue,
+		&request.Reason,
+		&request.Status,
+		&reviewedBy,
+		&request.ReviewNote,
+		&request.CreatedAt,
+		&reviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewedBy.Valid {
+		id := int(reviewedBy.Int64)
+		request.ReviewedBy = &id
+	}
+	if reviewedAt.Valid {
+		request.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &request, nil
+}
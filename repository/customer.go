@@ -1,57 +1,87 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
 	"alif-sigmatech/model"
+	"alif-sigmatech/util"
 )
 
 // CustomerRepository defines the interface for customer data access
 type CustomerRepository interface {
-	RegisterCustomer(customer *model.Customer) error
-	GetCustomerByNIK(nik string) (*model.Customer, error)
-	GetCustomerByID(id int) (*model.Customer, error)
+	RegisterCustomer(ctx context.Context, customer *model.Customer) error
+	GetCustomerByNIK(ctx context.Context, nik string) (*model.Customer, error)
+	GetCustomerByID(ctx context.Context, id int) (*model.Customer, error)
+	UpdateCustomerProfile(ctx context.Context, customer *model.Customer) error
+	UpdateCustomerIdentity(ctx context.Context, customer *model.Customer) error
+	UpdatePassword(ctx context.Context, id int, hashedPassword string) error
+	RevokeSessions(ctx context.Context, id int) error
+	UpdateMFA(ctx context.Context, id int, encryptedSecret []byte, enabled bool) error
 }
 
 // MySQLCustomerRepository is a repository implementation using MySQL
 type MySQLCustomerRepository struct {
-	DB *sql.DB
+	DB       DBTX
+	Timeouts Timeouts
 }
 
 // NewMySQLCustomerRepository creates a new instance of MySQLCustomerRepository
-func NewMySQLCustomerRepository(db *sql.DB) *MySQLCustomerRepository {
+func NewMySQLCustomerRepository(db DBTX, timeouts Timeouts) *MySQLCustomerRepository {
 	return &MySQLCustomerRepository{
-		DB: db,
+		DB:       db,
+		Timeouts: timeouts,
 	}
 }
 
 // RegisterCustomer registers a new consumer
-func (repo *MySQLCustomerRepository) RegisterCustomer(customer *model.Customer) error {
-	query := "INSERT INTO customer (nik, full_name, password, legal_name, birth_place, birth_date, salary, ktp_photo, selfie_photo) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+func (repo *MySQLCustomerRepository) RegisterCustomer(ctx context.Context, customer *model.Customer) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "RegisterCustomer")
+	defer cancel()
 
-	_, err := repo.DB.Exec(query, customer.NIK, customer.FullName, customer.Password, customer.LegalName, customer.BirthPlace, customer.BirthDate, customer.Salary, customer.KTPPhoto, customer.SelfiePhoto)
+	query := "INSERT INTO customer (nik, full_name, password, legal_name, birth_place, birth_date, salary, address, phone_number, role) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	result, err := repo.DB.ExecContext(ctx, query, customer.NIK, customer.FullName, customer.Password, customer.LegalName, customer.BirthPlace, nullDate(customer.BirthDate), customer.Salary, customer.Address, customer.PhoneNumber, customer.Role)
+	if err != nil {
+		return util.CheckMySQLError(err)
+	}
+
+	id, err := result.LastInsertId()
 	if err != nil {
 		return err
 	}
+	customer.ID = int(id)
 
 	return nil
 }
 
 // GetCustomerByNIK mengambil data pelanggan berdasarkan NIK dari database
-func (repo *MySQLCustomerRepository) GetCustomerByNIK(nik string) (*model.Customer, error) {
+func (repo *MySQLCustomerRepository) GetCustomerByNIK(ctx context.Context, nik string) (*model.Customer, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetCustomerByNIK")
+	defer cancel()
+
 	customer := &model.Customer{}
-	query := "SELECT id, nik, full_name, password, legal_name, birth_place, birth_date, salary FROM customer WHERE nik = ?"
+	var birthDate sql.NullTime
+	query := "SELECT id, nik, full_name, password, legal_name, birth_place, birth_date, salary, address, phone_number, language, role, risk_grade, token_version, mfa_enabled, mfa_secret FROM customer WHERE nik = ?"
 
-	err := repo.DB.QueryRow(query, nik).Scan(
+	err := repo.DB.QueryRowContext(ctx, query, nik).Scan(
 		&customer.ID,
 		&customer.NIK,
 		&customer.FullName,
 		&customer.Password,
 		&customer.LegalName,
 		&customer.BirthPlace,
-		&customer.BirthDate,
+		&birthDate,
 		&customer.Salary,
+		&customer.Address,
+		&customer.PhoneNumber,
+		&customer.Language,
+		&customer.Role,
+		&customer.RiskGrade,
+		&customer.TokenVersion,
+		&customer.MFAEnabled,
+		&customer.MFASecret,
 	)
 
 	if err != nil {
@@ -62,23 +92,37 @@ func (repo *MySQLCustomerRepository) GetCustomerByNIK(nik string) (*model.Custom
 		return nil, err
 	}
 
+	customer.BirthDate = formatDate(birthDate)
+
 	return customer, nil
 }
 
 // GetCustomerByID mengambil data pelanggan berdasarkan ID dari database
-func (repo *MySQLCustomerRepository) GetCustomerByID(id int) (*model.Customer, error) {
+func (repo *MySQLCustomerRepository) GetCustomerByID(ctx context.Context, id int) (*model.Customer, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetCustomerByID")
+	defer cancel()
+
 	customer := &model.Customer{}
-	query := "SELECT id, nik, full_name, password, legal_name, birth_place, birth_date, salary FROM customer WHERE id = ?"
+	var birthDate sql.NullTime
+	query := "SELECT id, nik, full_name, password, legal_name, birth_place, birth_date, salary, address, phone_number, language, role, risk_grade, token_version, mfa_enabled, mfa_secret FROM customer WHERE id = ?"
 
-	err := repo.DB.QueryRow(query, id).Scan(
+	err := repo.DB.QueryRowContext(ctx, query, id).Scan(
 		&customer.ID,
 		&customer.NIK,
 		&customer.FullName,
 		&customer.Password,
 		&customer.LegalName,
 		&customer.BirthPlace,
-		&customer.BirthDate,
+		&birthDate,
 		&customer.Salary,
+		&customer.Address,
+		&customer.PhoneNumber,
+		&customer.Language,
+		&customer.Role,
+		&customer.RiskGrade,
+		&customer.TokenVersion,
+		&customer.MFAEnabled,
+		&customer.MFASecret,
 	)
 
 	if err != nil {
@@ -89,5 +133,77 @@ func (repo *MySQLCustomerRepository) GetCustomerByID(id int) (*model.Customer, e
 		return nil, err
 	}
 
+	customer.BirthDate = formatDate(birthDate)
+
 	return customer, nil
 }
+
+// UpdateCustomerProfile updates the fields a customer may change themselves
+func (repo *MySQLCustomerRepository) UpdateCustomerProfile(ctx context.Context, customer *model.Customer) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "UpdateCustomerProfile")
+	defer cancel()
+
+	query := "UPDATE customer SET salary = ?, address = ?, phone_number = ?, language = ? WHERE id = ?"
+	_, err := repo.DB.ExecContext(ctx, query, customer.Salary, customer.Address, customer.PhoneNumber, customer.Language, customer.ID)
+	return err
+}
+
+// UpdateCustomerIdentity updates the identity fields of a customer after an approved correction
+func (repo *MySQLCustomerRepository) UpdateCustomerIdentity(ctx context.Context, customer *model.Customer) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "UpdateCustomerIdentity")
+	defer cancel()
+
+	query := "UPDATE customer SET nik = ?, full_name = ?, legal_name = ?, birth_place = ?, birth_date = ? WHERE id = ?"
+	_, err := repo.DB.ExecContext(ctx, query, customer.NIK, customer.FullName, customer.LegalName, customer.BirthPlace, nullDate(customer.BirthDate), customer.ID)
+	return err
+}
+
+// UpdatePassword replaces the password hash of a customer
+func (repo *MySQLCustomerRepository) UpdatePassword(ctx context.Context, id int, hashedPassword string) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "UpdatePassword")
+	defer cancel()
+
+	query := "UPDATE customer SET password = ? WHERE id = ?"
+	_, err := repo.DB.ExecContext(ctx, query, hashedPassword, id)
+	return err
+}
+
+// RevokeSessions invalidates every token issued to the customer so far
+func (repo *MySQLCustomerRepository) RevokeSessions(ctx context.Context, id int) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "RevokeSessions")
+	defer cancel()
+
+	query := "UPDATE customer SET token_version = token_version + 1 WHERE id = ?"
+	_, err := repo.DB.ExecContext(ctx, query, id)
+	return err
+}
+
+// UpdateMFA stores the customer's encrypted TOTP secret and whether MFA is enforced at login
+func (repo *MySQLCustomerRepository) UpdateMFA(ctx context.Context, id int, encryptedSecret []byte, enabled bool) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "UpdateMFA")
+	defer cancel()
+
+	query := "UPDATE customer SET mfa_secret = ?, mfa_enabled = ? WHERE id = ?"
+	_, err := repo.DB.ExecContext(ctx, query, encryptedSecret, enabled, id)
+	return err
+}
+
+// dateLayout is the format of dates such as the birth date in the models
+const dateLayout = "2006-01-02"
+
+// formatDate formats a DATE column the way dates are exchanged with clients and written back,
+// as the driver parses it into a time at midnight UTC
+func formatDate(date sql.NullTime) string {
+	if !date.Valid {
+		return ""
+	}
+	return date.Time.Format(dateLayout)
+}
+
+// nullDate writes an empty date as NULL, which a DATE column accepts unlike an empty string
+func nullDate(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
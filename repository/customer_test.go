@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var customerColumns = []string{"id", "nik", "full_name", "password", "legal_name", "birth_place", "birth_date", "salary",
+	"address", "phone_number", "language", "role", "risk_grade", "token_version", "mfa_enabled", "mfa_secret"}
+
+func customerRow(birthDate driver.Value) *sqlmock.Rows {
+	return sqlmock.NewRows(customerColumns).AddRow(1, "3171234567890001", "Budi", "hash", "Budi Santoso", "Jakarta", birthDate,
+		10000000, "Jl. Sudirman 1", "081234567890", "id", "customer", "A", 0, false, nil)
+}
+
+func TestCustomerBirthDateRoundTrip(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	repo := NewMySQLCustomerRepository(db, DefaultTimeouts())
+
+	// With parseTime the driver hands DATE columns over as a time at midnight UTC
+	mock.ExpectQuery(regexp.QuoteMeta("FROM customer WHERE id = ?")).WithArgs(1).
+		WillReturnRows(customerRow(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE customer SET nik = ?, full_name = ?, legal_name = ?, birth_place = ?, birth_date = ? WHERE id = ?")).
+		WithArgs("3171234567890001", "Budi", "Budi Santoso", "Bandung", "1990-01-01", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	customer, err := repo.GetCustomerByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "1990-01-01", customer.BirthDate)
+
+	customer.BirthPlace = "Bandung"
+	assert.NoError(t, repo.UpdateCustomerIdentity(context.Background(), customer))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	t.Run("Missing birth date", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM customer WHERE nik = ?")).WithArgs("3171234567890001").
+			WillReturnRows(customerRow(nil))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE customer SET")).
+			WithArgs("3171234567890001", "Budi", "Budi Santoso", "Jakarta", nil, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		customer, err := repo.GetCustomerByNIK(context.Background(), "3171234567890001")
+		assert.NoError(t, err)
+		assert.Equal(t, "", customer.BirthDate)
+
+		assert.NoError(t, repo.UpdateCustomerIdentity(context.Background(), customer))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
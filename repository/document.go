@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"database/sql"
+)
+
+// DocumentRepository defines the interface for customer document data access
+type DocumentRepository interface {
+	CreateDocument(ctx context.Context, document *model.CustomerDocument) error
+	GetLatestDocument(ctx context.Context, customerID int, documentType string) (*model.CustomerDocument, error)
+	CreateAccessLog(ctx context.Context, accessLog *model.DocumentAccessLog) error
+}
+
+// MySQLDocumentRepository is a repository implementation using MySQL
+type MySQLDocumentRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLDocumentRepository creates a new instance of MySQLDocumentRepository
+func NewMySQLDocumentRepository(db DBTX, timeouts Timeouts) *MySQLDocumentRepository {
+	return &MySQLDocumentRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// CreateDocument stores the metadata of an uploaded document
+func (repo *MySQLDocumentRepository) CreateDocument(ctx context.Context, document *model.CustomerDocument) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateDocument")
+	defer cancel()
+
+	query := "INSERT INTO customer_document (customer_id, document_type, storage_key, thumbnail_key, content_type, size, width, height, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, document.CustomerID, document.DocumentType, document.StorageKey, document.ThumbnailKey, document.ContentType, document.Size, document.Width, document.Height, document.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	document.ID = int(id)
+
+	return nil
+}
+
+// GetLatestDocument returns the most recently uploaded document of the given type
+func (repo *MySQLDocumentRepository) GetLatestDocument(ctx context.Context, customerID int, documentType string) (*model.CustomerDocument, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetLatestDocument")
+	defer cancel()
+
+	query := "SELECT id, customer_id, document_type, storage_key, thumbnail_key, content_type, size, width, height, created_at FROM customer_document WHERE customer_id = ? AND document_type = ? ORDER BY id DESC LIMIT 1"
+
+	var document model.CustomerDocument
+	err := repo.DB.QueryRowContext(ctx, query, customerID, documentType).Scan(
+		&document.ID,
+		&document.CustomerID,
+		&document.DocumentType,
+		&document.StorageKey,
+		&document.ThumbnailKey,
+		&document.ContentType,
+		&document.Size,
+		&document.Width,
+		&document.Height,
+		&document.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No document uploaded yet
+		}
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// CreateAccessLog records who viewed which customer document and why
+func (repo *MySQLDocumentRepository) CreateAccessLog(ctx context.Context, accessLog *model.DocumentAccessLog) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateAccessLog")
+	defer cancel()
+
+	query := "INSERT INTO document_access_log (customer_id, document_type, officer_id, reason, ip_address, accessed_at) VALUES (?, ?, ?, ?, ?, ?)"
+	_, err := repo.DB.ExecContext(ctx, query, accessLog.CustomerID, accessLog.DocumentType, accessLog.OfficerID, accessLog.Reason, accessLog.IPAddress, accessLog.AccessedAt)
+	return err
+}
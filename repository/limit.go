@@ -2,31 +2,50 @@ package repository
 
 import (
 	"alif-sigmatech/model"
+	"context"
 	"database/sql"
 )
 
 type LimitRepository interface {
-	GetLimitByCustomerID(customerID int) (*model.Limit, error)
-	CreateLimit(limit *model.Limit) error
+	GetLimitByCustomerID(ctx context.Context, customerID int) (*model.Limit, error)
+	GetLimitByCustomerIDForUpdate(ctx context.Context, customerID int) (*model.Limit, error)
+	CreateLimit(ctx context.Context, limit *model.Limit) error
 }
 
 type MySQLLimitRepository struct {
-	DB *sql.DB
+	DB       DBTX
+	Timeouts Timeouts
 }
 
 // NewMySQLLimitRepository creates a new instance of MySQLLimitRepository
-func NewMySQLLimitRepository(db *sql.DB) *MySQLLimitRepository {
+func NewMySQLLimitRepository(db DBTX, timeouts Timeouts) *MySQLLimitRepository {
 	return &MySQLLimitRepository{
-		DB: db,
+		DB:       db,
+		Timeouts: timeouts,
 	}
 }
 
-func (repo *MySQLLimitRepository) GetLimitByCustomerID(customerID int) (*model.Limit, error) {
+func (repo *MySQLLimitRepository) GetLimitByCustomerID(ctx context.Context, customerID int) (*model.Limit, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetLimitByCustomerID")
+	defer cancel()
+
 	query := "SELECT customer_id, tenor_1, tenor_2, tenor_3, tenor_4 FROM `limit` WHERE customer_id = ?"
-	row := repo.DB.QueryRow(query, customerID)
+	return repo.getLimit(ctx, query, customerID)
+}
+
+// GetLimitByCustomerIDForUpdate reads the customer's limit and locks it until the unit of work
+// the repository is bound to ends, so the limit cannot change while it is being used
+func (repo *MySQLLimitRepository) GetLimitByCustomerIDForUpdate(ctx context.Context, customerID int) (*model.Limit, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetLimitByCustomerIDForUpdate")
+	defer cancel()
 
+	query := "SELECT customer_id, tenor_1, tenor_2, tenor_3, tenor_4 FROM `limit` WHERE customer_id = ? FOR UPDATE"
+	return repo.getLimit(ctx, query, customerID)
+}
+
+func (repo *MySQLLimitRepository) getLimit(ctx context.Context, query string, customerID int) (*model.Limit, error) {
 	var limit model.Limit
-	err := row.Scan(&limit.CustomerID, &limit.Tenor1, &limit.Tenor2, &limit.Tenor3, &limit.Tenor4)
+	err := repo.DB.QueryRowContext(ctx, query, customerID).Scan(&limit.CustomerID, &limit.Tenor1, &limit.Tenor2, &limit.Tenor3, &limit.Tenor4)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No limit found with the given ID
@@ -37,8 +56,11 @@ func (repo *MySQLLimitRepository) GetLimitByCustomerID(customerID int) (*model.L
 	return &limit, nil
 }
 
-func (repo *MySQLLimitRepository) CreateLimit(limit *model.Limit) error {
+func (repo *MySQLLimitRepository) CreateLimit(ctx context.Context, limit *model.Limit) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateLimit")
+	defer cancel()
+
 	query := "INSERT INTO `limit` (customer_id, tenor_1, tenor_2, tenor_3, tenor_4) VALUES (?, ?, ?, ?, ?)"
-	_, err := repo.DB.Exec(query, limit.CustomerID, limit.Tenor1, limit.Tenor2, limit.Tenor3, limit.Tenor4)
+	_, err := repo.DB.ExecContext(ctx, query, limit.CustomerID, limit.Tenor1, limit.Tenor2, limit.Tenor3, limit.Tenor4)
 	return err
 }
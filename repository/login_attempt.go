@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// LoginAttemptRepository defines the interface for failed login tracking
+type LoginAttemptRepository interface {
+	GetLoginAttempt(ctx context.Context, key string) (*model.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) error
+	DeleteLoginAttempt(ctx context.Context, key string) error
+}
+
+// MySQLLoginAttemptRepository is a repository implementation using MySQL
+type MySQLLoginAttemptRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLLoginAttemptRepository creates a new instance of MySQLLoginAttemptRepository
+func NewMySQLLoginAttemptRepository(db DBTX, timeouts Timeouts) *MySQLLoginAttemptRepository {
+	return &MySQLLoginAttemptRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// GetLoginAttempt returns the failed login counter for key or nil when there is none
+func (repo *MySQLLoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*model.LoginAttempt, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetLoginAttempt")
+	defer cancel()
+
+	query := "SELECT attempt_key, failures, last_failure_at FROM login_attempt WHERE attempt_key = ?"
+
+	var attempt model.LoginAttempt
+	err := repo.DB.QueryRowContext(ctx, query, key).Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailureAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No failed login recorded for the key
+		}
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordLoginFailure atomically increments the failed login counter for key.
+// A counter whose last failure happened before resetBefore starts again from one.
+func (repo *MySQLLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, at time.Time, resetBefore time.Time) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "RecordLoginFailure")
+	defer cancel()
+
+	query := "INSERT INTO login_attempt (attempt_key, failures, last_failure_at) VALUES (?, 1, ?) " +
+		"ON DUPLICATE KEY UPDATE failures = IF(last_failure_at < ?, 1, failures + 1), last_failure_at = VALUES(last_failure_at)"
+	_, err := repo.DB.ExecContext(ctx, query, key, at, resetBefore)
+	return err
+}
+
+// DeleteLoginAttempt clears the failed login counter for key
+func (repo *MySQLLoginAttemptRepository) DeleteLoginAttempt(ctx context.Context, key string) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "DeleteLoginAttempt")
+	defer cancel()
+
+	query := "DELETE FROM login_attempt WHERE attempt_key = ?"
+	_, err := repo.DB.ExecContext(ctx, query, key)
+	return err
+}
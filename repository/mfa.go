@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// MFARepository defines the interface for MFA recovery code and TOTP replay data access
+type MFARepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, customerID int, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, customerID int, codeHash string, usedAt time.Time) (bool, error)
+	AcceptTOTPStep(ctx context.Context, customerID int, step int64) (bool, error)
+}
+
+// MySQLMFARepository is a repository implementation using MySQL
+type MySQLMFARepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLMFARepository creates a new instance of MySQLMFARepository
+func NewMySQLMFARepository(db DBTX, timeouts Timeouts) *MySQLMFARepository {
+	return &MySQLMFARepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// ReplaceRecoveryCodes discards the customer's previous recovery codes and stores the new hashes
+func (repo *MySQLMFARepository) ReplaceRecoveryCodes(ctx context.Context, customerID int, codeHashes []string) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ReplaceRecoveryCodes")
+	defer cancel()
+
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_code WHERE customer_id = ?", customerID)
+		if err != nil {
+			return err
+		}
+
+		for _, codeHash := range codeHashes {
+			_, err = tx.ExecContext(ctx, "INSERT INTO mfa_recovery_code (customer_id, code_hash) VALUES (?, ?)", customerID, codeHash)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used. It reports false
+// when the code does not exist or has already been used.
+func (repo *MySQLMFARepository) ConsumeRecoveryCode(ctx context.Context, customerID int, codeHash string, usedAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ConsumeRecoveryCode")
+	defer cancel()
+
+	query := "UPDATE mfa_recovery_code SET used_at = ? WHERE customer_id = ? AND code_hash = ? AND used_at IS NULL"
+	result, err := repo.DB.ExecContext(ctx, query, usedAt, customerID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// AcceptTOTPStep records the time step of a TOTP code the customer used. It reports false when
+// a code of that step or a later one was already accepted, so a code cannot be used twice.
+func (repo *MySQLMFARepository) AcceptTOTPStep(ctx context.Context, customerID int, step int64) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "AcceptTOTPStep")
+	defer cancel()
+
+	query := "UPDATE customer SET mfa_last_step = ? WHERE id = ? AND (mfa_last_step IS NULL OR mfa_last_step < ?)"
+	result, err := repo.DB.ExecContext(ctx, query, step, customerID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PartnerRepository defines the interface for partner, consent and request nonce data access
+type PartnerRepository interface {
+	CreatePartner(ctx context.Context, partner *model.Partner) error
+	GetPartnerByAPIKeyHash(ctx context.Context, apiKeyHash string) (*model.Partner, error)
+	GetPartnerByID(ctx context.Context, id int) (*model.Partner, error)
+	GetConsent(ctx context.Context, customerID, partnerID int) (*model.PartnerConsent, error)
+	GrantConsent(ctx context.Context, consent *model.PartnerConsent) error
+	RevokeConsent(ctx context.Context, customerID, partnerID int, revokedAt time.Time) (bool, error)
+	RecordNonce(ctx context.Context, partnerID int, nonce string, now, expiresAt time.Time) (bool, error)
+}
+
+// MySQLPartnerRepository is a repository implementation using MySQL
+type MySQLPartnerRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLPartnerRepository creates a new instance of MySQLPartnerRepository
+func NewMySQLPartnerRepository(db DBTX, timeouts Timeouts) *MySQLPartnerRepository {
+	return &MySQLPartnerRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// CreatePartner stores a new partner
+func (repo *MySQLPartnerRepository) CreatePartner(ctx context.Context, partner *model.Partner) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreatePartner")
+	defer cancel()
+
+	query := "INSERT INTO partner (name, channel, api_key_prefix, api_key_hash, signing_secret, active, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, partner.Name, partner.Channel, partner.APIKeyPrefix, partner.APIKeyHash, partner.SigningSecret, partner.Active, partner.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	partner.ID = int(id)
+
+	return nil
+}
+
+// GetPartnerByAPIKeyHash returns the partner owning the API key with the given hash or nil when there is none
+func (repo *MySQLPartnerRepository) GetPartnerByAPIKeyHash(ctx context.Context, apiKeyHash string) (*model.Partner, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetPartnerByAPIKeyHash")
+	defer cancel()
+
+	query := "SELECT id, name, channel, api_key_prefix, api_key_hash, signing_secret, active, created_at FROM partner WHERE api_key_hash = ?"
+	return repo.getPartner(ctx, query, apiKeyHash)
+}
+
+// GetPartnerByID returns the partner with the given ID or nil when it does not exist
+func (repo *MySQLPartnerRepository) GetPartnerByID(ctx context.Context, id int) (*model.Partner, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetPartnerByID")
+	defer cancel()
+
+	query := "SELECT id, name, channel, api_key_prefix, api_key_hash, signing_secret, active, created_at FROM partner WHERE id = ?"
+	return repo.getPartner(ctx, query, id)
+}
+
+func (repo *MySQLPartnerRepository) getPartner(ctx context.Context, query string, arg interface{}) (*model.Partner, error) {
+	var partner model.Partner
+	err := repo.DB.QueryRowContext(ctx, query, arg).Scan(&partner.ID, &partner.Name, &partner.Channel, &partner.APIKeyPrefix, &partner.APIKeyHash, &partner.SigningSecret, &partner.Active, &partner.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No partner found
+		}
+		return nil, err
+	}
+
+	return &partner, nil
+}
+
+// GetConsent returns the customer's consent for the partner or nil when it was never granted
+func (repo *MySQLPartnerRepository) GetConsent(ctx context.Context, customerID, partnerID int) (*model.PartnerConsent, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetConsent")
+	defer cancel()
+
+	query := "SELECT customer_id, partner_id, granted_at, revoked_at FROM partner_consent WHERE customer_id = ? AND partner_id = ?"
+
+	var consent model.PartnerConsent
+	var revokedAt sql.NullTime
+	err := repo.DB.QueryRowContext(ctx, query, customerID, partnerID).Scan(&consent.CustomerID, &consent.PartnerID, &consent.GrantedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No consent found
+		}
+		return nil, err
+	}
+	if revokedAt.Valid {
+		consent.RevokedAt = &revokedAt.Time
+	}
+
+	return &consent, nil
+}
+
+// GrantConsent grants consent, reinstating a previously revoked one
+func (repo *MySQLPartnerRepository) GrantConsent(ctx context.Context, consent *model.PartnerConsent) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GrantConsent")
+	defer cancel()
+
+	query := "INSERT INTO partner_consent (customer_id, partner_id, granted_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE granted_at = VALUES(granted_at), revoked_at = NULL"
+	_, err := repo.DB.ExecContext(ctx, query, consent.CustomerID, consent.PartnerID, consent.GrantedAt)
+	return err
+}
+
+// RevokeConsent revokes an active consent. It reports false when there was none to revoke.
+func (repo *MySQLPartnerRepository) RevokeConsent(ctx context.Context, customerID, partnerID int, revokedAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "RevokeConsent")
+	defer cancel()
+
+	query := "UPDATE partner_consent SET revoked_at = ? WHERE customer_id = ? AND partner_id = ? AND revoked_at IS NULL"
+	result, err := repo.DB.ExecContext(ctx, query, revokedAt, customerID, partnerID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RecordNonce remembers the nonce of a signed request of the partner until expiresAt. It reports
+// false when the partner already used the nonce and it has not expired, so every instance of the
+// API refuses the replay. The partner's expired nonces are deleted first, freeing them for reuse.
+func (repo *MySQLPartnerRepository) RecordNonce(ctx context.Context, partnerID int, nonce string, now, expiresAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "RecordNonce")
+	defer cancel()
+
+	_, err := repo.DB.ExecContext(ctx, "DELETE FROM partner_nonce WHERE partner_id = ? AND expires_at <= ?", partnerID, now)
+	if err != nil {
+		return false, err
+	}
+
+	query := "INSERT INTO partner_nonce (partner_id, nonce, expires_at) VALUES (?, ?, ?)"
+	_, err = repo.DB.ExecContext(ctx, query, partnerID, nonce, expiresAt)
+	if err != nil {
+		err = util.CheckMySQLError(err)
+		if errors.Is(err, util.ErrDuplicate) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordNonce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	repo := NewMySQLPartnerRepository(db, DefaultTimeouts())
+
+	now := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(10 * time.Minute)
+
+	t.Run("New nonce", func(t *testing.T) {
+		// Expired nonces of the partner are deleted before the new one is stored
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM partner_nonce WHERE partner_id = ? AND expires_at <= ?")).
+			WithArgs(3, now).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO partner_nonce (partner_id, nonce, expires_at) VALUES (?, ?, ?)")).
+			WithArgs(3, "once", expiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		recorded, err := repo.RecordNonce(context.Background(), 3, "once", now, expiresAt)
+
+		assert.NoError(t, err)
+		assert.True(t, recorded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Replayed nonce", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM partner_nonce")).
+			WithArgs(3, now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO partner_nonce")).
+			WithArgs(3, "once", expiresAt).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '3-once' for key 'PRIMARY'"})
+
+		recorded, err := repo.RecordNonce(context.Background(), 3, "once", now, expiresAt)
+
+		assert.NoError(t, err)
+		assert.False(t, recorded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PasswordResetRepository defines the interface for password reset token data access
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	ConsumePasswordResetToken(ctx context.Context, id int, usedAt time.Time) (bool, error)
+}
+
+// MySQLPasswordResetRepository is a repository implementation using MySQL
+type MySQLPasswordResetRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLPasswordResetRepository creates a new instance of MySQLPasswordResetRepository
+func NewMySQLPasswordResetRepository(db DBTX, timeouts Timeouts) *MySQLPasswordResetRepository {
+	return &MySQLPasswordResetRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// CreatePasswordResetToken stores a new password reset token
+func (repo *MySQLPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *model.PasswordResetToken) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreatePasswordResetToken")
+	defer cancel()
+
+	query := "INSERT INTO password_reset_token (customer_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, token.CustomerID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	token.ID = int(id)
+
+	return nil
+}
+
+// GetPasswordResetTokenByHash returns the token with the given hash or nil when it does not exist
+func (repo *MySQLPasswordResetRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetPasswordResetTokenByHash")
+	defer cancel()
+
+	query := "SELECT id, customer_id, token_hash, expires_at, used_at, created_at FROM password_reset_token WHERE token_hash = ?"
+
+	var token model.PasswordResetToken
+	var usedAt sql.NullTime
+	err := repo.DB.QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.CustomerID, &token.TokenHash, &token.ExpiresAt, &usedAt, &token.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No token found with the given hash
+		}
+		return nil, err
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+// ConsumePasswordResetToken marks a token as used. It reports false when the
+// token had already been used, so concurrent resets cannot both succeed.
+func (repo *MySQLPasswordResetRepository) ConsumePasswordResetToken(ctx context.Context, id int, usedAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ConsumePasswordResetToken")
+	defer cancel()
+
+	query := "UPDATE password_reset_token SET used_at = ? WHERE id = ? AND used_at IS NULL"
+	result, err := repo.DB.ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"time"
+)
+
+// PaymentRepository defines the interface for payment data access
+type PaymentRepository interface {
+	CreatePayment(ctx context.Context, payment *model.Payment) error
+	ListPaymentsByTransaction(ctx context.Context, transactionID int) ([]model.Payment, error)
+	ListPaymentsByCustomer(ctx context.Context, customerID int, before time.Time) ([]model.Payment, error)
+}
+
+// MySQLPaymentRepository is a repository implementation using MySQL
+type MySQLPaymentRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLPaymentRepository creates a new instance of MySQLPaymentRepository
+func NewMySQLPaymentRepository(db DBTX, timeouts Timeouts) *MySQLPaymentRepository {
+	return &MySQLPaymentRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+const paymentColumns = "payment.id, payment.transaction_id, payment.amount, payment.reference, payment.paid_at, payment.recorded_by, payment.created_at"
+
+// CreatePayment stores a payment received for a transaction
+func (repo *MySQLPaymentRepository) CreatePayment(ctx context.Context, payment *model.Payment) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreatePayment")
+	defer cancel()
+
+	query := "INSERT INTO payment (transaction_id, amount, reference, paid_at, recorded_by, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, payment.TransactionID, payment.Amount, payment.Reference, payment.PaidAt, payment.RecordedBy, payment.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	payment.ID = int(id)
+
+	return nil
+}
+
+// ListPaymentsByTransaction returns the payments of a transaction in the order they were made
+func (repo *MySQLPaymentRepository) ListPaymentsByTransaction(ctx context.Context, transactionID int) ([]model.Payment, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListPaymentsByTransaction")
+	defer cancel()
+
+	query := "SELECT " + paymentColumns + " FROM payment WHERE transaction_id = ? ORDER BY paid_at, id"
+	return repo.listPayments(ctx, query, transactionID)
+}
+
+// ListPaymentsByCustomer returns the payments made before the given time for any transaction of the customer
+func (repo *MySQLPaymentRepository) ListPaymentsByCustomer(ctx context.Context, customerID int, before time.Time) ([]model.Payment, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListPaymentsByCustomer")
+	defer cancel()
+
+	query := "SELECT " + paymentColumns + " FROM payment JOIN transaction ON transaction.id = payment.transaction_id WHERE transaction.customer_id = ? AND payment.paid_at < ? ORDER BY payment.paid_at, payment.id"
+	return repo.listPayments(ctx, query, customerID, before)
+}
+
+func (repo *MySQLPaymentRepository) listPayments(ctx context.Context, query string, args ...interface{}) ([]model.Payment, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []model.Payment{}
+	for rows.Next() {
+		var payment model.Payment
+		err := rows.Scan(&payment.ID, &payment.TransactionID, &payment.Amount, &payment.Reference, &payment.PaidAt, &payment.RecordedBy, &payment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
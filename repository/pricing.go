@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PricingRuleRepository defines the interface for pricing rule data access
+type PricingRuleRepository interface {
+	CreatePricingRule(ctx context.Context, rule *model.PricingRule) error
+	ListPricingRules(ctx context.Context, code string) ([]model.PricingRule, error)
+	ListEffectivePricingRules(ctx context.Context, at time.Time) ([]model.PricingRule, error)
+}
+
+// MySQLPricingRuleRepository is a repository implementation using MySQL
+type MySQLPricingRuleRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLPricingRuleRepository creates a new instance of MySQLPricingRuleRepository
+func NewMySQLPricingRuleRepository(db DBTX, timeouts Timeouts) *MySQLPricingRuleRepository {
+	return &MySQLPricingRuleRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+const pricingRuleColumns = "id, code, version, description, tenor, asset_category, partner_id, risk_grade, promo_code, priority, admin_fee, admin_fee_percent, monthly_interest_percent, effective_from, effective_to, created_at"
+
+// CreatePricingRule stores the next version of the rule's code. Earlier versions of the
+// same code stop being effective when the new version takes effect.
+func (repo *MySQLPricingRuleRepository) CreatePricingRule(ctx context.Context, rule *model.PricingRule) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreatePricingRule")
+	defer cancel()
+
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		// Locking the existing versions serialises concurrent updates of the same code
+		err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) + 1 FROM pricing_rule WHERE code = ? FOR UPDATE", rule.Code).Scan(&rule.Version)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE pricing_rule SET effective_to = ? WHERE code = ? AND (effective_to IS NULL OR effective_to > ?)",
+			rule.EffectiveFrom, rule.Code, rule.EffectiveFrom)
+		if err != nil {
+			return err
+		}
+
+		query := "INSERT INTO pricing_rule (code, version, description, tenor, asset_category, partner_id, risk_grade, promo_code, priority, admin_fee, admin_fee_percent, monthly_interest_percent, effective_from, effective_to, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		result, err := tx.ExecContext(ctx, query, rule.Code, rule.Version, rule.Description, rule.Tenor, rule.AssetCategory, rule.PartnerID, rule.RiskGrade, rule.PromoCode, rule.Priority, rule.AdminFee, rule.AdminFeePercent, rule.MonthlyInterestPercent, rule.EffectiveFrom, rule.EffectiveTo, rule.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		rule.ID = int(id)
+
+		return nil
+	})
+}
+
+// ListPricingRules returns every version of the rules, or of one code when code is not empty
+func (repo *MySQLPricingRuleRepository) ListPricingRules(ctx context.Context, code string) ([]model.PricingRule, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListPricingRules")
+	defer cancel()
+
+	query := "SELECT " + pricingRuleColumns + " FROM pricing_rule WHERE ? = '' OR code = ? ORDER BY code, version"
+	return repo.listPricingRules(ctx, query, code, code)
+}
+
+// ListEffectivePricingRules returns the rules in effect at the given time
+func (repo *MySQLPricingRuleRepository) ListEffectivePricingRules(ctx context.Context, at time.Time) ([]model.PricingRule, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListEffectivePricingRules")
+	defer cancel()
+
+	query := "SELECT " + pricingRuleColumns + " FROM pricing_rule WHERE effective_from <= ? AND (effective_to IS NULL OR effective_to > ?) ORDER BY id"
+	return repo.listPricingRules(ctx, query, at, at)
+}
+
+func (repo *MySQLPricingRuleRepository) listPricingRules(ctx context.Context, query string, args ...interface{}) ([]model.PricingRule, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.PricingRule{}
+	for rows.Next() {
+		rule, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func scanPricingRule(row rowScanner) (*model.PricingRule, error) {
+	var rule model.PricingRule
+	var partnerID sql.NullInt64
+	var effectiveTo sql.NullTime
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Code,
+		&rule.Version,
+		&rule.Description,
+		&rule.Tenor,
+		&rule.AssetCategory,
+		&partnerID,
+		&rule.RiskGrade,
+		&rule.PromoCode,
+		&rule.Priority,
+		&rule.AdminFee,
+		&rule.AdminFeePercent,
+		&rule.MonthlyInterestPercent,
+		&rule.EffectiveFrom,
+		&effectiveTo,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if partnerID.Valid {
+		id := int(partnerID.Int64)
+		rule.PartnerID = &id
+	}
+	if effectiveTo.Valid {
+		rule.EffectiveTo = &effectiveTo.Time
+	}
+
+	return &rule, nil
+}
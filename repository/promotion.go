@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Reasons RedeemVoucher refuses a voucher, checked while the campaign row is locked
+var (
+	ErrVoucherUnavailable = errors.New("voucher campaign is inactive or exhausted")
+	ErrVoucherAlreadyUsed = errors.New("voucher was already redeemed by the customer")
+)
+
+// PromotionRepository defines the interface for campaign and voucher redemption data access
+type PromotionRepository interface {
+	CreateCampaign(ctx context.Context, campaign *model.Campaign) error
+	ListCampaigns(ctx context.Context) ([]model.Campaign, error)
+	GetCampaignByCode(ctx context.Context, code string) (*model.Campaign, error)
+	DeactivateCampaign(ctx context.Context, id int) (bool, error)
+	HasRedeemed(ctx context.Context, campaignID, customerID int, at time.Time) (bool, error)
+	RedeemVoucher(ctx context.Context, redemption *model.PromoRedemption) error
+}
+
+// MySQLPromotionRepository is a repository implementation using MySQL
+type MySQLPromotionRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLPromotionRepository creates a new instance of MySQLPromotionRepository
+func NewMySQLPromotionRepository(db DBTX, timeouts Timeouts) *MySQLPromotionRepository {
+	return &MySQLPromotionRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+const campaignColumns = "id, code, name, admin_fee_discount_percent, interest_discount_percent, tenor, asset_category, channel, min_financed_amount, starts_at, ends_at, budget, budget_used, max_redemptions, redemptions, active, created_at"
+
+// CreateCampaign stores a new campaign
+func (repo *MySQLPromotionRepository) CreateCampaign(ctx context.Context, campaign *model.Campaign) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateCampaign")
+	defer cancel()
+
+	query := "INSERT INTO promo_campaign (code, name, admin_fee_discount_percent, interest_discount_percent, tenor, asset_category, channel, min_financed_amount, starts_at, ends_at, budget, max_redemptions, active, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, campaign.Code, campaign.Name, campaign.AdminFeeDiscountPercent, campaign.InterestDiscountPercent, campaign.Tenor, campaign.AssetCategory, campaign.Channel, campaign.MinFinancedAmount, campaign.StartsAt, campaign.EndsAt, campaign.Budget, campaign.MaxRedemptions, campaign.Active, campaign.CreatedAt)
+	if err != nil {
+		return util.CheckMySQLError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	campaign.ID = int(id)
+
+	return nil
+}
+
+// ListCampaigns returns every campaign, newest first
+func (repo *MySQLPromotionRepository) ListCampaigns(ctx context.Context) ([]model.Campaign, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListCampaigns")
+	defer cancel()
+
+	rows, err := repo.DB.QueryContext(ctx, "SELECT "+campaignColumns+" FROM promo_campaign ORDER BY id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []model.Campaign{}
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, *campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// GetCampaignByCode returns the campaign with the given voucher code or nil when it does not exist
+func (repo *MySQLPromotionRepository) GetCampaignByCode(ctx context.Context, code string) (*model.Campaign, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetCampaignByCode")
+	defer cancel()
+
+	query := "SELECT " + campaignColumns + " FROM promo_campaign WHERE code = ?"
+
+	campaign, err := scanCampaign(repo.DB.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No campaign found with the given code
+		}
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// DeactivateCampaign stops a campaign from accepting vouchers. It reports false when the campaign does not exist.
+func (repo *MySQLPromotionRepository) DeactivateCampaign(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "DeactivateCampaign")
+	defer cancel()
+
+	result, err := repo.DB.ExecContext(ctx, "UPDATE promo_campaign SET active = FALSE WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// HasRedeemed reports whether the customer holds a redemption of the campaign that was not reversed.
+// Redemptions of pending transactions whose confirmation code expired before at are not held, as
+// RedeemVoucher releases them.
+func (repo *MySQLPromotionRepository) HasRedeemed(ctx context.Context, campaignID, customerID int, at time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "HasRedeemed")
+	defer cancel()
+
+	query := "SELECT COUNT(*) FROM promo_redemption JOIN transaction ON transaction.id = promo_redemption.transaction_id WHERE promo_redemption.campaign_id = ? AND promo_redemption.customer_id = ? AND promo_redemption.reversed_at IS NULL AND NOT (transaction.status = ? AND transaction.otp_expires_at < ?)"
+
+	var count int
+	err := repo.DB.QueryRowContext(ctx, query, campaignID, customerID, model.TransactionPending, at).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RedeemVoucher records the redemption of a voucher for the transaction it names and charges the
+// campaign's caps. The campaign row stays locked until the unit of work the repository is bound to
+// ends, so concurrent redemptions cannot overspend the budget or use a voucher twice. Pending
+// transactions of the campaign whose confirmation code expired can no longer be booked, so they
+// are cancelled first and give their vouchers back.
+func (repo *MySQLPromotionRepository) RedeemVoucher(ctx context.Context, redemption *model.PromoRedemption) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "RedeemVoucher")
+	defer cancel()
+
+	err := releaseExpiredRedemptions(ctx, repo.DB, redemption.CampaignID, redemption.RedeemedAt)
+	if err != nil {
+		return err
+	}
+
+	var active bool
+	var budget, budgetUsed float64
+	var maxRedemptions, redemptions int
+	query := "SELECT active, budget, budget_used, max_redemptions, redemptions FROM promo_campaign WHERE id = ? FOR UPDATE"
+	err = repo.DB.QueryRowContext(ctx, query, redemption.CampaignID).Scan(&active, &budget, &budgetUsed, &maxRedemptions, &redemptions)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrVoucherUnavailable
+		}
+		return err
+	}
+	if !active ||
+		(maxRedemptions > 0 && redemptions >= maxRedemptions) ||
+		(budget > 0 && budgetUsed+redemption.DiscountAmount > budget) {
+		return ErrVoucherUnavailable
+	}
+
+	var used int
+	query = "SELECT COUNT(*) FROM promo_redemption WHERE campaign_id = ? AND customer_id = ? AND reversed_at IS NULL"
+	err = repo.DB.QueryRowContext(ctx, query, redemption.CampaignID, redemption.CustomerID).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrVoucherAlreadyUsed
+	}
+
+	query = "INSERT INTO promo_redemption (campaign_id, customer_id, transaction_id, discount_amount, redeemed_at) VALUES (?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, redemption.CampaignID, redemption.CustomerID, redemption.TransactionID, redemption.DiscountAmount, redemption.RedeemedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	redemption.ID = int(id)
+
+	query = "UPDATE promo_campaign SET redemptions = redemptions + 1, budget_used = budget_used + ? WHERE id = ?"
+	_, err = repo.DB.ExecContext(ctx, query, redemption.DiscountAmount, redemption.CampaignID)
+	return err
+}
+
+// releaseExpiredRedemptions cancels the pending transactions holding a voucher of the campaign
+// whose confirmation code expired before now, reversing their redemptions
+func releaseExpiredRedemptions(ctx context.Context, tx DBTX, campaignID int, now time.Time) error {
+	query := "SELECT promo_redemption.transaction_id FROM promo_redemption JOIN transaction ON transaction.id = promo_redemption.transaction_id WHERE promo_redemption.campaign_id = ? AND promo_redemption.reversed_at IS NULL AND transaction.status = ? AND transaction.otp_expires_at < ? FOR UPDATE"
+	rows, err := tx.QueryContext(ctx, query, campaignID, model.TransactionPending, now)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var transactionIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		transactionIDs = append(transactionIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, id := range transactionIDs {
+		query = "UPDATE transaction SET status = ?, otp_hash = NULL, cancelled_at = ? WHERE id = ? AND status = ?"
+		result, err := tx.ExecContext(ctx, query, model.TransactionCancelled, now, id, model.TransactionPending)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			continue // Booked or cancelled meanwhile
+		}
+
+		err = reverseRedemption(ctx, tx, id, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reverseRedemption reverses the voucher redemption of a cancelled transaction, if it had one,
+// and returns its discount to the campaign budget
+func reverseRedemption(ctx context.Context, tx DBTX, transactionID int, reversedAt time.Time) error {
+	var id, campaignID int
+	var discountAmount float64
+	query := "SELECT id, campaign_id, discount_amount FROM promo_redemption WHERE transaction_id = ? AND reversed_at IS NULL FOR UPDATE"
+	err := tx.QueryRowContext(ctx, query, transactionID).Scan(&id, &campaignID, &discountAmount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil // No voucher was redeemed for the transaction
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE promo_redemption SET reversed_at = ? WHERE id = ?", reversedAt, id)
+	if err != nil {
+		return err
+	}
+
+	query = "UPDATE promo_campaign SET redemptions = redemptions - 1, budget_used = budget_used - ? WHERE id = ?"
+	_, err = tx.ExecContext(ctx, query, discountAmount, campaignID)
+	return err
+}
+
+func scanCampaign(row rowScanner) (*model.Campaign, error) {
+	var campaign model.Campaign
+	var endsAt sql.NullTime
+
+	err := row.Scan(
+		&campaign.ID,
+		&campaign.Code,
+		&campaign.Name,
+		&campaign.AdminFeeDiscountPercent,
+		&campaign.InterestDiscountPercent,
+		&campaign.Tenor,
+		&campaign.AssetCategory,
+		&campaign.Channel,
+		&campaign.MinFinancedAmount,
+		&campaign.StartsAt,
+		&endsAt,
+		&campaign.Budget,
+		&campaign.BudgetUsed,
+		&campaign.MaxRedemptions,
+		&campaign.Redemptions,
+		&campaign.Active,
+		&campaign.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if endsAt.Valid {
+		campaign.EndsAt = &endsAt.Time
+	}
+
+	return &campaign, nil
+}
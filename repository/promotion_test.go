@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"alif-sigmatech/model"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedeemVoucherReleasesExpiredRedemptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	repo := NewMySQLPromotionRepository(db, DefaultTimeouts())
+
+	now := time.Date(2026, 8, 1, 10, 0, 0, 0, time.UTC)
+	redemption := &model.PromoRedemption{CampaignID: 7, CustomerID: 1, TransactionID: 6, DiscountAmount: 50000, RedeemedAt: now}
+
+	// The customer's earlier transaction was never confirmed and its code expired
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT promo_redemption.transaction_id FROM promo_redemption JOIN transaction")).
+		WithArgs(7, model.TransactionPending, now).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}).AddRow(5))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE transaction SET status = ?, otp_hash = NULL, cancelled_at = ? WHERE id = ? AND status = ?")).
+		WithArgs(model.TransactionCancelled, now, 5, model.TransactionPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, campaign_id, discount_amount FROM promo_redemption WHERE transaction_id = ?")).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "campaign_id", "discount_amount"}).AddRow(3, 7, 50000))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE promo_redemption SET reversed_at = ? WHERE id = ?")).
+		WithArgs(now, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE promo_campaign SET redemptions = redemptions - 1")).
+		WithArgs(50000.0, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// With the reservation released the campaign has room and the customer no redemption left
+	mock.ExpectQuery(regexp.QuoteMeta("FROM promo_campaign WHERE id = ? FOR UPDATE")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"active", "budget", "budget_used", "max_redemptions", "redemptions"}).AddRow(true, 0, 0, 1, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM promo_redemption WHERE campaign_id = ? AND customer_id = ?")).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO promo_redemption")).
+		WithArgs(7, 1, 6, 50000.0, now).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE promo_campaign SET redemptions = redemptions + 1")).
+		WithArgs(50000.0, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.RedeemVoucher(context.Background(), redemption)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, redemption.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
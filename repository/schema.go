@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// SchemaVersion is the version of database.sql the repositories are written against. Bump it
+// with every change to the schema, which ships twice: in database.sql for new databases, seeding
+// the new version into schema_migrations, and as migrations/NNNN_description.sql numbered with
+// the new version for existing ones, recording it with INSERT ... ON DUPLICATE KEY UPDATE.
+const SchemaVersion = 19
+
+// SchemaRepository defines the interface for checking the database the repositories run against
+type SchemaRepository interface {
+	Ping(ctx context.Context) error
+	CheckSchemaVersion(ctx context.Context) error
+}
+
+// MySQLSchemaRepository is a repository implementation using MySQL
+type MySQLSchemaRepository struct {
+	DB       DBTX
+	Timeouts Timeouts
+}
+
+// NewMySQLSchemaRepository creates a new instance of MySQLSchemaRepository
+func NewMySQLSchemaRepository(db DBTX, timeouts Timeouts) *MySQLSchemaRepository {
+	return &MySQLSchemaRepository{
+		DB:       db,
+		Timeouts: timeouts,
+	}
+}
+
+// Ping checks that the database answers a query
+func (repo *MySQLSchemaRepository) Ping(ctx context.Context) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "Ping")
+	defer cancel()
+
+	var one int
+	return repo.DB.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// CheckSchemaVersion reports an error unless the latest migration applied is SchemaVersion
+func (repo *MySQLSchemaRepository) CheckSchemaVersion(ctx context.Context) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CheckSchemaVersion")
+	defer cancel()
+
+	query := "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"
+
+	var version int
+	err := repo.DB.QueryRowContext(ctx, query).Scan(&version)
+	if err != nil {
+		return err
+	}
+	if version != SchemaVersion {
+		return fmt.Errorf("database schema is at version %d, expected %d", version, SchemaVersion)
+	}
+	return nil
+}
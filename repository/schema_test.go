@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// schemaMigrationsVersion is the migration creating schema_migrations
+const schemaMigrationsVersion = 16
+
+// TestSchemaVersion keeps SchemaVersion, the version seeded by database.sql and the latest
+// migration in step, so a schema change cannot forget one of them. Migrations older than
+// schemaMigrationsVersion ran before schema_migrations existed and are recorded by it.
+func TestSchemaVersion(t *testing.T) {
+	schema, err := os.ReadFile("../database.sql")
+	assert.NoError(t, err)
+	seeded := regexp.MustCompile(`INSERT INTO schema_migrations \(version\) VALUES \((\d+)\)`).FindSubmatch(schema)
+	if assert.NotNil(t, seeded, "database.sql does not seed schema_migrations") {
+		assert.Equal(t, strconv.Itoa(SchemaVersion), string(seeded[1]))
+	}
+
+	files, err := filepath.Glob("../migrations/*.sql")
+	assert.NoError(t, err)
+	latest := 0
+	for _, file := range files {
+		version, err := strconv.Atoi(regexp.MustCompile(`^\d+`).FindString(filepath.Base(file)))
+		if !assert.NoError(t, err, "migration %s is not numbered", file) {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+
+		if version < schemaMigrationsVersion {
+			continue
+		}
+		migration, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Regexp(t, `INSERT INTO schema_migrations \(version\)\s+VALUES [^;]*\(`+strconv.Itoa(version)+`\)\s+ON DUPLICATE KEY UPDATE`,
+			string(migration), "migration %s does not record its version", file)
+	}
+	assert.Equal(t, SchemaVersion, latest)
+}
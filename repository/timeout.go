@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Timeouts bounds how long a single repository operation may run against the database.
+// Operations overrides Default for individual methods, keyed by the method name (e.g. "ListTransactions").
+// A zero timeout leaves the operation bound only by the caller's context.
+type Timeouts struct {
+	Default    time.Duration
+	Operations map[string]time.Duration
+}
+
+// DefaultTimeouts returns the timeouts used when nothing is configured
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Default: 5 * time.Second,
+		Operations: map[string]time.Duration{
+			// Listing scans an index range and may read up to a full page of rows
+			"ListTransactions":       10 * time.Second,
+			"ListPaymentsByCustomer": 10 * time.Second,
+		},
+	}
+}
+
+// IsOperation reports whether name is an operation Timeouts applies to, i.e. a method of one of
+// the repositories, so a misspelt name in the configuration does not go unnoticed
+func IsOperation(name string) bool {
+	repositories := reflect.TypeOf(Repositories{})
+	for i := 0; i < repositories.NumField(); i++ {
+		if _, ok := repositories.Field(i).Type.MethodByName(name); ok {
+			return true
+		}
+	}
+	_, ok := reflect.TypeOf((*SchemaRepository)(nil)).Elem().MethodByName(name)
+	return ok
+}
+
+// For returns the timeout of the named operation
+func (t Timeouts) For(operation string) time.Duration {
+	if timeout, ok := t.Operations[operation]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// withTimeout derives the context the named operation runs with. The caller's deadline still applies when it is earlier.
+func (t Timeouts) withTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	timeout := t.For(operation)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
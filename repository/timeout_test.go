@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEveryOperationIsKnown keeps the operation names the repositories time out under in step
+// with IsOperation, which the configuration checks DB_OPERATION_TIMEOUTS against
+func TestEveryOperationIsKnown(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	assert.NoError(t, err)
+	operation := regexp.MustCompile(`withTimeout\(ctx, "(\w+)"\)`)
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		for _, match := range operation.FindAllSubmatch(source, -1) {
+			assert.True(t, IsOperation(string(match[1])), "%s times out %s, which IsOperation does not know", file, match[1])
+		}
+	}
+
+	assert.False(t, IsOperation("ListTransaction"))
+	assert.False(t, IsOperation("Do"))
+}
@@ -2,26 +2,332 @@ package repository
 
 import (
 	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 )
 
 type TransactionRepository interface {
-	CreateTransaction(transaction *model.Transaction) error
+	CreateTransaction(ctx context.Context, transaction *model.Transaction) error
+	GetTransactionByID(ctx context.Context, id int) (*model.Transaction, error)
+	GetTransactionByIDForUpdate(ctx context.Context, id int) (*model.Transaction, error)
+	GetTransactionByContractNumber(ctx context.Context, customerID int, contractNumber string) (*model.Transaction, error)
+	ReserveOTPAttempt(ctx context.Context, id int, maxAttempts int) (bool, error)
+	ConfirmTransaction(ctx context.Context, id int, confirmedAt time.Time) (bool, error)
+	CancelTransaction(ctx context.Context, id int, cancellableStatuses []string, cancelledAt time.Time) (bool, error)
+	ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error)
 }
 
 type MySQLTransactionRepository struct {
-	DB *sql.DB
+	DB       DBTX
+	Timeouts Timeouts
 }
 
 // NewMySQLCustomerRepository creates a new instance of MySQLTransactionRepository
-func NewMySQLTransactionRepository(db *sql.DB) *MySQLTransactionRepository {
+func NewMySQLTransactionRepository(db DBTX, timeouts Timeouts) *MySQLTransactionRepository {
 	return &MySQLTransactionRepository{
-		DB: db,
+		DB:       db,
+		Timeouts: timeouts,
 	}
 }
 
-func (repo *MySQLTransactionRepository) CreateTransaction(transaction *model.Transaction) error {
-	query := "INSERT INTO transaction (customer_id, contract_number, otr, admin_fee, installment_amount, interest_amount, asset_name, tenor) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
-	_, err := repo.DB.Exec(query, transaction.CustomerID, transaction.ContractNumber, transaction.OTR, transaction.AdminFee, transaction.InstallmentAmount, transaction.InterestAmount, transaction.AssetName, transaction.Tenor)
-	return err
+const transactionColumns = "id, customer_id, contract_number, otr, admin_fee, installment_amount, interest_amount, asset_id, asset_name, down_payment, tenor, status, otp_hash, otp_attempts, otp_expires_at, confirmed_at, cancelled_at, channel, partner_id, promo_code, discount_amount, pricing_rule_id, pricing_rule_version, created_at"
+
+func (repo *MySQLTransactionRepository) CreateTransaction(ctx context.Context, transaction *model.Transaction) error {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CreateTransaction")
+	defer cancel()
+
+	query := "INSERT INTO transaction (customer_id, contract_number, otr, admin_fee, installment_amount, interest_amount, asset_id, asset_name, down_payment, tenor, status, otp_hash, otp_expires_at, channel, partner_id, promo_code, discount_amount, pricing_rule_id, pricing_rule_version, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := repo.DB.ExecContext(ctx, query, transaction.CustomerID, transaction.ContractNumber, transaction.OTR, transaction.AdminFee, transaction.InstallmentAmount, transaction.InterestAmount, transaction.AssetID, transaction.AssetName, transaction.DownPayment, transaction.Tenor, transaction.Status, transaction.OTPHash, transaction.OTPExpiresAt, transaction.Channel, transaction.PartnerID, transaction.PromoCode, transaction.DiscountAmount, transaction.PricingRuleID, transaction.PricingRuleVersion, transaction.CreatedAt)
+	if err != nil {
+		return util.CheckMySQLError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	transaction.ID = int(id)
+
+	return nil
+}
+
+// GetTransactionByID returns the transaction with the given ID or nil when it does not exist
+func (repo *MySQLTransactionRepository) GetTransactionByID(ctx context.Context, id int) (*model.Transaction, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetTransactionByID")
+	defer cancel()
+
+	query := "SELECT " + transactionColumns + " FROM transaction WHERE id = ?"
+	return repo.getTransaction(ctx, query, id)
+}
+
+// GetTransactionByIDForUpdate reads the transaction and locks it until the unit of work the
+// repository is bound to ends, so what depends on it, such as its payments, cannot change meanwhile
+func (repo *MySQLTransactionRepository) GetTransactionByIDForUpdate(ctx context.Context, id int) (*model.Transaction, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetTransactionByIDForUpdate")
+	defer cancel()
+
+	query := "SELECT " + transactionColumns + " FROM transaction WHERE id = ? FOR UPDATE"
+	return repo.getTransaction(ctx, query, id)
+}
+
+func (repo *MySQLTransactionRepository) getTransaction(ctx context.Context, query string, args ...interface{}) (*model.Transaction, error) {
+	transaction, err := scanTransaction(repo.DB.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No transaction found
+		}
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// GetTransactionByContractNumber returns the customer's transaction with the given contract number
+// or nil when there is none
+func (repo *MySQLTransactionRepository) GetTransactionByContractNumber(ctx context.Context, customerID int, contractNumber string) (*model.Transaction, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "GetTransactionByContractNumber")
+	defer cancel()
+
+	query := "SELECT " + transactionColumns + " FROM transaction WHERE customer_id = ? AND contract_number = ?"
+
+	transaction, err := scanTransaction(repo.DB.QueryRowContext(ctx, query, customerID, contractNumber))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No transaction found with the given contract number
+		}
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// ReserveOTPAttempt counts a confirmation attempt before the code is checked. It reports
+// false once maxAttempts have been used, so parallel guesses cannot exceed the limit.
+func (repo *MySQLTransactionRepository) ReserveOTPAttempt(ctx context.Context, id int, maxAttempts int) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ReserveOTPAttempt")
+	defer cancel()
+
+	query := "UPDATE transaction SET otp_attempts = otp_attempts + 1 WHERE id = ? AND status = ? AND otp_attempts < ?"
+	result, err := repo.DB.ExecContext(ctx, query, id, model.TransactionPending, maxAttempts)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// ConfirmTransaction books a pending transaction. It reports false when the
+// transaction was no longer pending, so it cannot be confirmed twice.
+func (repo *MySQLTransactionRepository) ConfirmTransaction(ctx context.Context, id int, confirmedAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ConfirmTransaction")
+	defer cancel()
+
+	query := "UPDATE transaction SET status = ?, otp_hash = NULL, confirmed_at = ? WHERE id = ? AND status = ?"
+	result, err := repo.DB.ExecContext(ctx, query, model.TransactionConfirmed, confirmedAt, id, model.TransactionPending)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// CancelTransaction cancels a transaction in one of the given statuses and reverses its voucher
+// redemption in the same database transaction. It reports false when the transaction was not
+// in a cancellable status.
+func (repo *MySQLTransactionRepository) CancelTransaction(ctx context.Context, id int, cancellableStatuses []string, cancelledAt time.Time) (bool, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "CancelTransaction")
+	defer cancel()
+
+	args := []interface{}{model.TransactionCancelled, cancelledAt, id}
+	for _, status := range cancellableStatuses {
+		args = append(args, status)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(cancellableStatuses)), ", ")
+
+	cancelled := false
+	err := inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		query := "UPDATE transaction SET status = ?, otp_hash = NULL, cancelled_at = ? WHERE id = ? AND status IN (" + placeholders + ")"
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected != 1 {
+			return nil
+		}
+
+		cancelled = true
+		return reverseRedemption(ctx, tx, id, cancelledAt)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return cancelled, nil
+}
+
+// transactionSortColumns whitelists the columns ListTransactions sorts by
+var transactionSortColumns = map[string]string{
+	model.TransactionSortCreatedAt:         "created_at",
+	model.TransactionSortOTR:               "otr",
+	model.TransactionSortInstallmentAmount: "installment_amount",
+}
+
+// ListTransactions returns at most filter.Limit transactions matching the filter, sorted by the
+// filter's column with the ID breaking ties. Pages are read with a cursor rather than an offset,
+// so rows inserted meanwhile neither shift nor repeat the following pages.
+func (repo *MySQLTransactionRepository) ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+	ctx, cancel := repo.Timeouts.withTimeout(ctx, "ListTransactions")
+	defer cancel()
+
+	column, ok := transactionSortColumns[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported transaction sort %q", filter.Sort)
+	}
+
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, values ...interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, values...)
+	}
+
+	if filter.CustomerID != 0 {
+		where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.Status != "" {
+		where("status = ?", filter.Status)
+	}
+	if filter.Tenor != 0 {
+		where("tenor = ?", filter.Tenor)
+	}
+	if filter.AssetID != 0 {
+		where("asset_id = ?", filter.AssetID)
+	}
+	if filter.PartnerID != 0 {
+		where("partner_id = ?", filter.PartnerID)
+	}
+	if filter.CreatedFrom != nil {
+		where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		where("created_at < ?", *filter.CreatedTo)
+	}
+	if filter.MinOTR != 0 {
+		where("otr >= ?", filter.MinOTR)
+	}
+	if filter.MaxOTR != 0 {
+		where("otr <= ?", filter.MaxOTR)
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, comparison, column, comparison),
+			filter.After.Value, filter.After.Value, filter.After.ID)
+	}
+
+	query := "SELECT " + transactionColumns + " FROM transaction"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT ?", column, direction, direction)
+	args = append(args, filter.Limit)
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []model.Transaction{}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+func scanTransaction(row rowScanner) (*model.Transaction, error) {
+	var transaction model.Transaction
+	var otpHash sql.NullString
+	var otpExpiresAt, confirmedAt, cancelledAt sql.NullTime
+	var assetID, partnerID, pricingRuleID, pricingRuleVersion sql.NullInt64
+
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.CustomerID,
+		&transaction.ContractNumber,
+		&transaction.OTR,
+		&transaction.AdminFee,
+		&transaction.InstallmentAmount,
+		&transaction.InterestAmount,
+		&assetID,
+		&transaction.AssetName,
+		&transaction.DownPayment,
+		&transaction.Tenor,
+		&transaction.Status,
+		&otpHash,
+		&transaction.OTPAttempts,
+		&otpExpiresAt,
+		&confirmedAt,
+		&cancelledAt,
+		&transaction.Channel,
+		&partnerID,
+		&transaction.PromoCode,
+		&transaction.DiscountAmount,
+		&pricingRuleID,
+		&pricingRuleVersion,
+		&transaction.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.AssetID = int(assetID.Int64)
+	transaction.OTPHash = otpHash.String
+	if otpExpiresAt.Valid {
+		transaction.OTPExpiresAt = &otpExpiresAt.Time
+	}
+	if confirmedAt.Valid {
+		transaction.ConfirmedAt = &confirmedAt.Time
+	}
+	if cancelledAt.Valid {
+		transaction.CancelledAt = &cancelledAt.Time
+	}
+	if partnerID.Valid {
+		id := int(partnerID.Int64)
+		transaction.PartnerID = &id
+	}
+	if pricingRuleID.Valid {
+		id := int(pricingRuleID.Int64)
+		transaction.PricingRuleID = &id
+	}
+	transaction.PricingRuleVersion = int(pricingRuleVersion.Int64)
+
+	return &transaction, nil
 }
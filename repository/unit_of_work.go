@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DBTX is implemented by both *sql.DB and the transaction of a unit of work, so repositories
+// run their statements either on their own or as part of a unit of work
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Repositories groups the repositories a unit of work hands to its function
+type Repositories struct {
+	Customers      CustomerRepository
+	Limits         LimitRepository
+	Transactions   TransactionRepository
+	Documents      DocumentRepository
+	Corrections    CorrectionRepository
+	PasswordResets PasswordResetRepository
+	LoginAttempts  LoginAttemptRepository
+	MFA            MFARepository
+	Partners       PartnerRepository
+	Assets         AssetRepository
+	PricingRules   PricingRuleRepository
+	Promotions     PromotionRepository
+	Payments       PaymentRepository
+	Contracts      ContractDocumentRepository
+}
+
+// NewRepositories creates the MySQL repositories running their statements on db
+func NewRepositories(db DBTX, timeouts Timeouts) Repositories {
+	return Repositories{
+		Customers:      NewMySQLCustomerRepository(db, timeouts),
+		Limits:         NewMySQLLimitRepository(db, timeouts),
+		Transactions:   NewMySQLTransactionRepository(db, timeouts),
+		Documents:      NewMySQLDocumentRepository(db, timeouts),
+		Corrections:    NewMySQLCorrectionRepository(db, timeouts),
+		PasswordResets: NewMySQLPasswordResetRepository(db, timeouts),
+		LoginAttempts:  NewMySQLLoginAttemptRepository(db, timeouts),
+		MFA:            NewMySQLMFARepository(db, timeouts),
+		Partners:       NewMySQLPartnerRepository(db, timeouts),
+		Assets:         NewMySQLAssetRepository(db, timeouts),
+		PricingRules:   NewMySQLPricingRuleRepository(db, timeouts),
+		Promotions:     NewMySQLPromotionRepository(db, timeouts),
+		Payments:       NewMySQLPaymentRepository(db, timeouts),
+		Contracts:      NewMySQLContractDocumentRepository(db, timeouts),
+	}
+}
+
+// UnitOfWork runs a function with repositories bound to one database transaction. The transaction
+// is committed when the function returns nil and rolled back otherwise. A unit of work started with
+// the context of a running one joins it: only the outermost unit commits, and an error of the inner
+// function undoes the inner function's work alone.
+//
+// The function may run more than once when the database aborts the transaction to resolve a
+// deadlock, so it must not have effects outside the database that cannot be repeated.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+// MySQLUnitOfWork is a unit of work implementation using MySQL
+type MySQLUnitOfWork struct {
+	DB       *sql.DB
+	Timeouts Timeouts
+	// MaxRetries is how often a transaction aborted by a deadlock or lock wait timeout is run again
+	MaxRetries int
+	// RetryDelay is the wait before the first retry; every further retry waits one delay longer
+	RetryDelay time.Duration
+}
+
+// NewMySQLUnitOfWork creates a new instance of MySQLUnitOfWork
+func NewMySQLUnitOfWork(db *sql.DB, timeouts Timeouts, maxRetries int, retryDelay time.Duration) *MySQLUnitOfWork {
+	return &MySQLUnitOfWork{
+		DB:         db,
+		Timeouts:   timeouts,
+		MaxRetries: maxRetries,
+		RetryDelay: retryDelay,
+	}
+}
+
+// unitOfWorkKey is the context key of the running unit of work's transaction
+type unitOfWorkKey struct{}
+
+// Do runs fn in a database transaction, or in the running unit of work's transaction when ctx carries one
+func (u *MySQLUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	if tx, ok := ctx.Value(unitOfWorkKey{}).(*unitTx); ok {
+		return tx.savepoint(ctx, func() error {
+			return fn(ctx, tx.repos)
+		})
+	}
+
+	return retry(ctx, u.MaxRetries, u.RetryDelay, func() error {
+		return u.run(ctx, fn)
+	})
+}
+
+func (u *MySQLUnitOfWork) run(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	sqlTx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer sqlTx.Rollback()
+
+	tx := &unitTx{Tx: sqlTx}
+	tx.repos = NewRepositories(tx, u.Timeouts)
+
+	err = fn(context.WithValue(ctx, unitOfWorkKey{}, tx), tx.repos)
+	if err != nil {
+		return err
+	}
+
+	return sqlTx.Commit()
+}
+
+// unitTx is the transaction of a unit of work. Like *sql.Tx it must not be used concurrently.
+type unitTx struct {
+	*sql.Tx
+	repos      Repositories
+	savepoints int
+}
+
+// savepoint runs fn and rolls the transaction back to where it was before fn when fn fails
+func (tx *unitTx) savepoint(ctx context.Context, fn func() error) error {
+	tx.savepoints++
+	name := fmt.Sprintf("unit_of_work_%d", tx.savepoints)
+
+	_, err := tx.ExecContext(ctx, "SAVEPOINT "+name)
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err != nil {
+		// Deadlocks abort the whole transaction and take the savepoint with them, so this may fail as well
+		tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// inTransaction runs fn in a database transaction of its own. A repository bound to a unit of work
+// runs fn in the unit's transaction instead, behind a savepoint so a failing fn leaves no partial writes.
+func inTransaction(ctx context.Context, db DBTX, fn func(tx DBTX) error) error {
+	switch db := db.(type) {
+	case *unitTx:
+		return db.savepoint(ctx, func() error {
+			return fn(db)
+		})
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		err = fn(tx)
+		if err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	default:
+		return fn(db)
+	}
+}
+
+// retry runs fn again while it fails with an error that is worth retrying, up to maxRetries times
+func retry(ctx context.Context, maxRetries int, delay time.Duration, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// MySQL error numbers of transactions that were aborted and may succeed when run again
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// isRetryable reports whether err aborted the transaction because of a deadlock or lock wait timeout
+func isRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
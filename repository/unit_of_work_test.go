@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: mysqlErrDeadlock, Message: "Deadlock found when trying to get lock"}
+
+	t.Run("Deadlock is retried", func(t *testing.T) {
+		attempts := 0
+		err := retry(context.Background(), 3, time.Millisecond, func() error {
+			attempts++
+			if attempts < 3 {
+				return fmt.Errorf("store transaction: %w", deadlock)
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Retries are limited", func(t *testing.T) {
+		attempts := 0
+		err := retry(context.Background(), 2, time.Millisecond, func() error {
+			attempts++
+			return deadlock
+		})
+
+		assert.ErrorIs(t, err, deadlock)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Other errors are not retried", func(t *testing.T) {
+		attempts := 0
+		duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+		err := retry(context.Background(), 3, time.Millisecond, func() error {
+			attempts++
+			return duplicate
+		})
+
+		assert.ErrorIs(t, err, duplicate)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Cancelled context stops retrying", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		attempts := 0
+		err := retry(ctx, 3, time.Hour, func() error {
+			attempts++
+			return deadlock
+		})
+
+		assert.ErrorIs(t, err, deadlock)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(&mysql.MySQLError{Number: mysqlErrDeadlock}))
+	assert.True(t, isRetryable(&mysql.MySQLError{Number: mysqlErrLockWaitTimeout}))
+	assert.False(t, isRetryable(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, isRetryable(errors.New("connection refused")))
+	assert.False(t, isRetryable(nil))
+}
+
+func TestTimeouts(t *testing.T) {
+	timeouts := Timeouts{Default: time.Second, Operations: map[string]time.Duration{"ListTransactions": time.Minute}}
+
+	assert.Equal(t, time.Minute, timeouts.For("ListTransactions"))
+	assert.Equal(t, time.Second, timeouts.For("GetTransactionByID"))
+
+	ctx, cancel := timeouts.withTimeout(context.Background(), "GetTransactionByID")
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	ctx, cancel = Timeouts{}.withTimeout(context.Background(), "GetTransactionByID")
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CertificateReloader serves a TLS certificate and reads it again from its files when they
+// change, so a renewed certificate is picked up without a restart. A certificate that cannot be
+// loaded is logged and the previous one is kept.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	modified    time.Time
+}
+
+// NewCertificateReloader loads the certificate and key of the PEM files
+func NewCertificateReloader(certFile string, keyFile string) (*CertificateReloader, error) {
+	c := &CertificateReloader{certFile: certFile, keyFile: keyFile}
+	err := c.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate returns the current certificate, for tls.Config
+func (c *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.certificate, nil
+}
+
+// Reload reads the certificate and key again, keeping the current ones if that fails
+func (c *CertificateReloader) Reload() error {
+	modified, err := c.lastModified()
+	if err != nil {
+		return err
+	}
+	certificate, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate %s: %w", c.certFile, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.certificate = &certificate
+	c.modified = modified
+	return nil
+}
+
+// Watch reloads the certificate on SIGHUP, and when its files were modified since the last load,
+// checked every interval, until ctx is done
+func (c *CertificateReloader) Watch(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			c.reload()
+		case <-ticker.C:
+			modified, err := c.lastModified()
+			if err != nil {
+				logrus.Warn(err)
+				continue
+			}
+			c.mu.RLock()
+			changed := modified.After(c.modified)
+			c.mu.RUnlock()
+			if changed {
+				c.reload()
+			}
+		}
+	}
+}
+
+func (c *CertificateReloader) reload() {
+	err := c.Reload()
+	if err != nil {
+		logrus.Errorf("Keeping the current TLS certificate: %v", err)
+		return
+	}
+	logrus.Infof("Reloaded TLS certificate %s", c.certFile)
+}
+
+// lastModified returns when the certificate or the key was last modified
+func (c *CertificateReloader) lastModified() (time.Time, error) {
+	var modified time.Time
+	for _, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to check TLS certificate: %w", err)
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+	return modified, nil
+}
@@ -0,0 +1,185 @@
+// Package server runs the HTTP API with the limits of the configuration and shuts it down
+// gracefully: on SIGINT or SIGTERM it stops accepting connections, lets in-flight requests and
+// background jobs finish within the shutdown timeout, then releases resources such as the
+// database pool.
+package server
+
+import (
+	"alif-sigmatech/config"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Server is the HTTP server of the API together with the background jobs and resources that
+// live as long as it
+type Server struct {
+	HTTP *http.Server
+	// ShutdownDelay is how long the server keeps serving once shutdown starts, after telling the
+	// draining hooks
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests and background jobs may take to finish
+	ShutdownTimeout time.Duration
+
+	certificates *CertificateReloader
+	jobs         sync.WaitGroup
+	jobsCtx      context.Context
+	stopJobs     context.CancelFunc
+	draining     []func()
+	closers      []func() error
+}
+
+// New creates a server listening on addr, e.g. :8080, and serving handler within the limits of
+// cfg. With a certificate configured it serves HTTPS and reloads the certificate when it changes.
+func New(addr string, cfg config.HTTP, handler http.Handler) (*Server, error) {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	s := &Server{
+		HTTP: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+			ErrorLog:          newErrorLog(),
+		},
+		ShutdownDelay:   cfg.ShutdownDelay,
+		ShutdownTimeout: cfg.ShutdownTimeout,
+		jobsCtx:         jobsCtx,
+		stopJobs:        stopJobs,
+	}
+
+	if cfg.TLSCertFile != "" {
+		certificates, err := NewCertificateReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			stopJobs()
+			return nil, err
+		}
+		s.certificates = certificates
+		s.HTTP.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certificates.GetCertificate,
+		}
+		s.Go(func(ctx context.Context) {
+			certificates.Watch(ctx, cfg.TLSReloadInterval)
+		})
+	}
+	return s, nil
+}
+
+// Go runs a background job. Its context is cancelled on shutdown, which waits for the job to return.
+func (s *Server) Go(job func(ctx context.Context)) {
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		job(s.jobsCtx)
+	}()
+}
+
+// OnDrain registers a function called as soon as shutdown starts, while the server still serves
+// requests, e.g. to fail readiness
+func (s *Server) OnDrain(drain func()) {
+	s.draining = append(s.draining, drain)
+}
+
+// OnShutdown registers a resource to close once requests and background jobs are done, e.g. the
+// database pool. Resources are closed in the reverse order of registration.
+func (s *Server) OnShutdown(close func() error) {
+	s.closers = append(s.closers, close)
+}
+
+// Run listens on the address of the server and serves until ctx is done, then shuts down
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.HTTP.Addr)
+	if err != nil {
+		s.close()
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves the connections of listener until ctx is done, then drains and shuts down. It
+// returns the error that stopped the server, or the errors of the shutdown.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		if s.certificates != nil {
+			serveErr <- s.HTTP.ServeTLS(listener, "", "")
+		} else {
+			serveErr <- s.HTTP.Serve(listener)
+		}
+	}()
+	logrus.Infof("Server listening on %s", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		// The server stopped on its own, so there is nothing left to drain
+		s.stopJobs()
+		s.jobs.Wait()
+		return errors.Join(err, s.close())
+	case <-ctx.Done():
+	}
+
+	for _, drain := range s.draining {
+		drain()
+	}
+	if s.ShutdownDelay > 0 {
+		logrus.Infof("Draining, shutting down in %s", s.ShutdownDelay)
+		time.Sleep(s.ShutdownDelay)
+	}
+
+	logrus.Infof("Shutting down, waiting up to %s for in-flight requests", s.ShutdownTimeout)
+	return s.Shutdown()
+}
+
+// Shutdown stops accepting connections, waits for in-flight requests and background jobs within
+// the shutdown timeout and closes the registered resources. Requests still running at the
+// timeout have their connections closed, cancelling their contexts, before the resources they
+// may be using are closed.
+func (s *Server) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	err := s.HTTP.Shutdown(ctx)
+	if err != nil {
+		errs = append(errs, err, s.HTTP.Close())
+	}
+
+	s.stopJobs()
+	jobsDone := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(jobsDone)
+	}()
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("background jobs did not finish before the shutdown timeout"))
+	}
+
+	errs = append(errs, s.close())
+	return errors.Join(errs...)
+}
+
+func (s *Server) close() error {
+	var errs []error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		errs = append(errs, s.closers[i]())
+	}
+	s.closers = nil
+	return errors.Join(errs...)
+}
+
+// newErrorLog sends the errors of the HTTP server, such as failed TLS handshakes, to logrus
+func newErrorLog() *log.Logger {
+	return log.New(logrus.StandardLogger().WriterLevel(logrus.WarnLevel), "", 0)
+}
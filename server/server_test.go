@@ -0,0 +1,228 @@
+package server_test
+
+import (
+	"alif-sigmatech/config"
+	"alif-sigmatech/server"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listen(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	return listener
+}
+
+func TestServe(t *testing.T) {
+	t.Run("Shutdown drains in-flight requests, then jobs, then resources", func(t *testing.T) {
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			io.WriteString(w, "done")
+		})
+		srv, err := server.New("", config.Default().HTTP, handler)
+		assert.NoError(t, err)
+
+		var order []string
+		jobStopped := make(chan struct{})
+		srv.Go(func(ctx context.Context) {
+			<-ctx.Done()
+			order = append(order, "job")
+			close(jobStopped)
+		})
+		srv.OnShutdown(func() error {
+			<-jobStopped
+			order = append(order, "db")
+			return nil
+		})
+
+		listener := listen(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() { served <- srv.Serve(ctx, listener) }()
+
+		responses := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + listener.Addr().String())
+			if err != nil {
+				responses <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			responses <- string(body)
+		}()
+
+		<-started
+		cancel()
+
+		assert.Equal(t, "done", <-responses)
+		assert.NoError(t, <-served)
+		assert.Equal(t, []string{"job", "db"}, order)
+
+		_, err = http.Get("http://" + listener.Addr().String())
+		assert.Error(t, err)
+	})
+
+	t.Run("Draining keeps serving for the shutdown delay", func(t *testing.T) {
+		cfg := config.Default().HTTP
+		cfg.ShutdownDelay = 100 * time.Millisecond
+		srv, err := server.New("", cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "still serving")
+		}))
+		assert.NoError(t, err)
+		drained := make(chan struct{})
+		srv.OnDrain(func() { close(drained) })
+
+		listener := listen(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() { served <- srv.Serve(ctx, listener) }()
+
+		cancel()
+		<-drained
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if assert.NoError(t, err) {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Equal(t, "still serving", string(body))
+		}
+		assert.NoError(t, <-served)
+	})
+
+	t.Run("Shutdown gives up after the timeout", func(t *testing.T) {
+		started := make(chan struct{})
+		cancelled := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-r.Context().Done()
+			close(cancelled)
+			<-release
+		})
+		cfg := config.Default().HTTP
+		cfg.ShutdownTimeout = 50 * time.Millisecond
+		srv, err := server.New("", cfg, handler)
+		assert.NoError(t, err)
+		closed := false
+		srv.OnShutdown(func() error {
+			// The connection of the request still running is closed before resources are
+			select {
+			case <-cancelled:
+			case <-time.After(time.Second):
+				t.Error("the request still running was not cancelled before closing resources")
+			}
+			closed = true
+			return nil
+		})
+
+		listener := listen(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		served := make(chan error, 1)
+		go func() { served <- srv.Serve(ctx, listener) }()
+		go http.Get("http://" + listener.Addr().String())
+
+		<-started
+		cancel()
+
+		assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+		assert.True(t, closed)
+	})
+}
+
+// writeCertificate writes a self-signed certificate for localhost with the common name
+func writeCertificate(t *testing.T, dir string, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func servedCommonName(t *testing.T, addr string) string {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir, "first")
+	cfg := config.Default().HTTP
+	cfg.TLSCertFile = certFile
+	cfg.TLSKeyFile = keyFile
+	cfg.TLSReloadInterval = 10 * time.Millisecond
+	srv, err := server.New("", cfg, http.NotFoundHandler())
+	assert.NoError(t, err)
+
+	listener := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, listener) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-served)
+	}()
+
+	assert.Equal(t, "first", servedCommonName(t, listener.Addr().String()))
+
+	// A broken certificate is ignored until it is fixed
+	later := time.Now().Add(time.Second)
+	assert.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	assert.NoError(t, os.Chtimes(certFile, later, later))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "first", servedCommonName(t, listener.Addr().String()))
+
+	writeCertificate(t, dir, "second")
+	later = later.Add(time.Second)
+	assert.NoError(t, os.Chtimes(certFile, later, later))
+	assert.Eventually(t, func() bool {
+		return servedCommonName(t, listener.Addr().String()) == "second"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNew(t *testing.T) {
+	cfg := config.Default().HTTP
+	cfg.TLSCertFile = filepath.Join(t.TempDir(), "missing.crt")
+	cfg.TLSKeyFile = filepath.Join(t.TempDir(), "missing.key")
+
+	_, err := server.New("", cfg, http.NotFoundHandler())
+
+	assert.ErrorContains(t, err, "failed to check TLS certificate")
+}
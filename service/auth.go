@@ -0,0 +1,314 @@
+package service
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/model"
+	"alif-sigmatech/notifier"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AuthService checks the credentials of customers and manages their passwords
+type AuthService interface {
+	Login(ctx context.Context, credentials model.AuthLogin, clientIP string) (*LoginResult, error)
+	UnlockCustomer(ctx context.Context, customerID int) error
+	ChangePassword(ctx context.Context, customerID int, request model.ChangePasswordRequest) error
+	ForgotPassword(ctx context.Context, request model.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request model.ResetPasswordRequest) error
+}
+
+// LoginResult is the customer whose password was right and the purpose of the token they get next
+type LoginResult struct {
+	Customer *model.Customer
+	// TokenPurpose is model.TokenPurposeAccess, or an MFA purpose while a TOTP code is still needed
+	TokenPurpose string
+}
+
+// DefaultAuthService is the AuthService backed by the repositories
+type DefaultAuthService struct {
+	CustomerRepo      repository.CustomerRepository
+	PasswordResetRepo repository.PasswordResetRepository
+	LoginAttemptRepo  repository.LoginAttemptRepository
+	UnitOfWork        repository.UnitOfWork
+	Notifier          notifier.Notifier
+	PasswordPolicy    util.PasswordPolicy
+	NIKThrottle       util.LoginThrottlePolicy
+	IPThrottle        util.LoginThrottlePolicy
+	MFARequiredRoles  []string
+}
+
+// PasswordResetTokenTTL is how long a token sent by ForgotPassword can be used
+const PasswordResetTokenTTL = 30 * time.Minute
+
+// dummyPasswordHash is compared against when a login uses an unknown NIK
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password for timing"), bcrypt.DefaultCost)
+
+// NewAuthService creates a new instance of DefaultAuthService
+func NewAuthService(customerRepo repository.CustomerRepository, passwordResetRepo repository.PasswordResetRepository,
+	loginAttemptRepo repository.LoginAttemptRepository, unitOfWork repository.UnitOfWork, notifier notifier.Notifier,
+	passwordPolicy util.PasswordPolicy, nikThrottle util.LoginThrottlePolicy, ipThrottle util.LoginThrottlePolicy,
+	mfaRequiredRoles []string) *DefaultAuthService {
+	return &DefaultAuthService{
+		CustomerRepo:      customerRepo,
+		PasswordResetRepo: passwordResetRepo,
+		LoginAttemptRepo:  loginAttemptRepo,
+		UnitOfWork:        unitOfWork,
+		Notifier:          notifier,
+		PasswordPolicy:    passwordPolicy,
+		NIKThrottle:       nikThrottle,
+		IPThrottle:        ipThrottle,
+		MFARequiredRoles:  mfaRequiredRoles,
+	}
+}
+
+// Login checks the NIK and password of a customer logging in from clientIP. Failures are counted
+// against both, and attempts made too soon after earlier failures are refused.
+func (s *DefaultAuthService) Login(ctx context.Context, credentials model.AuthLogin, clientIP string) (*LoginResult, error) {
+	v := validation.New()
+	v.Required("nik", credentials.NIK)
+	v.Required("password", credentials.Password)
+	if err := validationError(v); err != nil {
+		return nil, err
+	}
+
+	nikKey := "nik:" + credentials.NIK
+	ipKey := "ip:" + clientIP
+
+	retryAfter, err := s.loginRetryAfter(ctx, nikKey, ipKey)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		metrics.Logins.WithLabelValues(metrics.LoginPassword, metrics.LoginFailure).Inc()
+		return nil, &Error{
+			Kind:       KindTooManyAttempts,
+			Code:       apierror.CodeTooManyLoginAttempts,
+			Message:    "Too many failed login attempts",
+			RetryAfter: retryAfter,
+		}
+	}
+
+	customer, err := s.CustomerRepo.GetCustomerByNIK(ctx, credentials.NIK)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unknown NIKs are compared against a dummy hash so they take as long as a wrong password
+	passwordHash := dummyPasswordHash
+	if customer != nil {
+		passwordHash = []byte(customer.Password)
+	}
+	err = bcrypt.CompareHashAndPassword(passwordHash, []byte(credentials.Password))
+	if customer == nil || err != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginPassword, metrics.LoginFailure).Inc()
+		if err := s.recordLoginFailure(ctx, nikKey, ipKey); err != nil {
+			logrus.Error(err)
+		}
+		return nil, newError(KindUnauthenticated, apierror.CodeInvalidCredentials, "Invalid NIK or password")
+	}
+
+	err = s.LoginAttemptRepo.DeleteLoginAttempt(ctx, nikKey)
+	if err != nil {
+		logrus.Error(err)
+	}
+
+	result := &LoginResult{Customer: customer, TokenPurpose: model.TokenPurposeAccess}
+	switch {
+	case customer.MFAEnabled:
+		// The password was right but a TOTP code is still needed
+		result.TokenPurpose = model.TokenPurposeMFAChallenge
+	case s.requiresMFA(customer.Role):
+		// The role must use MFA but the account has not enrolled yet
+		result.TokenPurpose = model.TokenPurposeMFAEnrollment
+	default:
+		// Logins needing MFA are counted once the code is checked
+		metrics.Logins.WithLabelValues(metrics.LoginPassword, metrics.LoginSuccess).Inc()
+	}
+
+	return result, nil
+}
+
+// requiresMFA reports whether accounts with the role must log in with MFA
+func (s *DefaultAuthService) requiresMFA(role string) bool {
+	for _, required := range s.MFARequiredRoles {
+		if role == required {
+			return true
+		}
+	}
+	return false
+}
+
+// loginRetryAfter returns how long the client has to wait before the next login attempt
+func (s *DefaultAuthService) loginRetryAfter(ctx context.Context, nikKey, ipKey string) (time.Duration, error) {
+	nikAttempt, err := s.LoginAttemptRepo.GetLoginAttempt(ctx, nikKey)
+	if err != nil {
+		return 0, err
+	}
+	ipAttempt, err := s.LoginAttemptRepo.GetLoginAttempt(ctx, ipKey)
+	if err != nil {
+		return 0, err
+	}
+
+	allowedAt := s.NIKThrottle.NextAllowedAt(nikAttempt)
+	if ipAllowedAt := s.IPThrottle.NextAllowedAt(ipAttempt); ipAllowedAt.After(allowedAt) {
+		allowedAt = ipAllowedAt
+	}
+
+	return time.Until(allowedAt), nil
+}
+
+// recordLoginFailure counts a failed login against both the NIK and the client IP
+func (s *DefaultAuthService) recordLoginFailure(ctx context.Context, nikKey, ipKey string) error {
+	now := time.Now()
+	err := s.LoginAttemptRepo.RecordLoginFailure(ctx, nikKey, now, now.Add(-s.NIKThrottle.ResetAfter))
+	if err != nil {
+		return err
+	}
+	return s.LoginAttemptRepo.RecordLoginFailure(ctx, ipKey, now, now.Add(-s.IPThrottle.ResetAfter))
+}
+
+// UnlockCustomer clears the failed login counter of a customer's NIK
+func (s *DefaultAuthService) UnlockCustomer(ctx context.Context, customerID int) error {
+	customer, err := s.CustomerRepo.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+
+	return s.LoginAttemptRepo.DeleteLoginAttempt(ctx, "nik:"+customer.NIK)
+}
+
+// ChangePassword sets a new password for a logged in customer who knows the old one
+func (s *DefaultAuthService) ChangePassword(ctx context.Context, customerID int, request model.ChangePasswordRequest) error {
+	v := validation.New()
+	v.Required("old_password", request.OldPassword)
+	v.Required("new_password", request.NewPassword)
+	if err := validationError(v); err != nil {
+		return err
+	}
+
+	customer, err := s.CustomerRepo.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte(request.OldPassword))
+	if err != nil {
+		return newError(KindUnauthenticated, apierror.CodeIncorrectPassword, "Old password is incorrect")
+	}
+
+	v = validation.New()
+	v.Password("new_password", request.NewPassword, s.PasswordPolicy)
+	if err := validationError(v); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.CustomerRepo.UpdatePassword(ctx, customer.ID, string(hashedPassword))
+}
+
+// ForgotPassword sends a single-use reset token to the customer with the NIK. It succeeds
+// whether or not the NIK is registered, so callers cannot tell the difference.
+func (s *DefaultAuthService) ForgotPassword(ctx context.Context, request model.ForgotPasswordRequest) error {
+	v := validation.New()
+	v.Required("nik", request.NIK)
+	if err := validationError(v); err != nil {
+		return err
+	}
+
+	customer, err := s.CustomerRepo.GetCustomerByNIK(ctx, request.NIK)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return nil
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.PasswordResetRepo.CreatePasswordResetToken(ctx, &model.PasswordResetToken{
+		CustomerID: customer.ID,
+		TokenHash:  hashToken(secret),
+		ExpiresAt:  now.Add(PasswordResetTokenTTL),
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Notifier.Notify(notifier.Message{
+		CustomerID: customer.ID,
+		Recipient:  customer.PhoneNumber,
+		Subject:    "Password reset",
+		Body:       fmt.Sprintf("Use this token to reset your password within %d minutes: %s", int(PasswordResetTokenTTL.Minutes()), secret),
+	})
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword and revokes all existing sessions
+func (s *DefaultAuthService) ResetPassword(ctx context.Context, request model.ResetPasswordRequest) error {
+	v := validation.New()
+	v.Required("token", request.Token)
+	v.Required("new_password", request.NewPassword)
+	if err := validationError(v); err != nil {
+		return err
+	}
+
+	v = validation.New()
+	v.Password("new_password", request.NewPassword, s.PasswordPolicy)
+	if err := validationError(v); err != nil {
+		return err
+	}
+
+	token, err := s.PasswordResetRepo.GetPasswordResetTokenByHash(ctx, hashToken(request.Token))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if token == nil || token.UsedAt != nil || now.After(token.ExpiresAt) {
+		return newError(KindValidation, apierror.CodeInvalidResetToken, "Invalid or expired reset token")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// The token is only spent if the password changes and the old sessions end with it
+	return s.UnitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		consumed, err := repos.PasswordResets.ConsumePasswordResetToken(ctx, token.ID, now)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return newError(KindValidation, apierror.CodeInvalidResetToken, "Invalid or expired reset token")
+		}
+
+		err = repos.Customers.UpdatePassword(ctx, token.CustomerID, string(hashedPassword))
+		if err != nil {
+			return err
+		}
+
+		return repos.Customers.RevokeSessions(ctx, token.CustomerID)
+	})
+}
@@ -0,0 +1,318 @@
+package service_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
+	"alif-sigmatech/util"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func hashPassword(t *testing.T, password string) string {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	assert.NoError(t, err)
+	return string(hashedPassword)
+}
+
+type testAuthService struct {
+	*service.DefaultAuthService
+	customerRepo      *mocks.MockCustomerRepository
+	passwordResetRepo *mocks.MockPasswordResetRepository
+	loginAttemptRepo  *mocks.MockLoginAttemptRepository
+	notifier          *recordingNotifier
+	unitOfWork        *mocks.FakeUnitOfWork
+}
+
+func newTestAuthService(ctrl *gomock.Controller) *testAuthService {
+	s := &testAuthService{
+		customerRepo:      mocks.NewMockCustomerRepository(ctrl),
+		passwordResetRepo: mocks.NewMockPasswordResetRepository(ctrl),
+		loginAttemptRepo:  mocks.NewMockLoginAttemptRepository(ctrl),
+		notifier:          &recordingNotifier{},
+	}
+	s.unitOfWork = mocks.NewFakeUnitOfWork(repository.Repositories{
+		Customers:      s.customerRepo,
+		PasswordResets: s.passwordResetRepo,
+	})
+	s.DefaultAuthService = service.NewAuthService(s.customerRepo, s.passwordResetRepo, s.loginAttemptRepo, s.unitOfWork, s.notifier,
+		util.DefaultPasswordPolicy(), util.DefaultNIKThrottlePolicy(), util.DefaultIPThrottlePolicy(),
+		[]string{model.RoleOfficer, model.RoleAdmin})
+	return s
+}
+
+func TestLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestAuthService(ctrl)
+	s.loginAttemptRepo.EXPECT().GetLoginAttempt(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	password := hashPassword(t, "CorrectPassw0rd")
+
+	login := func(nik, plain string) (*service.LoginResult, error) {
+		return s.Login(context.Background(), model.AuthLogin{NIK: nik, Password: plain}, "10.0.0.1")
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "111").Return(&model.Customer{ID: 1, NIK: "111", Role: model.RoleCustomer, Password: password}, nil)
+		s.loginAttemptRepo.EXPECT().DeleteLoginAttempt(gomock.Any(), "nik:111").Return(nil)
+		logins := testutil.ToFloat64(metrics.Logins.WithLabelValues(metrics.LoginPassword, metrics.LoginSuccess))
+
+		result, err := login("111", "CorrectPassw0rd")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Customer.ID)
+		assert.Equal(t, model.TokenPurposeAccess, result.TokenPurpose)
+		assert.Equal(t, logins+1, testutil.ToFloat64(metrics.Logins.WithLabelValues(metrics.LoginPassword, metrics.LoginSuccess)))
+	})
+
+	t.Run("Enrolled customer needs a TOTP code", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "111").Return(&model.Customer{ID: 1, NIK: "111", Role: model.RoleCustomer, MFAEnabled: true, Password: password}, nil)
+		s.loginAttemptRepo.EXPECT().DeleteLoginAttempt(gomock.Any(), "nik:111").Return(nil)
+
+		result, err := login("111", "CorrectPassw0rd")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.TokenPurposeMFAChallenge, result.TokenPurpose)
+	})
+
+	t.Run("Officer without MFA must enrol", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "222").Return(&model.Customer{ID: 2, NIK: "222", Role: model.RoleOfficer, Password: password}, nil)
+		s.loginAttemptRepo.EXPECT().DeleteLoginAttempt(gomock.Any(), "nik:222").Return(nil)
+
+		result, err := login("222", "CorrectPassw0rd")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.TokenPurposeMFAEnrollment, result.TokenPurpose)
+	})
+
+	t.Run("Unknown NIK and wrong password are indistinguishable", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "333").Return(nil, nil)
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "111").Return(&model.Customer{ID: 1, NIK: "111", Password: password}, nil)
+		s.loginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), "nik:333", gomock.Any(), gomock.Any()).Return(nil)
+		s.loginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), "nik:111", gomock.Any(), gomock.Any()).Return(nil)
+		s.loginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), "ip:10.0.0.1", gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		_, unknown := login("333", "WrongPassw0rd")
+		_, wrong := login("111", "WrongPassw0rd")
+
+		assert.Equal(t, service.KindUnauthenticated, service.KindOf(unknown))
+		assert.Equal(t, apierror.CodeInvalidCredentials, unknown.(*service.Error).Code)
+		assert.Equal(t, wrong, unknown)
+	})
+
+	t.Run("Missing password", func(t *testing.T) {
+		_, err := login("111", "")
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+}
+
+func TestLoginThrottling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestAuthService(ctrl)
+	password := hashPassword(t, "CorrectPassw0rd")
+
+	login := func() (*service.LoginResult, error) {
+		return s.Login(context.Background(), model.AuthLogin{NIK: "222", Password: "CorrectPassw0rd"}, "10.0.0.1")
+	}
+	expectAttempts := func(nikAttempt, ipAttempt *model.LoginAttempt) {
+		s.loginAttemptRepo.EXPECT().GetLoginAttempt(gomock.Any(), "nik:222").Return(nikAttempt, nil)
+		s.loginAttemptRepo.EXPECT().GetLoginAttempt(gomock.Any(), "ip:10.0.0.1").Return(ipAttempt, nil)
+	}
+
+	t.Run("Backoff after repeated failures", func(t *testing.T) {
+		expectAttempts(&model.LoginAttempt{Key: "nik:222", Failures: 6, LastFailureAt: time.Now()}, nil)
+
+		_, err := login()
+
+		assert.Equal(t, service.KindTooManyAttempts, service.KindOf(err))
+		assert.Equal(t, apierror.CodeTooManyLoginAttempts, err.(*service.Error).Code)
+		assert.InDelta(t, 4*time.Second, err.(*service.Error).RetryAfter, float64(time.Second))
+	})
+
+	t.Run("Locked out past the threshold", func(t *testing.T) {
+		expectAttempts(&model.LoginAttempt{Key: "nik:222", Failures: 10, LastFailureAt: time.Now()}, nil)
+
+		_, err := login()
+
+		assert.Equal(t, service.KindTooManyAttempts, service.KindOf(err))
+		assert.InDelta(t, 15*time.Minute, err.(*service.Error).RetryAfter, float64(time.Second))
+	})
+
+	t.Run("Client IP throttled on its own", func(t *testing.T) {
+		expectAttempts(nil, &model.LoginAttempt{Key: "ip:10.0.0.1", Failures: 100, LastFailureAt: time.Now()})
+
+		_, err := login()
+
+		assert.Equal(t, service.KindTooManyAttempts, service.KindOf(err))
+	})
+
+	t.Run("Lockout expires", func(t *testing.T) {
+		expectAttempts(&model.LoginAttempt{Key: "nik:222", Failures: 10, LastFailureAt: time.Now().Add(-16 * time.Minute)}, nil)
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "222").Return(&model.Customer{ID: 2, NIK: "222", Password: password}, nil)
+		s.loginAttemptRepo.EXPECT().DeleteLoginAttempt(gomock.Any(), "nik:222").Return(nil)
+
+		result, err := login()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Customer.ID)
+	})
+
+	t.Run("Officer unlock", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByID(gomock.Any(), 2).Return(&model.Customer{ID: 2, NIK: "222"}, nil)
+		s.loginAttemptRepo.EXPECT().DeleteLoginAttempt(gomock.Any(), "nik:222").Return(nil)
+
+		assert.NoError(t, s.UnlockCustomer(context.Background(), 2))
+	})
+
+	t.Run("Unlock unknown customer", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByID(gomock.Any(), 3).Return(nil, nil)
+
+		err := s.UnlockCustomer(context.Background(), 3)
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestAuthService(ctrl)
+	customer := &model.Customer{ID: 1, Password: hashPassword(t, "OldPassw0rd!")}
+	s.customerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(customer, nil).AnyTimes()
+
+	t.Run("Wrong old password", func(t *testing.T) {
+		err := s.ChangePassword(context.Background(), 1, model.ChangePasswordRequest{OldPassword: "nope", NewPassword: "NewPassw0rd!!"})
+
+		assert.Equal(t, service.KindUnauthenticated, service.KindOf(err))
+		assert.Equal(t, apierror.CodeIncorrectPassword, err.(*service.Error).Code)
+	})
+
+	t.Run("Weak new password", func(t *testing.T) {
+		err := s.ChangePassword(context.Background(), 1, model.ChangePasswordRequest{OldPassword: "OldPassw0rd!", NewPassword: "weak"})
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		s.customerRepo.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, hashedPassword string) error {
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte("NewPassw0rd!!")))
+			return nil
+		})
+
+		err := s.ChangePassword(context.Background(), 1, model.ChangePasswordRequest{OldPassword: "OldPassw0rd!", NewPassword: "NewPassw0rd!!"})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestAuthService(ctrl)
+	s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "3201010101010001").Return(&model.Customer{
+		ID: 1, NIK: "3201010101010001", PhoneNumber: "081234567890",
+	}, nil).AnyTimes()
+
+	t.Run("Unknown NIK looks the same", func(t *testing.T) {
+		s.customerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "9999999999999999").Return(nil, nil)
+
+		err := s.ForgotPassword(context.Background(), model.ForgotPasswordRequest{NIK: "9999999999999999"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, s.notifier.messages)
+	})
+
+	var stored *model.PasswordResetToken
+	var token string
+
+	t.Run("Token is sent through the notifier", func(t *testing.T) {
+		s.passwordResetRepo.EXPECT().CreatePasswordResetToken(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, resetToken *model.PasswordResetToken) error {
+			resetToken.ID = 10
+			stored = resetToken
+			return nil
+		})
+
+		err := s.ForgotPassword(context.Background(), model.ForgotPasswordRequest{NIK: "3201010101010001"})
+
+		assert.NoError(t, err)
+		assert.Len(t, s.notifier.messages, 1)
+		assert.Equal(t, "081234567890", s.notifier.messages[0].Recipient)
+
+		body := s.notifier.messages[0].Body
+		token = body[strings.LastIndex(body, " ")+1:]
+		assert.Equal(t, hashCode(token), stored.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(service.PasswordResetTokenTTL), stored.ExpiresAt, time.Minute)
+	})
+
+	reset := func(password string) error {
+		return s.ResetPassword(context.Background(), model.ResetPasswordRequest{Token: token, NewPassword: password})
+	}
+
+	t.Run("Expired token", func(t *testing.T) {
+		expired := *stored
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		s.passwordResetRepo.EXPECT().GetPasswordResetTokenByHash(gomock.Any(), hashCode(token)).Return(&expired, nil)
+
+		err := reset("BrandNewPassw0rd")
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, apierror.CodeInvalidResetToken, err.(*service.Error).Code)
+	})
+
+	t.Run("Weak new password", func(t *testing.T) {
+		err := reset("weak")
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, apierror.CodeValidationFailed, err.(*service.Error).Code)
+	})
+
+	t.Run("Success revokes sessions", func(t *testing.T) {
+		s.passwordResetRepo.EXPECT().GetPasswordResetTokenByHash(gomock.Any(), hashCode(token)).Return(stored, nil)
+		s.passwordResetRepo.EXPECT().ConsumePasswordResetToken(gomock.Any(), 10, gomock.Any()).Return(true, nil)
+		s.customerRepo.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, hashedPassword string) error {
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte("BrandNewPassw0rd")))
+			return nil
+		})
+		s.customerRepo.EXPECT().RevokeSessions(gomock.Any(), 1).Return(nil)
+
+		assert.NoError(t, reset("BrandNewPassw0rd"))
+		assert.Equal(t, 1, s.unitOfWork.Commits)
+	})
+
+	t.Run("Failed revocation keeps the token", func(t *testing.T) {
+		s.passwordResetRepo.EXPECT().GetPasswordResetTokenByHash(gomock.Any(), hashCode(token)).Return(stored, nil)
+		s.passwordResetRepo.EXPECT().ConsumePasswordResetToken(gomock.Any(), 10, gomock.Any()).Return(true, nil)
+		s.customerRepo.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).Return(nil)
+		s.customerRepo.EXPECT().RevokeSessions(gomock.Any(), 1).Return(errors.New("connection reset"))
+
+		assert.Error(t, reset("BrandNewPassw0rd"))
+		assert.Equal(t, 1, s.unitOfWork.Rollbacks)
+	})
+
+	t.Run("Token cannot be used twice", func(t *testing.T) {
+		s.passwordResetRepo.EXPECT().GetPasswordResetTokenByHash(gomock.Any(), hashCode(token)).Return(stored, nil)
+		s.passwordResetRepo.EXPECT().ConsumePasswordResetToken(gomock.Any(), 10, gomock.Any()).Return(false, nil)
+
+		err := reset("AnotherPassw0rd")
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, apierror.CodeInvalidResetToken, err.(*service.Error).Code)
+	})
+}
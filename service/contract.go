@@ -0,0 +1,82 @@
+package service
+
+import (
+	"alif-sigmatech/contract"
+	"alif-sigmatech/model"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StoreContractDocument renders the contract document of a transaction with the current
+// template and stores it encrypted, as it holds the customer's personal data. The blob is stored
+// before the unit of work booking the transaction, which may run more than once: the caller
+// records the document once the transaction has its ID, or discards it when booking fails.
+func StoreContractDocument(blobStore storage.BlobStore, encryptionKey []byte,
+	customer model.Customer, limit model.Limit, transaction model.Transaction) (*model.ContractDocument, error) {
+	now := time.Now()
+	data, contentHash, err := contract.Render(contract.Data{
+		Template:    contract.CurrentTemplate,
+		Customer:    customer,
+		Limit:       limit,
+		Transaction: transaction,
+		GeneratedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := util.EncryptData(data, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction has no ID yet, the content hash covers its contract number and the time
+	// the document was generated
+	document := &model.ContractDocument{
+		CustomerID:  customer.ID,
+		Template:    contract.CurrentTemplate,
+		ContentHash: contentHash,
+		StorageKey:  fmt.Sprintf("contracts/%d/%s.pdf", customer.ID, contentHash),
+		CreatedAt:   now,
+	}
+	err = blobStore.Put(document.StorageKey, encrypted, "application/pdf")
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// DiscardContractDocument deletes the blob of a contract document that was never recorded, so no
+// copy of the customer's personal data is left behind
+func DiscardContractDocument(blobStore storage.BlobStore, document *model.ContractDocument) {
+	err := blobStore.Delete(document.StorageKey)
+	if err != nil {
+		logrus.Errorf("Failed to delete unrecorded contract document %s: %v", document.StorageKey, err)
+	}
+}
+
+// LoadContractDocument reads and decrypts a contract document, checking it against its content hash
+func LoadContractDocument(blobStore storage.BlobStore, encryptionKey []byte, document *model.ContractDocument) ([]byte, error) {
+	encrypted, err := blobStore.Get(document.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := util.DecryptData(encrypted, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+	if hex.EncodeToString(hash[:]) != document.ContentHash {
+		return nil, errors.New("contract document does not match its content hash")
+	}
+	return data, nil
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CustomerService registers customers and manages their profiles and identity corrections
+type CustomerService interface {
+	Register(ctx context.Context, input RegisterCustomerInput) (*model.Customer, error)
+	GetProfile(ctx context.Context, customerID int) (*model.CustomerProfile, error)
+	UpdateProfile(ctx context.Context, customerID int, update model.UpdateProfileRequest) (*model.CustomerProfile, error)
+	RequestCorrection(ctx context.Context, customerID int, input CorrectionInput) (*model.CorrectionRequest, error)
+	ListCorrectionRequests(ctx context.Context, status string) ([]model.CorrectionRequest, error)
+	ReviewCorrectionRequest(ctx context.Context, reviewerID int, id int, review model.ReviewCorrectionRequest) (*model.CorrectionRequest, error)
+}
+
+// RegisterCustomerInput is what a consumer registers with
+type RegisterCustomerInput struct {
+	NIK         string  `json:"nik"`
+	Password    string  `json:"password"`
+	FullName    string  `json:"full_name"`
+	LegalName   string  `json:"legal_name"`
+	BirthPlace  string  `json:"birth_place"`
+	BirthDate   string  `json:"birth_date"`
+	Salary      float64 `json:"salary"`
+	Address     string  `json:"address"`
+	PhoneNumber string  `json:"phone_number"`
+}
+
+// CorrectionInput asks for an identity field of the customer to be corrected
+type CorrectionInput struct {
+	FieldName      string `json:"field_name"`
+	RequestedValue string `json:"requested_value"`
+	Reason         string `json:"reason"`
+}
+
+// IdentityFields are the customer fields that can only change through an approved correction request
+var IdentityFields = map[string]bool{
+	"nik":         true,
+	"full_name":   true,
+	"legal_name":  true,
+	"birth_place": true,
+	"birth_date":  true,
+}
+
+// identityFieldNames lists IdentityFields in the order they are named in messages
+var identityFieldNames = []string{"nik", "full_name", "legal_name", "birth_place", "birth_date"}
+
+// DefaultCustomerService is the CustomerService backed by the repositories
+type DefaultCustomerService struct {
+	CustomerRepo   repository.CustomerRepository
+	CorrectionRepo repository.CorrectionRepository
+	UnitOfWork     repository.UnitOfWork
+	PasswordPolicy util.PasswordPolicy
+}
+
+// NewCustomerService creates a new instance of DefaultCustomerService
+func NewCustomerService(customerRepo repository.CustomerRepository, correctionRepo repository.CorrectionRepository,
+	unitOfWork repository.UnitOfWork, passwordPolicy util.PasswordPolicy) *DefaultCustomerService {
+	return &DefaultCustomerService{
+		CustomerRepo:   customerRepo,
+		CorrectionRepo: correctionRepo,
+		UnitOfWork:     unitOfWork,
+		PasswordPolicy: passwordPolicy,
+	}
+}
+
+// Register creates a regular customer account. Identity documents are uploaded separately once
+// the customer is logged in, and the returned customer carries no password.
+func (s *DefaultCustomerService) Register(ctx context.Context, input RegisterCustomerInput) (*model.Customer, error) {
+	err := s.validateRegisterCustomerInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := model.Customer{
+		NIK:         input.NIK,
+		FullName:    input.FullName,
+		LegalName:   input.LegalName,
+		BirthPlace:  input.BirthPlace,
+		BirthDate:   input.BirthDate,
+		Salary:      input.Salary,
+		Address:     input.Address,
+		PhoneNumber: input.PhoneNumber,
+		// Self registration always creates a regular customer account
+		Role: model.RoleCustomer,
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	customer.Password = string(hashedPassword)
+
+	existing, err := s.CustomerRepo.GetCustomerByNIK(ctx, customer.NIK)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, newError(KindConflict, apierror.CodeNIKAlreadyRegistered, "NIK already exist")
+	}
+
+	err = s.CustomerRepo.RegisterCustomer(ctx, &customer)
+	if errors.Is(err, util.ErrDuplicate) {
+		// Registered concurrently since the lookup above
+		return nil, newError(KindConflict, apierror.CodeNIKAlreadyRegistered, "NIK already exist")
+	}
+	if err != nil {
+		return nil, err
+	}
+	customer.Password = "" // obfuscate
+	metrics.Registrations.Inc()
+
+	return &customer, nil
+}
+
+// GetProfile returns the profile of a customer
+func (s *DefaultCustomerService) GetProfile(ctx context.Context, customerID int) (*model.CustomerProfile, error) {
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := newCustomerProfile(customer)
+	return &profile, nil
+}
+
+// UpdateProfile changes the mutable fields of a customer that are set in the update
+func (s *DefaultCustomerService) UpdateProfile(ctx context.Context, customerID int, update model.UpdateProfileRequest) (*model.CustomerProfile, error) {
+	err := validateUpdateProfileInput(update)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Salary != nil {
+		customer.Salary = *update.Salary
+	}
+	if update.Address != nil {
+		customer.Address = strings.TrimSpace(*update.Address)
+	}
+	if update.PhoneNumber != nil {
+		customer.PhoneNumber = *update.PhoneNumber
+	}
+	if update.Language != nil {
+		customer.Language = *update.Language
+	}
+
+	err = s.CustomerRepo.UpdateCustomerProfile(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := newCustomerProfile(customer)
+	return &profile, nil
+}
+
+// RequestCorrection asks an officer to correct an identity field of the customer
+func (s *DefaultCustomerService) RequestCorrection(ctx context.Context, customerID int, input CorrectionInput) (*model.CorrectionRequest, error) {
+	err := validateCorrectionInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &model.CorrectionRequest{
+		CustomerID:     customerID,
+		FieldName:      input.FieldName,
+		RequestedValue: input.RequestedValue,
+		Reason:         input.Reason,
+		Status:         model.CorrectionPending,
+		CreatedAt:      time.Now(),
+	}
+	err = s.CorrectionRepo.CreateCorrectionRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ListCorrectionRequests returns the correction requests in the given status, pending ones by default
+func (s *DefaultCustomerService) ListCorrectionRequests(ctx context.Context, status string) ([]model.CorrectionRequest, error) {
+	if status == "" {
+		status = model.CorrectionPending
+	}
+	return s.CorrectionRepo.ListCorrectionRequests(ctx, status)
+}
+
+// ReviewCorrectionRequest approves or rejects a pending correction request on behalf of the reviewer.
+// Approving applies the requested value to the customer's identity in the same unit of work, so
+// the identity only changes along with the request being marked approved.
+func (s *DefaultCustomerService) ReviewCorrectionRequest(ctx context.Context, reviewerID int, id int, review model.ReviewCorrectionRequest) (*model.CorrectionRequest, error) {
+	err := validateReviewCorrectionInput(review)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := s.CorrectionRepo.GetCorrectionRequestByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, newError(KindNotFound, apierror.CodeCorrectionNotFound, "Correction request not found")
+	}
+	if request.Status != model.CorrectionPending {
+		return nil, newError(KindConflict, apierror.CodeCorrectionAlreadyReviewed, "Correction request has already been reviewed")
+	}
+
+	reviewedAt := time.Now()
+	request.Status = review.Status
+	request.ReviewedBy = &reviewerID
+	request.ReviewNote = review.Note
+	request.ReviewedAt = &reviewedAt
+
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		// Marking the request reviewed first locks its row, so a concurrent review waits and then finds it reviewed
+		updated, err := repos.Corrections.UpdateCorrectionRequestReview(ctx, request)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return newError(KindConflict, apierror.CodeCorrectionAlreadyReviewed, "Correction request has already been reviewed")
+		}
+
+		if review.Status == model.CorrectionApproved {
+			return applyCorrection(ctx, repos.Customers, request)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// applyCorrection writes the requested value of an approved correction to the customer
+func applyCorrection(ctx context.Context, customerRepo repository.CustomerRepository, request *model.CorrectionRequest) error {
+	customer, err := customerRepo.GetCustomerByID(ctx, request.CustomerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+
+	switch request.FieldName {
+	case "nik":
+		existing, err := customerRepo.GetCustomerByNIK(ctx, request.RequestedValue)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != customer.ID {
+			return newError(KindConflict, apierror.CodeNIKAlreadyRegistered, "NIK already exist")
+		}
+		customer.NIK = request.RequestedValue
+	case "full_name":
+		customer.FullName = request.RequestedValue
+	case "legal_name":
+		customer.LegalName = request.RequestedValue
+	case "birth_place":
+		customer.BirthPlace = request.RequestedValue
+	case "birth_date":
+		customer.BirthDate = request.RequestedValue
+	}
+
+	return customerRepo.UpdateCustomerIdentity(ctx, customer)
+}
+
+func (s *DefaultCustomerService) getCustomer(ctx context.Context, id int) (*model.Customer, error) {
+	customer, err := s.CustomerRepo.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+	return customer, nil
+}
+
+func (s *DefaultCustomerService) validateRegisterCustomerInput(input RegisterCustomerInput) error {
+	v := validation.New()
+	if v.Required("nik", input.NIK) {
+		v.NIK("nik", input.NIK)
+	}
+	if v.Required("password", input.Password) {
+		v.Password("password", input.Password, s.PasswordPolicy)
+	}
+	if v.Required("full_name", input.FullName) {
+		v.MaxLength("full_name", input.FullName, 100)
+	}
+	if v.Required("legal_name", input.LegalName) {
+		v.MaxLength("legal_name", input.LegalName, 100)
+	}
+	v.MaxLength("birth_place", input.BirthPlace, 100)
+	if input.BirthDate != "" {
+		v.Date("birth_date", input.BirthDate)
+	}
+	v.NotNegative("salary", input.Salary)
+	v.MaxLength("address", input.Address, 255)
+	if input.PhoneNumber != "" {
+		v.PhoneNumber("phone_number", input.PhoneNumber)
+	}
+	return validationError(v)
+}
+
+func validateUpdateProfileInput(update model.UpdateProfileRequest) error {
+	v := validation.New()
+	if update.Salary != nil {
+		v.NotNegative("salary", *update.Salary)
+	}
+	if update.Address != nil {
+		v.MaxLength("address", *update.Address, 255)
+	}
+	if update.PhoneNumber != nil {
+		v.PhoneNumber("phone_number", *update.PhoneNumber)
+	}
+	if update.Language != nil {
+		v.OneOf("language", *update.Language, string(i18n.Indonesian), string(i18n.English))
+	}
+	return validationError(v)
+}
+
+func validateCorrectionInput(input CorrectionInput) error {
+	v := validation.New()
+	validField := v.OneOf("field_name", input.FieldName, identityFieldNames...)
+	if v.Required("requested_value", input.RequestedValue) && validField {
+		// The requested value must be acceptable for the field it replaces
+		switch input.FieldName {
+		case "nik":
+			v.NIK("requested_value", input.RequestedValue)
+		case "birth_date":
+			v.Date("requested_value", input.RequestedValue)
+		default:
+			v.MaxLength("requested_value", input.RequestedValue, 100)
+		}
+	}
+	if v.Required("reason", input.Reason) {
+		v.MaxLength("reason", input.Reason, 255)
+	}
+	return validationError(v)
+}
+
+func validateReviewCorrectionInput(review model.ReviewCorrectionRequest) error {
+	v := validation.New()
+	v.OneOf("status", review.Status, model.CorrectionApproved, model.CorrectionRejected)
+	v.MaxLength("note", review.Note, 255)
+	return validationError(v)
+}
+
+func newCustomerProfile(customer *model.Customer) model.CustomerProfile {
+	return model.CustomerProfile{
+		ID:          customer.ID,
+		NIK:         customer.NIK,
+		FullName:    customer.FullName,
+		LegalName:   customer.LegalName,
+		BirthPlace:  customer.BirthPlace,
+		BirthDate:   customer.BirthDate,
+		Salary:      customer.Salary,
+		Address:     customer.Address,
+		PhoneNumber: customer.PhoneNumber,
+		Language:    customer.Language,
+	}
+}
@@ -0,0 +1,317 @@
+package service_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
+	"alif-sigmatech/util"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRegister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	s := service.NewCustomerService(mockCustomerRepo, mocks.NewMockCorrectionRepository(ctrl), mocks.NewFakeUnitOfWork(repository.Repositories{}), util.DefaultPasswordPolicy())
+
+	input := service.RegisterCustomerInput{
+		NIK:       "3201010101010001",
+		FullName:  "Alif Coba",
+		LegalName: "John Doe",
+		Password:  "Str0ngPassphrase",
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "3201010101010001").Return(nil, nil)
+		mockCustomerRepo.EXPECT().RegisterCustomer(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, customer *model.Customer) error {
+			assert.Equal(t, model.RoleCustomer, customer.Role)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(customer.Password), []byte("Str0ngPassphrase")))
+			customer.ID = 5
+			return nil
+		})
+
+		customer, err := s.Register(context.Background(), input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, customer.ID)
+		assert.Empty(t, customer.Password)
+	})
+
+	t.Run("NIK already registered", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "3201010101010001").Return(&model.Customer{ID: 1}, nil)
+
+		_, err := s.Register(context.Background(), input)
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		assert.Equal(t, apierror.CodeNIKAlreadyRegistered, err.(*service.Error).Code)
+	})
+
+	t.Run("NIK registered concurrently", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "3201010101010001").Return(nil, nil)
+		mockCustomerRepo.EXPECT().RegisterCustomer(gomock.Any(), gomock.Any()).Return(fmt.Errorf("%w: Duplicate entry", util.ErrDuplicate))
+
+		_, err := s.Register(context.Background(), input)
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		assert.Equal(t, apierror.CodeNIKAlreadyRegistered, err.(*service.Error).Code)
+	})
+
+	for _, password := range []string{"", "short1A", "alllowercase123", "Password123"} {
+		t.Run("Weak password "+password, func(t *testing.T) {
+			weak := input
+			weak.Password = password
+
+			_, err := s.Register(context.Background(), weak)
+
+			assert.Equal(t, service.KindValidation, service.KindOf(err))
+		})
+	}
+
+	t.Run("Missing legal name", func(t *testing.T) {
+		incomplete := input
+		incomplete.LegalName = ""
+
+		_, err := s.Register(context.Background(), incomplete)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, []apierror.FieldError{{Field: "legal_name", Code: apierror.CodeRequired, Message: "LegalName is required"}}, reported(err.(*service.Error).Fields))
+	})
+
+	t.Run("Every invalid field", func(t *testing.T) {
+		invalid := input
+		invalid.NIK = "182381283182"
+		invalid.BirthDate = "31-01-1990"
+		invalid.Salary = -1
+		invalid.PhoneNumber = "0812"
+
+		_, err := s.Register(context.Background(), invalid)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, apierror.CodeValidationFailed, err.(*service.Error).Code)
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "nik", Code: apierror.CodeInvalidFormat, Message: "NIK must be 16 digits"},
+			{Field: "birth_date", Code: apierror.CodeInvalidFormat, Message: "BirthDate must be a date formatted as YYYY-MM-DD"},
+			{Field: "salary", Code: apierror.CodeOutOfRange, Message: "Salary must not be negative"},
+			{Field: "phone_number", Code: apierror.CodeInvalidFormat, Message: "PhoneNumber must contain 8 to 15 digits"},
+		}, reported(err.(*service.Error).Fields))
+	})
+}
+
+func TestGetProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	s := service.NewCustomerService(mockCustomerRepo, mocks.NewMockCorrectionRepository(ctrl), mocks.NewFakeUnitOfWork(repository.Repositories{}), util.DefaultPasswordPolicy())
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, NIK: "3201010101010001", Password: "hashed"}, nil)
+
+		profile, err := s.GetProfile(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "3201010101010001", profile.NIK)
+	})
+
+	t.Run("Customer not found", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 2).Return(nil, nil)
+
+		_, err := s.GetProfile(context.Background(), 2)
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	s := service.NewCustomerService(mockCustomerRepo, mocks.NewMockCorrectionRepository(ctrl), mocks.NewFakeUnitOfWork(repository.Repositories{}), util.DefaultPasswordPolicy())
+
+	t.Run("Success", func(t *testing.T) {
+		salary := 12000000.0
+		address := " Jl. Sudirman 1 "
+		phoneNumber := "+6281234567890"
+		language := "en"
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, NIK: "3201010101010001", Salary: 5000000, Address: "Old"}, nil)
+		mockCustomerRepo.EXPECT().UpdateCustomerProfile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, customer *model.Customer) error {
+			assert.Equal(t, 12000000.0, customer.Salary)
+			assert.Equal(t, "Jl. Sudirman 1", customer.Address)
+			assert.Equal(t, "+6281234567890", customer.PhoneNumber)
+			assert.Equal(t, "en", customer.Language)
+			assert.Equal(t, "3201010101010001", customer.NIK)
+			return nil
+		})
+
+		profile, err := s.UpdateProfile(context.Background(), 1, model.UpdateProfileRequest{Salary: &salary, Address: &address, PhoneNumber: &phoneNumber, Language: &language})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Jl. Sudirman 1", profile.Address)
+		assert.Equal(t, "en", profile.Language)
+	})
+
+	t.Run("Fields left out stay untouched", func(t *testing.T) {
+		salary := 7000000.0
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, Address: "Jl. Thamrin 2"}, nil)
+		mockCustomerRepo.EXPECT().UpdateCustomerProfile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, customer *model.Customer) error {
+			assert.Equal(t, "Jl. Thamrin 2", customer.Address)
+			return nil
+		})
+
+		_, err := s.UpdateProfile(context.Background(), 1, model.UpdateProfileRequest{Salary: &salary})
+
+		assert.NoError(t, err)
+	})
+
+	negative := -1.0
+	invalidPhoneNumber := "call me"
+	unsupportedLanguage := "jv"
+	for name, update := range map[string]model.UpdateProfileRequest{
+		"Negative salary":      {Salary: &negative},
+		"Invalid phone number": {PhoneNumber: &invalidPhoneNumber},
+		"Unsupported language": {Language: &unsupportedLanguage},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.UpdateProfile(context.Background(), 1, update)
+
+			assert.Equal(t, service.KindValidation, service.KindOf(err))
+		})
+	}
+}
+
+func TestRequestCorrection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCorrectionRepo := mocks.NewMockCorrectionRepository(ctrl)
+	s := service.NewCustomerService(mocks.NewMockCustomerRepository(ctrl), mockCorrectionRepo, mocks.NewFakeUnitOfWork(repository.Repositories{}), util.DefaultPasswordPolicy())
+
+	t.Run("Success", func(t *testing.T) {
+		mockCorrectionRepo.EXPECT().CreateCorrectionRequest(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *model.CorrectionRequest) error {
+			assert.Equal(t, 1, request.CustomerID)
+			assert.Equal(t, model.CorrectionPending, request.Status)
+			request.ID = 5
+			return nil
+		})
+
+		request, err := s.RequestCorrection(context.Background(), 1, service.CorrectionInput{FieldName: "legal_name", RequestedValue: "Alif Ramdein", Reason: "Typo at registration"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, request.ID)
+	})
+
+	tests := []struct {
+		name  string
+		input service.CorrectionInput
+	}{
+		{name: "Field cannot be corrected", input: service.CorrectionInput{FieldName: "salary", RequestedValue: "1", Reason: "raise"}},
+		{name: "Missing value", input: service.CorrectionInput{FieldName: "full_name", RequestedValue: " ", Reason: "Typo"}},
+		{name: "Invalid birth date", input: service.CorrectionInput{FieldName: "birth_date", RequestedValue: "01-02-1990", Reason: "Typo"}},
+		{name: "Invalid NIK", input: service.CorrectionInput{FieldName: "nik", RequestedValue: "32010101", Reason: "Typo"}},
+		{name: "Missing reason", input: service.CorrectionInput{FieldName: "full_name", RequestedValue: "Alif"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RequestCorrection(context.Background(), 1, tt.input)
+
+			assert.Equal(t, service.KindValidation, service.KindOf(err))
+		})
+	}
+}
+
+func TestReviewCorrectionRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	mockCorrectionRepo := mocks.NewMockCorrectionRepository(ctrl)
+	unitOfWork := mocks.NewFakeUnitOfWork(repository.Repositories{Customers: mockCustomerRepo, Corrections: mockCorrectionRepo})
+	s := service.NewCustomerService(mockCustomerRepo, mockCorrectionRepo, unitOfWork, util.DefaultPasswordPolicy())
+
+	t.Run("Approve applies the correction", func(t *testing.T) {
+		mockCorrectionRepo.EXPECT().GetCorrectionRequestByID(gomock.Any(), 5).Return(&model.CorrectionRequest{
+			ID: 5, CustomerID: 1, FieldName: "nik", RequestedValue: "3201010101010002", Status: model.CorrectionPending,
+		}, nil)
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, NIK: "3201010101010001"}, nil)
+		mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "3201010101010002").Return(nil, nil)
+		mockCustomerRepo.EXPECT().UpdateCustomerIdentity(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, customer *model.Customer) error {
+			assert.Equal(t, "3201010101010002", customer.NIK)
+			return nil
+		})
+		mockCorrectionRepo.EXPECT().UpdateCorrectionRequestReview(gomock.Any(), gomock.Any()).Return(true, nil)
+
+		request, err := s.ReviewCorrectionRequest(context.Background(), 99, 5, model.ReviewCorrectionRequest{Status: model.CorrectionApproved})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.CorrectionApproved, request.Status)
+		assert.Equal(t, 99, *request.ReviewedBy)
+		assert.NotNil(t, request.ReviewedAt)
+	})
+
+	t.Run("Approve with NIK already taken", func(t *testing.T) {
+		mockCorrectionRepo.EXPECT().GetCorrectionRequestByID(gomock.Any(), 6).Return(&model.CorrectionRequest{
+			ID: 6, CustomerID: 1, FieldName: "nik", RequestedValue: "3201010101010003", Status: model.CorrectionPending,
+		}, nil)
+		rollbacks := unitOfWork.Rollbacks
+		mockCorrectionRepo.EXPECT().UpdateCorrectionRequestReview(gomock.Any(), gomock.Any()).Return(true, nil)
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		mockCustomerRepo.EXPECT().GetCustomerByNIK(gomock.Any(), "3201010101010003").Return(&model.Customer{ID: 2}, nil)
+
+		_, err := s.ReviewCorrectionRequest(context.Background(), 99, 6, model.ReviewCorrectionRequest{Status: model.CorrectionApproved})
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		// The request stays pending as marking it approved is rolled back
+		assert.Equal(t, rollbacks+1, unitOfWork.Rollbacks)
+	})
+
+	t.Run("Reviewed concurrently", func(t *testing.T) {
+		mockCorrectionRepo.EXPECT().GetCorrectionRequestByID(gomock.Any(), 10).Return(&model.CorrectionRequest{
+			ID: 10, CustomerID: 1, FieldName: "full_name", RequestedValue: "Alif", Status: model.CorrectionPending,
+		}, nil)
+		mockCorrectionRepo.EXPECT().UpdateCorrectionRequestReview(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		_, err := s.ReviewCorrectionRequest(context.Background(), 99, 10, model.ReviewCorrectionRequest{Status: model.CorrectionApproved})
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		assert.Equal(t, apierror.CodeCorrectionAlreadyReviewed, err.(*service.Error).Code)
+	})
+
+	t.Run("Reject leaves the customer untouched", func(t *testing.T) {
+		mockCorrectionRepo.EXPECT().GetCorrectionRequestByID(gomock.Any(), 7).Return(&model.CorrectionRequest{
+			ID: 7, CustomerID: 1, FieldName: "legal_name", RequestedValue: "Someone Else", Status: model.CorrectionPending,
+		}, nil)
+		mockCorrectionRepo.EXPECT().UpdateCorrectionRequestReview(gomock.Any(), gomock.Any()).Return(true, nil)
+
+		request, err := s.ReviewCorrectionRequest(context.Background(), 99, 7, model.ReviewCorrectionRequest{Status: model.CorrectionRejected, Note: "Does not match KTP"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Does not match KTP", request.ReviewNote)
+	})
+
+	t.Run("Already reviewed", func(t *testing.T) {
+		mockCorrectionRepo.EXPECT().GetCorrectionRequestByID(gomock.Any(), 8).Return(&model.CorrectionRequest{
+			ID: 8, Status: model.CorrectionApproved,
+		}, nil)
+
+		_, err := s.ReviewCorrectionRequest(context.Background(), 99, 8, model.ReviewCorrectionRequest{Status: model.CorrectionRejected})
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+	})
+
+	t.Run("Unknown decision", func(t *testing.T) {
+		_, err := s.ReviewCorrectionRequest(context.Background(), 99, 9, model.ReviewCorrectionRequest{Status: model.CorrectionPending})
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+}
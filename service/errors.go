@@ -0,0 +1,91 @@
+package service
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/validation"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kind classifies what went wrong in a service so the transport can report it, e.g. as an HTTP status
+type Kind int
+
+// Kinds of service errors. Errors that are not an *Error are failures of the infrastructure.
+const (
+	// KindValidation is an input that breaks a rule of the domain
+	KindValidation Kind = iota + 1
+	// KindNotFound is a missing resource, or one the caller may not see
+	KindNotFound
+	// KindConflict is an operation the current state of the resource does not allow
+	KindConflict
+	// KindForbidden is an operation the caller is not allowed to perform
+	KindForbidden
+	// KindLimitExceeded is a transaction above the customer's limit
+	KindLimitExceeded
+	// KindExpired is a confirmation code that is no longer valid
+	KindExpired
+	// KindTooManyAttempts is a confirmation or login that failed too often
+	KindTooManyAttempts
+	// KindUnauthenticated is a caller whose credentials are wrong
+	KindUnauthenticated
+)
+
+// Error is an expected failure of a service operation. Code and Message are meant for the
+// caller, Fields lists the invalid fields of a validation error and RetryAfter, when set, is
+// how long the caller has to wait before trying again.
+type Error struct {
+	Kind       Kind
+	Code       apierror.Code
+	Message    string
+	Fields     []apierror.FieldError
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// KindOf returns the kind of a service error, or 0 when err is not one
+func KindOf(err error) Kind {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Kind
+	}
+	return 0
+}
+
+func newError(kind Kind, code apierror.Code, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// newFieldError reports an input whose field breaks a rule, described as by validation.NewFieldError
+func newFieldError(field string, fieldCode apierror.Code, variant string, params i18n.Params) error {
+	return newRuleError(apierror.CodeValidationFailed, field, fieldCode, variant, params)
+}
+
+// newRuleError reports an input rejected with code because one of its fields breaks a rule
+func newRuleError(code apierror.Code, field string, fieldCode apierror.Code, variant string, params i18n.Params) error {
+	fieldErr := validation.NewFieldError(field, fieldCode, variant, params)
+	return &Error{
+		Kind:    KindValidation,
+		Code:    code,
+		Message: fieldErr.Message,
+		Fields:  []apierror.FieldError{fieldErr},
+	}
+}
+
+// validationError reports the invalid fields collected by the validator, or returns nil when there are none
+func validationError(v *validation.Validator) error {
+	var validationErr *validation.Error
+	if !errors.As(v.Err(), &validationErr) {
+		return nil
+	}
+	return &Error{
+		Kind:    KindValidation,
+		Code:    apierror.CodeValidationFailed,
+		Message: validationErr.Error(),
+		Fields:  validationErr.Fields,
+	}
+}
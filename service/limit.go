@@ -0,0 +1,92 @@
+package service
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/validation"
+	"context"
+)
+
+// LimitService manages the credit limits of customers
+type LimitService interface {
+	CreateLimit(ctx context.Context, input CreateLimitInput) (*model.Limit, error)
+}
+
+// CreateLimitInput is the limit granted to a customer for each tenor
+type CreateLimitInput struct {
+	CustomerID int     `json:"customer_id"`
+	Tenor1     float64 `json:"tenor_1"`
+	Tenor2     float64 `json:"tenor_2"`
+	Tenor3     float64 `json:"tenor_3"`
+	Tenor4     float64 `json:"tenor_4"`
+}
+
+// DefaultLimitService is the LimitService backed by the repositories
+type DefaultLimitService struct {
+	LimitRepo    repository.LimitRepository
+	CustomerRepo repository.CustomerRepository
+}
+
+// NewLimitService creates a new instance of DefaultLimitService
+func NewLimitService(limitRepo repository.LimitRepository, customerRepo repository.CustomerRepository) *DefaultLimitService {
+	return &DefaultLimitService{
+		LimitRepo:    limitRepo,
+		CustomerRepo: customerRepo,
+	}
+}
+
+// CreateLimit stores the limit of an existing customer
+func (s *DefaultLimitService) CreateLimit(ctx context.Context, input CreateLimitInput) (*model.Limit, error) {
+	err := validateCreateLimitInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.CustomerRepo.GetCustomerByID(ctx, input.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+
+	limit := &model.Limit{
+		CustomerID: input.CustomerID,
+		Tenor1:     input.Tenor1,
+		Tenor2:     input.Tenor2,
+		Tenor3:     input.Tenor3,
+		Tenor4:     input.Tenor4,
+	}
+	err = s.LimitRepo.CreateLimit(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+func validateCreateLimitInput(input CreateLimitInput) error {
+	v := validation.New()
+	v.RequiredID("customer_id", input.CustomerID)
+	v.NotNegative("tenor_1", input.Tenor1)
+	v.NotNegative("tenor_2", input.Tenor2)
+	v.NotNegative("tenor_3", input.Tenor3)
+	v.NotNegative("tenor_4", input.Tenor4)
+	return validationError(v)
+}
+
+// isWithinLimit checks if the transaction is within the customer's limit based on the tenor
+func isWithinLimit(transaction model.Transaction, limit *model.Limit) bool {
+	switch transaction.Tenor {
+	case 1:
+		return transaction.InstallmentAmount <= limit.Tenor1
+	case 2:
+		return transaction.InstallmentAmount <= limit.Tenor2
+	case 3:
+		return transaction.InstallmentAmount <= limit.Tenor3
+	case 4:
+		return transaction.InstallmentAmount <= limit.Tenor4
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/service"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLimitRepo := mocks.NewMockLimitRepository(ctrl)
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	s := service.NewLimitService(mockLimitRepo, mockCustomerRepo)
+
+	input := service.CreateLimitInput{CustomerID: 1, Tenor1: 1000, Tenor2: 2000, Tenor3: 3000, Tenor4: 4000}
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		mockLimitRepo.EXPECT().CreateLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, limit *model.Limit) error {
+			limit.ID = 3
+			return nil
+		})
+
+		limit, err := s.CreateLimit(context.Background(), input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.Limit{ID: 3, CustomerID: 1, Tenor1: 1000, Tenor2: 2000, Tenor3: 3000, Tenor4: 4000}, *limit)
+	})
+
+	t.Run("Customer not found", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(nil, nil)
+
+		_, err := s.CreateLimit(context.Background(), input)
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		_, err := s.CreateLimit(context.Background(), service.CreateLimitInput{Tenor1: 1000, Tenor2: -1})
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "customer_id", Code: apierror.CodeRequired, Message: "CustomerID is required"},
+			{Field: "tenor_2", Code: apierror.CodeOutOfRange, Message: "Tenor2 must not be negative"},
+		}, reported(err.(*service.Error).Fields))
+	})
+
+	t.Run("Failed to create limit", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		mockLimitRepo.EXPECT().CreateLimit(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		_, err := s.CreateLimit(context.Background(), input)
+
+		assert.Error(t, err)
+		assert.Zero(t, service.KindOf(err))
+	})
+}
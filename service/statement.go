@@ -0,0 +1,174 @@
+package service
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/statement"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatementService records payments and builds statements of account from them
+type StatementService interface {
+	ContractStatement(ctx context.Context, customerID int, contractNumber string) (*statement.Contract, error)
+	MonthlyStatement(ctx context.Context, customerID int, period time.Time) (*statement.Monthly, error)
+	RecordPayment(ctx context.Context, recordedBy int, transactionID int, payment model.Payment) (*model.Payment, error)
+}
+
+// DefaultStatementService is the StatementService backed by the repositories
+type DefaultStatementService struct {
+	TransactionRepo repository.TransactionRepository
+	CustomerRepo    repository.CustomerRepository
+	PaymentRepo     repository.PaymentRepository
+	UnitOfWork      repository.UnitOfWork
+}
+
+// statementPageSize is the number of transactions read at a time for a monthly statement
+const statementPageSize = 100
+
+// NewStatementService creates a new instance of DefaultStatementService
+func NewStatementService(transactionRepo repository.TransactionRepository, customerRepo repository.CustomerRepository,
+	paymentRepo repository.PaymentRepository, unitOfWork repository.UnitOfWork) *DefaultStatementService {
+	return &DefaultStatementService{
+		TransactionRepo: transactionRepo,
+		CustomerRepo:    customerRepo,
+		PaymentRepo:     paymentRepo,
+		UnitOfWork:      unitOfWork,
+	}
+}
+
+// ContractStatement builds the statement of account of one of the customer's booked contracts
+func (s *DefaultStatementService) ContractStatement(ctx context.Context, customerID int, contractNumber string) (*statement.Contract, error) {
+	transaction, err := s.TransactionRepo.GetTransactionByContractNumber(ctx, customerID, contractNumber)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, newError(KindNotFound, apierror.CodeContractNotFound, "Contract not found")
+	}
+	if transaction.Status != model.TransactionConfirmed {
+		return nil, newError(KindConflict, apierror.CodeContractNotBooked, "Contract is not booked")
+	}
+
+	payments, err := s.PaymentRepo.ListPaymentsByTransaction(ctx, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	contract := statement.BuildContract(*transaction, payments, time.Now())
+	return &contract, nil
+}
+
+// MonthlyStatement builds the customer's statement for the month starting at period
+func (s *DefaultStatementService) MonthlyStatement(ctx context.Context, customerID int, period time.Time) (*statement.Monthly, error) {
+	customer, err := s.CustomerRepo.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+
+	transactions, err := s.listBookedTransactions(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.PaymentRepo.ListPaymentsByCustomer(ctx, customerID, period.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	monthly := statement.BuildMonthly(*customer, period, transactions, payments)
+	return &monthly, nil
+}
+
+// listBookedTransactions reads every booked transaction of the customer, oldest first
+func (s *DefaultStatementService) listBookedTransactions(ctx context.Context, customerID int) ([]model.Transaction, error) {
+	filter := model.TransactionFilter{
+		CustomerID: customerID,
+		Status:     model.TransactionConfirmed,
+		Sort:       model.TransactionSortCreatedAt,
+		Limit:      statementPageSize,
+	}
+
+	var transactions []model.Transaction
+	for {
+		page, err := s.TransactionRepo.ListTransactions(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, page...)
+		if len(page) < filter.Limit {
+			return transactions, nil
+		}
+
+		last := page[len(page)-1]
+		filter.After = &model.TransactionCursor{Value: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// RecordPayment records a payment received for a booked transaction. Payments cannot exceed
+// what remains payable on the contract.
+func (s *DefaultStatementService) RecordPayment(ctx context.Context, recordedBy int, transactionID int, payment model.Payment) (*model.Payment, error) {
+	now := time.Now()
+	payment.ID = 0
+	payment.TransactionID = transactionID
+	payment.Reference = strings.TrimSpace(payment.Reference)
+	payment.RecordedBy = recordedBy
+	payment.CreatedAt = now
+	if payment.PaidAt.IsZero() {
+		payment.PaidAt = now
+	}
+
+	err := validatePaymentInput(payment, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction stays locked from reading its payments until the new one is stored, so
+	// concurrent payments cannot together exceed the outstanding amount
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		transaction, err := repos.Transactions.GetTransactionByIDForUpdate(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+		if transaction == nil {
+			return newError(KindNotFound, apierror.CodeTransactionNotFound, "Transaction not found")
+		}
+		if transaction.Status != model.TransactionConfirmed {
+			return newError(KindConflict, apierror.CodeTransactionNotBooked, "Transaction is not booked")
+		}
+
+		payments, err := repos.Payments.ListPaymentsByTransaction(ctx, transactionID)
+		if err != nil {
+			return err
+		}
+		outstanding := statement.BuildContract(*transaction, payments, now).Outstanding
+		if util.RoundCents(payment.Amount) > outstanding {
+			return newRuleError(apierror.CodeAmountExceedsOutstanding, "amount", apierror.CodeOutOfRange, "outstanding",
+				i18n.Params{"outstanding": strconv.FormatFloat(outstanding, 'f', 2, 64)})
+		}
+
+		return repos.Payments.CreatePayment(ctx, &payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func validatePaymentInput(payment model.Payment, now time.Time) error {
+	v := validation.New()
+	v.Positive("amount", payment.Amount)
+	v.MaxLength("reference", payment.Reference, 100)
+	v.Check(!payment.PaidAt.After(now), "paid_at", apierror.CodeOutOfRange, "future", nil)
+	return validationError(v)
+}
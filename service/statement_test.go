@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBookedTransaction() *model.Transaction {
+	confirmedAt := time.Date(2026, 7, 15, 10, 0, 0, 0, time.UTC)
+	return &model.Transaction{ID: 10, CustomerID: 1, ContractNumber: "KTR/001", AssetName: "Honda Vario 160",
+		InstallmentAmount: 4500000, Tenor: 4, Status: model.TransactionConfirmed, ConfirmedAt: &confirmedAt, CreatedAt: confirmedAt}
+}
+
+func TestContractStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockPaymentRepo := mocks.NewMockPaymentRepository(ctrl)
+	s := service.NewStatementService(mockTransactionRepo, mocks.NewMockCustomerRepository(ctrl), mockPaymentRepo,
+		mocks.NewFakeUnitOfWork(repository.Repositories{}))
+
+	t.Run("Success", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByContractNumber(gomock.Any(), 1, "KTR/001").Return(newBookedTransaction(), nil)
+		mockPaymentRepo.EXPECT().ListPaymentsByTransaction(gomock.Any(), 10).Return([]model.Payment{
+			{TransactionID: 10, Amount: 4500000, PaidAt: time.Date(2026, 8, 14, 9, 0, 0, 0, time.UTC)},
+		}, nil)
+
+		contract, err := s.ContractStatement(context.Background(), 1, "KTR/001")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "KTR/001", contract.Transaction.ContractNumber)
+		assert.Equal(t, 13500000.0, contract.Outstanding)
+	})
+
+	t.Run("Unknown contract", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByContractNumber(gomock.Any(), 1, "KTR-404").Return(nil, nil)
+
+		_, err := s.ContractStatement(context.Background(), 1, "KTR-404")
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+		assert.Equal(t, apierror.CodeContractNotFound, err.(*service.Error).Code)
+	})
+
+	t.Run("Pending contract", func(t *testing.T) {
+		pending := newBookedTransaction()
+		pending.Status = model.TransactionPending
+		mockTransactionRepo.EXPECT().GetTransactionByContractNumber(gomock.Any(), 1, "KTR/001").Return(pending, nil)
+
+		_, err := s.ContractStatement(context.Background(), 1, "KTR/001")
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		assert.Equal(t, apierror.CodeContractNotBooked, err.(*service.Error).Code)
+	})
+}
+
+func TestMonthlyStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockCustomerRepo := mocks.NewMockCustomerRepository(ctrl)
+	mockPaymentRepo := mocks.NewMockPaymentRepository(ctrl)
+	s := service.NewStatementService(mockTransactionRepo, mockCustomerRepo, mockPaymentRepo, mocks.NewFakeUnitOfWork(repository.Repositories{}))
+	period := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, FullName: "Budi"}, nil)
+		mockTransactionRepo.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+			assert.Equal(t, 1, filter.CustomerID)
+			assert.Equal(t, model.TransactionConfirmed, filter.Status)
+			return []model.Transaction{*newBookedTransaction()}, nil
+		})
+		mockPaymentRepo.EXPECT().ListPaymentsByCustomer(gomock.Any(), 1, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)).Return([]model.Payment{}, nil)
+
+		monthly, err := s.MonthlyStatement(context.Background(), 1, period)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Budi", monthly.Customer.FullName)
+		assert.Len(t, monthly.Contracts, 1)
+	})
+
+	t.Run("Unknown customer", func(t *testing.T) {
+		mockCustomerRepo.EXPECT().GetCustomerByID(gomock.Any(), 2).Return(nil, nil)
+
+		_, err := s.MonthlyStatement(context.Background(), 2, period)
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+}
+
+func TestRecordPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransactionRepo := mocks.NewMockTransactionRepository(ctrl)
+	mockPaymentRepo := mocks.NewMockPaymentRepository(ctrl)
+	unitOfWork := mocks.NewFakeUnitOfWork(repository.Repositories{Transactions: mockTransactionRepo, Payments: mockPaymentRepo})
+	s := service.NewStatementService(mockTransactionRepo, mocks.NewMockCustomerRepository(ctrl), mockPaymentRepo, unitOfWork)
+	paid := []model.Payment{{TransactionID: 10, Amount: 15000000, PaidAt: time.Date(2026, 8, 14, 9, 0, 0, 0, time.UTC)}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByIDForUpdate(gomock.Any(), 10).Return(newBookedTransaction(), nil)
+		mockPaymentRepo.EXPECT().ListPaymentsByTransaction(gomock.Any(), 10).Return(paid, nil)
+		mockPaymentRepo.EXPECT().CreatePayment(gomock.Any(), gomock.Any()).Return(nil)
+
+		payment, err := s.RecordPayment(context.Background(), 3, 10, model.Payment{Amount: 3000000, Reference: " VA-0002 "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, payment.TransactionID)
+		assert.Equal(t, 3, payment.RecordedBy)
+		assert.Equal(t, "VA-0002", payment.Reference)
+		assert.False(t, payment.PaidAt.IsZero())
+		assert.Equal(t, 1, unitOfWork.Commits)
+	})
+
+	t.Run("Exceeds outstanding", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByIDForUpdate(gomock.Any(), 10).Return(newBookedTransaction(), nil)
+		mockPaymentRepo.EXPECT().ListPaymentsByTransaction(gomock.Any(), 10).Return(paid, nil)
+
+		_, err := s.RecordPayment(context.Background(), 3, 10, model.Payment{Amount: 3000000.01})
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, apierror.CodeAmountExceedsOutstanding, err.(*service.Error).Code)
+		assert.Contains(t, err.Error(), "3000000.00")
+	})
+
+	t.Run("Unknown transaction", func(t *testing.T) {
+		mockTransactionRepo.EXPECT().GetTransactionByIDForUpdate(gomock.Any(), 11).Return(nil, nil)
+
+		_, err := s.RecordPayment(context.Background(), 3, 11, model.Payment{Amount: 100})
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+		assert.Equal(t, apierror.CodeTransactionNotFound, err.(*service.Error).Code)
+	})
+
+	t.Run("Transaction not booked", func(t *testing.T) {
+		pending := newBookedTransaction()
+		pending.Status = model.TransactionPending
+		mockTransactionRepo.EXPECT().GetTransactionByIDForUpdate(gomock.Any(), 10).Return(pending, nil)
+
+		_, err := s.RecordPayment(context.Background(), 3, 10, model.Payment{Amount: 100})
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		assert.Equal(t, apierror.CodeTransactionNotBooked, err.(*service.Error).Code)
+	})
+
+	t.Run("Not positive", func(t *testing.T) {
+		_, err := s.RecordPayment(context.Background(), 3, 10, model.Payment{Amount: 0})
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+
+	t.Run("Paid in the future", func(t *testing.T) {
+		_, err := s.RecordPayment(context.Background(), 3, 10, model.Payment{Amount: 100, PaidAt: time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+}
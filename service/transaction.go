@@ -0,0 +1,605 @@
+package service
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/model"
+	"alif-sigmatech/notifier"
+	"alif-sigmatech/pricing"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TransactionService books, confirms, cancels and lists financing transactions
+type TransactionService interface {
+	BookTransaction(ctx context.Context, actor Actor, input BookTransactionInput) (*model.Transaction, error)
+	ConfirmTransaction(ctx context.Context, actor Actor, id int, code string) (*model.Transaction, error)
+	CancelTransaction(ctx context.Context, actor Actor, id int) (*model.Transaction, error)
+	ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, bool, error)
+}
+
+// Actor is who books or changes a transaction. Exactly one of its fields is set.
+type Actor struct {
+	// CustomerID is the customer acting on their own transactions
+	CustomerID int
+	// Partner acts for the customers who consented to it, on the transactions it created
+	Partner *model.Partner
+	// Officer acts on the transactions of every customer
+	Officer bool
+}
+
+// owns reports whether the actor may see and change the transaction
+func (a Actor) owns(transaction *model.Transaction) bool {
+	switch {
+	case a.Officer:
+		return true
+	case a.Partner != nil:
+		return transaction.PartnerID != nil && *transaction.PartnerID == a.Partner.ID
+	default:
+		return transaction.CustomerID == a.CustomerID
+	}
+}
+
+// cancellableStatuses are the statuses in which the actor may cancel a transaction.
+// Only officers cancel contracts that are already booked.
+func (a Actor) cancellableStatuses() []string {
+	if a.Officer {
+		return []string{model.TransactionPending, model.TransactionConfirmed}
+	}
+	return []string{model.TransactionPending}
+}
+
+// BookTransactionInput is a transaction as requested by the customer or partner. CustomerID is
+// only read for partners; customers always book for themselves.
+type BookTransactionInput struct {
+	CustomerID        int     `json:"customer_id"`
+	ContractNumber    string  `json:"contract_number"`
+	AssetID           int     `json:"asset_id"`
+	OTR               float64 `json:"otr"`
+	DownPayment       float64 `json:"down_payment"`
+	AdminFee          float64 `json:"admin_fee"`
+	InstallmentAmount float64 `json:"installment_amount"`
+	InterestAmount    float64 `json:"interest_amount"`
+	Tenor             int     `json:"tenor"`
+	PromoCode         string  `json:"promo_code"`
+}
+
+// Confirmation of a transaction with a one-time code
+const (
+	TransactionOTPLength      = 6
+	TransactionOTPTTL         = 5 * time.Minute
+	TransactionOTPMaxAttempts = 5
+)
+
+// errLimitExceeded rolls back a transaction that no longer fits the customer's limit when it is stored
+var errLimitExceeded = newError(KindLimitExceeded, apierror.CodeLimitExceeded, "Transaction exceeds limit")
+
+// DefaultTransactionService is the TransactionService backed by the repositories
+type DefaultTransactionService struct {
+	TransactionRepo repository.TransactionRepository
+	LimitRepo       repository.LimitRepository
+	CustomerRepo    repository.CustomerRepository
+	PartnerRepo     repository.PartnerRepository
+	AssetRepo       repository.AssetRepository
+	PromotionRepo   repository.PromotionRepository
+	ContractRepo    repository.ContractDocumentRepository
+	// UnitOfWork stores a transaction together with everything booked with it
+	UnitOfWork    repository.UnitOfWork
+	Pricing       *pricing.Engine
+	Notifier      notifier.Notifier
+	BlobStore     storage.BlobStore
+	EncryptionKey []byte
+	// OTRTolerancePercent is how far the OTR may deviate from the asset's reference price range
+	OTRTolerancePercent float64
+}
+
+// NewTransactionService creates a new instance of DefaultTransactionService
+func NewTransactionService(repo repository.TransactionRepository, limitRepo repository.LimitRepository,
+	customerRepo repository.CustomerRepository, partnerRepo repository.PartnerRepository,
+	assetRepo repository.AssetRepository, promotionRepo repository.PromotionRepository,
+	contractRepo repository.ContractDocumentRepository, unitOfWork repository.UnitOfWork,
+	pricingEngine *pricing.Engine, notifier notifier.Notifier,
+	blobStore storage.BlobStore, encryptionKey []byte, otrTolerancePercent float64) *DefaultTransactionService {
+	return &DefaultTransactionService{
+		TransactionRepo:     repo,
+		LimitRepo:           limitRepo,
+		CustomerRepo:        customerRepo,
+		PartnerRepo:         partnerRepo,
+		AssetRepo:           assetRepo,
+		PromotionRepo:       promotionRepo,
+		ContractRepo:        contractRepo,
+		UnitOfWork:          unitOfWork,
+		Pricing:             pricingEngine,
+		Notifier:            notifier,
+		BlobStore:           blobStore,
+		EncryptionKey:       encryptionKey,
+		OTRTolerancePercent: otrTolerancePercent,
+	}
+}
+
+// BookTransaction checks the asset, the customer's limit and the voucher, stores the transaction
+// as pending with its contract document and sends the customer the one-time code confirming it.
+// The code is sent once the transaction is stored, and a failure to deliver it is only logged.
+// Partners may only book for customers who consented to them. A redeemed voucher stays reserved
+// for the transaction until it is cancelled.
+func (s *DefaultTransactionService) BookTransaction(ctx context.Context, actor Actor, input BookTransactionInput) (*model.Transaction, error) {
+	err := validateBookTransactionInput(actor, input)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &model.Transaction{
+		CustomerID:        actor.CustomerID,
+		ContractNumber:    input.ContractNumber,
+		AssetID:           input.AssetID,
+		OTR:               input.OTR,
+		DownPayment:       input.DownPayment,
+		AdminFee:          input.AdminFee,
+		InstallmentAmount: input.InstallmentAmount,
+		InterestAmount:    input.InterestAmount,
+		Tenor:             input.Tenor,
+		PromoCode:         strings.ToUpper(strings.TrimSpace(input.PromoCode)),
+		Channel:           model.ChannelApp,
+	}
+
+	if actor.Partner != nil {
+		consent, err := s.PartnerRepo.GetConsent(ctx, input.CustomerID, actor.Partner.ID)
+		if err != nil {
+			return nil, err
+		}
+		if consent == nil || consent.RevokedAt != nil {
+			return nil, newError(KindForbidden, apierror.CodeConsentRequired, "Customer has not consented to this partner")
+		}
+
+		transaction.CustomerID = input.CustomerID
+		transaction.Channel = actor.Partner.Channel
+		transaction.PartnerID = &actor.Partner.ID
+	}
+
+	asset, err := s.AssetRepo.GetAssetByID(ctx, transaction.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	if asset == nil || !asset.Active {
+		return nil, newError(KindValidation, apierror.CodeAssetNotAvailable, "Asset not found")
+	}
+
+	err = validateTransactionAsset(*transaction, asset, s.OTRTolerancePercent)
+	if err != nil {
+		return nil, err
+	}
+	transaction.AssetName = asset.Name()
+
+	limit, err := s.LimitRepo.GetLimitByCustomerID(ctx, transaction.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil {
+		return nil, newError(KindNotFound, apierror.CodeLimitNotFound, "Customer limit not found")
+	}
+
+	customer, err := s.CustomerRepo.GetCustomerByID(ctx, transaction.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, newError(KindNotFound, apierror.CodeCustomerNotFound, "Customer not found")
+	}
+
+	now := time.Now()
+	campaign, err := s.voucherCampaign(ctx, *transaction, asset, customer.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.Pricing.Quote(ctx, pricing.Request{
+		Tenor:          transaction.Tenor,
+		AssetCategory:  asset.Category,
+		PartnerID:      transaction.PartnerID,
+		RiskGrade:      customer.RiskGrade,
+		PromoCode:      transaction.PromoCode,
+		FinancedAmount: transaction.OTR - transaction.DownPayment,
+		At:             now,
+	})
+	if err != nil {
+		if errors.Is(err, pricing.ErrNoRule) {
+			return nil, newError(KindValidation, apierror.CodeNoPricingRule, "No pricing applies to this transaction")
+		}
+		return nil, err
+	}
+
+	discount := applyCampaign(quote, campaign)
+	err = applyQuote(transaction, quote)
+	if err != nil {
+		return nil, err
+	}
+	transaction.DiscountAmount = discount
+
+	// Check customer limit against the installment derived from the quote
+	if !isWithinLimit(*transaction, limit) {
+		metrics.LimitRejections.WithLabelValues(metrics.Tenor(transaction.Tenor)).Inc()
+		return nil, errLimitExceeded
+	}
+
+	code, err := util.RandomDigits(TransactionOTPLength)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(TransactionOTPTTL)
+	transaction.Status = model.TransactionPending
+	transaction.OTPHash = hashToken(code)
+	transaction.OTPExpiresAt = &expiresAt
+	transaction.CreatedAt = now
+
+	// The contract document is rendered and stored before the unit of work, which may run more
+	// than once, and deleted again when the transaction is not booked
+	document, err := StoreContractDocument(s.BlobStore, s.EncryptionKey, *customer, *limit, *transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction, its voucher redemption and the record of its contract document are stored
+	// together, checked against the limit as it is when they are stored
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		limit, err := repos.Limits.GetLimitByCustomerIDForUpdate(ctx, transaction.CustomerID)
+		if err != nil {
+			return err
+		}
+		if limit == nil || !isWithinLimit(*transaction, limit) {
+			return errLimitExceeded
+		}
+
+		err = repos.Transactions.CreateTransaction(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		if campaign != nil {
+			err = repos.Promotions.RedeemVoucher(ctx, &model.PromoRedemption{
+				CampaignID:     campaign.ID,
+				CustomerID:     customer.ID,
+				TransactionID:  transaction.ID,
+				DiscountAmount: discount,
+				RedeemedAt:     now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		document.TransactionID = transaction.ID
+		return repos.Contracts.CreateContractDocument(ctx, document)
+	})
+	if err != nil {
+		DiscardContractDocument(s.BlobStore, document)
+	}
+	switch {
+	case errors.Is(err, errLimitExceeded):
+		metrics.LimitRejections.WithLabelValues(metrics.Tenor(transaction.Tenor)).Inc()
+		return nil, err
+	case errors.Is(err, repository.ErrVoucherUnavailable):
+		return nil, newError(KindConflict, apierror.CodeVoucherUnavailable, "Voucher is no longer available")
+	case errors.Is(err, repository.ErrVoucherAlreadyUsed):
+		return nil, newError(KindConflict, apierror.CodeVoucherAlreadyUsed, "Voucher was already used")
+	case errors.Is(err, util.ErrDuplicate):
+		return nil, newError(KindConflict, apierror.CodeContractNumberExists, "Contract number is already used")
+	case err != nil:
+		return nil, err
+	}
+
+	err = s.Notifier.Notify(notifier.Message{
+		CustomerID: customer.ID,
+		Recipient:  customer.PhoneNumber,
+		Subject:    "Contract confirmation",
+		Body: fmt.Sprintf("Use this code within %d minutes to confirm contract %s: %s",
+			int(TransactionOTPTTL.Minutes()), transaction.ContractNumber, code),
+	})
+	if err != nil {
+		// The transaction is already booked, so failing here would hide it from the client, who
+		// can still cancel it or let the code expire
+		logrus.Errorf("Sending the confirmation code of transaction %d: %v", transaction.ID, err)
+	}
+
+	return transaction, nil
+}
+
+// voucherCampaign returns the campaign of the transaction's voucher code after checking the customer
+// may redeem it for the transaction, or nil when the transaction has no voucher code
+func (s *DefaultTransactionService) voucherCampaign(ctx context.Context, transaction model.Transaction, asset *model.Asset,
+	customerID int, at time.Time) (*model.Campaign, error) {
+	if transaction.PromoCode == "" {
+		return nil, nil
+	}
+
+	campaign, err := s.PromotionRepo.GetCampaignByCode(ctx, transaction.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil || !isCampaignRunning(campaign, at) {
+		return nil, newError(KindValidation, apierror.CodeInvalidVoucher, "Voucher code is not valid")
+	}
+
+	err = validateCampaignEligibility(transaction, asset, campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemed, err := s.PromotionRepo.HasRedeemed(ctx, campaign.ID, customerID, at)
+	if err != nil {
+		return nil, err
+	}
+	if redeemed {
+		return nil, newError(KindConflict, apierror.CodeVoucherAlreadyUsed, "Voucher was already used")
+	}
+	return campaign, nil
+}
+
+// ConfirmTransaction checks the one-time code of a pending transaction and books it.
+// Transactions the actor does not own are reported as missing so their IDs cannot be probed.
+func (s *DefaultTransactionService) ConfirmTransaction(ctx context.Context, actor Actor, id int, code string) (*model.Transaction, error) {
+	v := validation.New()
+	v.Digits("code", code, TransactionOTPLength)
+	err := validationError(v)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.getTransaction(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status != model.TransactionPending {
+		return nil, newError(KindConflict, apierror.CodeTransactionNotPending, "Transaction is not pending confirmation")
+	}
+
+	now := time.Now()
+	if transaction.OTPExpiresAt == nil || now.After(*transaction.OTPExpiresAt) {
+		return nil, newError(KindExpired, apierror.CodeConfirmationCodeExpired, "Confirmation code has expired")
+	}
+
+	// The customer must have accepted the terms before the code can book the contract
+	document, err := s.ContractRepo.GetContractDocumentByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil || document.AcceptedAt == nil {
+		return nil, newError(KindConflict, apierror.CodeContractNotAccepted, "Contract document has not been accepted")
+	}
+
+	reserved, err := s.TransactionRepo.ReserveOTPAttempt(ctx, transaction.ID, TransactionOTPMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, newError(KindTooManyAttempts, apierror.CodeTooManyConfirmationAttempts, "Too many failed confirmation attempts")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(code)), []byte(transaction.OTPHash)) != 1 {
+		return nil, newError(KindValidation, apierror.CodeInvalidConfirmationCode, "Invalid confirmation code")
+	}
+
+	confirmed, err := s.TransactionRepo.ConfirmTransaction(ctx, transaction.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, newError(KindConflict, apierror.CodeTransactionNotPending, "Transaction is not pending confirmation")
+	}
+
+	transaction.Status = model.TransactionConfirmed
+	transaction.OTPHash = ""
+	transaction.ConfirmedAt = &now
+
+	tenor := metrics.Tenor(transaction.Tenor)
+	metrics.ContractsBooked.WithLabelValues(tenor).Inc()
+	metrics.AmountFinanced.WithLabelValues(tenor).Add(transaction.OTR - transaction.DownPayment)
+	return transaction, nil
+}
+
+// CancelTransaction cancels a transaction that is in one of the statuses the actor may cancel.
+// The voucher redemption of the transaction is reversed together with the cancellation.
+func (s *DefaultTransactionService) CancelTransaction(ctx context.Context, actor Actor, id int) (*model.Transaction, error) {
+	transaction, err := s.getTransaction(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cancellableStatuses := actor.cancellableStatuses()
+	cancellable := false
+	for _, status := range cancellableStatuses {
+		if transaction.Status == status {
+			cancellable = true
+			break
+		}
+	}
+	if !cancellable {
+		return nil, newError(KindConflict, apierror.CodeTransactionNotCancellable, "Transaction cannot be cancelled")
+	}
+
+	now := time.Now()
+	cancelled, err := s.TransactionRepo.CancelTransaction(ctx, transaction.ID, cancellableStatuses, now)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, newError(KindConflict, apierror.CodeTransactionNotCancellable, "Transaction cannot be cancelled")
+	}
+
+	transaction.Status = model.TransactionCancelled
+	transaction.OTPHash = ""
+	transaction.CancelledAt = &now
+	return transaction, nil
+}
+
+// ListTransactions returns up to filter.Limit transactions selected by the filter and whether
+// there are more behind the last one
+func (s *DefaultTransactionService) ListTransactions(ctx context.Context, filter model.TransactionFilter) ([]model.Transaction, bool, error) {
+	// One extra row tells whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+
+	transactions, err := s.TransactionRepo.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(transactions) > pageSize {
+		return transactions[:pageSize], true, nil
+	}
+	return transactions, false, nil
+}
+
+func (s *DefaultTransactionService) getTransaction(ctx context.Context, actor Actor, id int) (*model.Transaction, error) {
+	transaction, err := s.TransactionRepo.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil || !actor.owns(transaction) {
+		return nil, newError(KindNotFound, apierror.CodeTransactionNotFound, "Transaction not found")
+	}
+	return transaction, nil
+}
+
+// validateBookTransactionInput checks the rules of a transaction that do not depend on the financed asset
+func validateBookTransactionInput(actor Actor, input BookTransactionInput) error {
+	v := validation.New()
+	if actor.Partner != nil {
+		v.RequiredID("customer_id", input.CustomerID)
+	}
+	if v.Required("contract_number", input.ContractNumber) {
+		v.MaxLength("contract_number", input.ContractNumber, 100)
+	}
+	v.RequiredID("asset_id", input.AssetID)
+	v.Between("tenor", float64(input.Tenor), 1, 4)
+
+	// The down payment is bounded by the OTR, so it is only compared to a valid one
+	if v.Positive("otr", input.OTR) && v.NotNegative("down_payment", input.DownPayment) {
+		v.Check(input.DownPayment <= input.OTR, "down_payment", apierror.CodeOutOfRange, "otr", nil)
+	}
+
+	v.NotNegative("admin_fee", input.AdminFee)
+	v.NotNegative("installment_amount", input.InstallmentAmount)
+	v.NotNegative("interest_amount", input.InterestAmount)
+	v.MaxLength("promo_code", strings.TrimSpace(input.PromoCode), 50)
+	return validationError(v)
+}
+
+// validateTransactionAsset checks the tenor, OTR and down payment of a transaction against the financed asset
+func validateTransactionAsset(transaction model.Transaction, asset *model.Asset, otrTolerancePercent float64) error {
+	allowed := false
+	for _, tenor := range asset.AllowedTenors {
+		if tenor == transaction.Tenor {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return newFieldError("tenor", apierror.CodeNotAllowed, "tenor_for_asset",
+			i18n.Params{"tenor": strconv.Itoa(transaction.Tenor), "asset": asset.Name()})
+	}
+
+	minOTR := util.RoundCents(asset.MinOTR * (1 - otrTolerancePercent/100))
+	maxOTR := util.RoundCents(asset.MaxOTR * (1 + otrTolerancePercent/100))
+	if transaction.OTR < minOTR || transaction.OTR > maxOTR {
+		return newFieldError("otr", apierror.CodeOutOfRange, "between_for_asset",
+			i18n.Params{"min": formatAmount(minOTR), "max": formatAmount(maxOTR), "asset": asset.Name()})
+	}
+
+	minDownPayment := util.RoundCents(transaction.OTR * (1 - asset.MaxFinancePercent/100))
+	if transaction.DownPayment < minDownPayment {
+		return newFieldError("down_payment", apierror.CodeOutOfRange, "min_for_asset",
+			i18n.Params{"min": formatAmount(minDownPayment), "asset": asset.Name()})
+	}
+
+	return nil
+}
+
+// applyQuote sets the computed admin fee, interest and installment on the transaction. The
+// installment repays the financed amount, the interest and the admin fee in equal parts over the
+// tenor. Amounts sent by the client are optional, but when given they must agree with the quote.
+func applyQuote(transaction *model.Transaction, quote *pricing.Quote) error {
+	if transaction.AdminFee != 0 && util.RoundCents(transaction.AdminFee) != quote.AdminFee {
+		return newFieldError("admin_fee", apierror.CodeMismatch, "expected", i18n.Params{"expected": formatAmount(quote.AdminFee)})
+	}
+	if transaction.InterestAmount != 0 && util.RoundCents(transaction.InterestAmount) != quote.InterestAmount {
+		return newFieldError("interest_amount", apierror.CodeMismatch, "expected", i18n.Params{"expected": formatAmount(quote.InterestAmount)})
+	}
+	installment := util.RoundCents((transaction.OTR - transaction.DownPayment + quote.InterestAmount + quote.AdminFee) / float64(transaction.Tenor))
+	if transaction.InstallmentAmount != 0 && util.RoundCents(transaction.InstallmentAmount) != installment {
+		return newFieldError("installment_amount", apierror.CodeMismatch, "expected", i18n.Params{"expected": formatAmount(installment)})
+	}
+
+	transaction.AdminFee = quote.AdminFee
+	transaction.InterestAmount = quote.InterestAmount
+	transaction.InstallmentAmount = installment
+	transaction.PricingRuleID = &quote.Rule.ID
+	transaction.PricingRuleVersion = quote.Rule.Version
+	return nil
+}
+
+// isCampaignRunning reports whether the campaign accepts vouchers at the given time
+func isCampaignRunning(campaign *model.Campaign, at time.Time) bool {
+	return campaign.Active && !at.Before(campaign.StartsAt) && (campaign.EndsAt == nil || at.Before(*campaign.EndsAt))
+}
+
+// validateCampaignEligibility checks the transaction against the eligibility rules of the campaign
+func validateCampaignEligibility(transaction model.Transaction, asset *model.Asset, campaign *model.Campaign) error {
+	if campaign.Tenor != 0 && campaign.Tenor != transaction.Tenor {
+		return newRuleError(apierror.CodeVoucherNotEligible, "promo_code", apierror.CodeNotAllowed, "voucher_tenor",
+			i18n.Params{"tenor": strconv.Itoa(campaign.Tenor)})
+	}
+	if campaign.AssetCategory != "" && campaign.AssetCategory != asset.Category {
+		return newRuleError(apierror.CodeVoucherNotEligible, "promo_code", apierror.CodeNotAllowed, "voucher_asset_category",
+			i18n.Params{"category": campaign.AssetCategory})
+	}
+	if campaign.Channel != "" && campaign.Channel != transaction.Channel {
+		return newRuleError(apierror.CodeVoucherNotEligible, "promo_code", apierror.CodeNotAllowed, "voucher_channel",
+			i18n.Params{"channel": campaign.Channel})
+	}
+	if transaction.OTR-transaction.DownPayment < campaign.MinFinancedAmount {
+		return newRuleError(apierror.CodeVoucherNotEligible, "promo_code", apierror.CodeOutOfRange, "voucher_min_financed_amount",
+			i18n.Params{"min": formatAmount(campaign.MinFinancedAmount)})
+	}
+	return nil
+}
+
+// applyCampaign takes the campaign's discounts off the quote and returns the total discount.
+// A nil campaign leaves the quote unchanged.
+func applyCampaign(quote *pricing.Quote, campaign *model.Campaign) float64 {
+	if campaign == nil {
+		return 0
+	}
+
+	adminFeeDiscount := util.RoundCents(quote.AdminFee * campaign.AdminFeeDiscountPercent / 100)
+	interestDiscount := util.RoundCents(quote.InterestAmount * campaign.InterestDiscountPercent / 100)
+	quote.AdminFee = util.RoundCents(quote.AdminFee - adminFeeDiscount)
+	quote.InterestAmount = util.RoundCents(quote.InterestAmount - interestDiscount)
+
+	return util.RoundCents(adminFeeDiscount + interestDiscount)
+}
+
+// formatAmount writes an amount of money in messages
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// hashToken returns the hex encoded SHA-256 hash under which a one-time code is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,869 @@
+package service_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/contract"
+	"alif-sigmatech/metrics"
+	"alif-sigmatech/mocks"
+	"alif-sigmatech/model"
+	"alif-sigmatech/notifier"
+	"alif-sigmatech/pricing"
+	"alif-sigmatech/repository"
+	"alif-sigmatech/service"
+	"alif-sigmatech/storage"
+	"alif-sigmatech/util"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEncryptionKey = []byte("0123456789abcdef")
+
+// recordingNotifier keeps every message it is asked to deliver and fails with err when it is set
+type recordingNotifier struct {
+	messages []notifier.Message
+	err      error
+}
+
+func (n *recordingNotifier) Notify(message notifier.Message) error {
+	n.messages = append(n.messages, message)
+	return n.err
+}
+
+// reported keeps what clients see of field errors, dropping the templates they are translated from
+func reported(fields []apierror.FieldError) []apierror.FieldError {
+	reported := make([]apierror.FieldError, len(fields))
+	for i, field := range fields {
+		reported[i] = apierror.FieldError{Field: field.Field, Code: field.Code, Message: field.Message}
+	}
+	return reported
+}
+
+func newTestAsset() *model.Asset {
+	return &model.Asset{
+		ID:                1,
+		Category:          "motorcycle",
+		Brand:             "Honda",
+		Model:             "Vario 160",
+		MinOTR:            19000000,
+		MaxOTR:            21000000,
+		MaxFinancePercent: 80,
+		AllowedTenors:     []int{1, 2, 3, 4},
+		Active:            true,
+	}
+}
+
+func newTestCampaign() *model.Campaign {
+	return &model.Campaign{
+		ID:                      7,
+		Code:                    "ZEROFEE",
+		Name:                    "Zero admin fee",
+		AdminFeeDiscountPercent: 100,
+		InterestDiscountPercent: 50,
+		AssetCategory:           "motorcycle",
+		StartsAt:                time.Now().Add(-time.Hour),
+		Active:                  true,
+	}
+}
+
+// newTestPricing prices every transaction with a 100000 admin fee and 2% monthly interest
+func newTestPricing(ctrl *gomock.Controller) *pricing.Engine {
+	mockPricingRuleRepo := mocks.NewMockPricingRuleRepository(ctrl)
+	mockPricingRuleRepo.EXPECT().ListEffectivePricingRules(gomock.Any(), gomock.Any()).Return([]model.PricingRule{{
+		ID:                     1,
+		Code:                   "DEFAULT",
+		Version:                2,
+		AdminFee:               100000,
+		MonthlyInterestPercent: 2,
+		EffectiveFrom:          time.Now().Add(-time.Hour),
+	}}, nil).AnyTimes()
+	return pricing.NewEngine(mockPricingRuleRepo)
+}
+
+func newTestBlobStore(t *testing.T) storage.BlobStore {
+	blobStore, err := storage.NewFileSystemBlobStore(t.TempDir())
+	assert.NoError(t, err)
+	return blobStore
+}
+
+// countBlobs returns how many blobs are kept in a file system blob store
+func countBlobs(t *testing.T, blobStore storage.BlobStore) int {
+	count := 0
+	err := filepath.WalkDir(blobStore.(*storage.FileSystemBlobStore).BaseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			count++
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	return count
+}
+
+// acceptContracts lets the contract document repository store every rendered document
+// and report it as accepted
+func acceptContracts(contractRepo *mocks.MockContractDocumentRepository) {
+	acceptedAt := time.Now()
+	contractRepo.EXPECT().CreateContractDocument(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	contractRepo.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, transactionID int) (*model.ContractDocument, error) {
+		return &model.ContractDocument{ID: 1, TransactionID: transactionID, Template: contract.CurrentTemplate, AcceptedAt: &acceptedAt}, nil
+	}).AnyTimes()
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// testTransactionService is a DefaultTransactionService on mocked repositories. Asset 1 is
+// newTestAsset and every transaction is priced by newTestPricing.
+type testTransactionService struct {
+	*service.DefaultTransactionService
+	transactions *mocks.MockTransactionRepository
+	limits       *mocks.MockLimitRepository
+	customers    *mocks.MockCustomerRepository
+	partners     *mocks.MockPartnerRepository
+	assets       *mocks.MockAssetRepository
+	promotions   *mocks.MockPromotionRepository
+	contracts    *mocks.MockContractDocumentRepository
+	unitOfWork   *mocks.FakeUnitOfWork
+	notifier     *recordingNotifier
+}
+
+func newTestTransactionService(t *testing.T, ctrl *gomock.Controller) *testTransactionService {
+	s := &testTransactionService{
+		transactions: mocks.NewMockTransactionRepository(ctrl),
+		limits:       mocks.NewMockLimitRepository(ctrl),
+		customers:    mocks.NewMockCustomerRepository(ctrl),
+		partners:     mocks.NewMockPartnerRepository(ctrl),
+		assets:       mocks.NewMockAssetRepository(ctrl),
+		promotions:   mocks.NewMockPromotionRepository(ctrl),
+		contracts:    mocks.NewMockContractDocumentRepository(ctrl),
+		notifier:     &recordingNotifier{},
+	}
+	s.assets.EXPECT().GetAssetByID(gomock.Any(), 1).Return(newTestAsset(), nil).AnyTimes()
+	s.unitOfWork = mocks.NewFakeUnitOfWork(repository.Repositories{
+		Transactions: s.transactions,
+		Limits:       s.limits,
+		Promotions:   s.promotions,
+		Contracts:    s.contracts,
+	})
+	s.DefaultTransactionService = service.NewTransactionService(s.transactions, s.limits, s.customers, s.partners, s.assets,
+		s.promotions, s.contracts, s.unitOfWork, newTestPricing(ctrl), s.notifier, newTestBlobStore(t), testEncryptionKey, 5)
+	return s
+}
+
+// expectLimit grants the customer the limit, both when it is checked and when the transaction is stored
+func (s *testTransactionService) expectLimit(limit *model.Limit) {
+	s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), limit.CustomerID).Return(limit, nil)
+	s.limits.EXPECT().GetLimitByCustomerIDForUpdate(gomock.Any(), limit.CustomerID).Return(limit, nil)
+}
+
+func TestBookTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	acceptContracts(s.contracts)
+	customer := service.Actor{CustomerID: 1}
+	input := service.BookTransactionInput{
+		CustomerID:     2,
+		ContractNumber: "KTR-001",
+		AssetID:        1,
+		OTR:            20000000,
+		DownPayment:    4000000,
+		Tenor:          1,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		var stored *model.Transaction
+		s.expectLimit(&model.Limit{CustomerID: 1, Tenor1: 20000000, Tenor2: 700000, Tenor3: 900000, Tenor4: 1100000})
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, PhoneNumber: "+6281234567890"}, nil)
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, transaction *model.Transaction) error {
+			stored = transaction
+			transaction.ID = 10
+			return nil
+		})
+
+		transaction, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 10, transaction.ID)
+		// The customer is the actor, not the one in the input
+		assert.Equal(t, 1, stored.CustomerID)
+		assert.Equal(t, model.ChannelApp, stored.Channel)
+		assert.Nil(t, stored.PartnerID)
+		assert.Equal(t, model.TransactionPending, stored.Status)
+		assert.Equal(t, "Honda Vario 160", stored.AssetName)
+		// 16,000,000 financed plus 320,000 interest and the 100,000 admin fee over one month
+		assert.Equal(t, 16420000.0, stored.InstallmentAmount)
+
+		assert.Len(t, s.notifier.messages, 1)
+		assert.Equal(t, "+6281234567890", s.notifier.messages[0].Recipient)
+		code := regexp.MustCompile(`[0-9]{6}$`).FindString(s.notifier.messages[0].Body)
+		assert.Len(t, code, service.TransactionOTPLength)
+		assert.Equal(t, hashCode(code), stored.OTPHash)
+	})
+
+	t.Run("Limit not found", func(t *testing.T) {
+		s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(nil, nil)
+
+		_, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+
+	t.Run("Transaction exceeds limit", func(t *testing.T) {
+		rejections := testutil.ToFloat64(metrics.LimitRejections.WithLabelValues("1"))
+		s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor1: 10000000}, nil)
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+
+		_, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.Equal(t, service.KindLimitExceeded, service.KindOf(err))
+		assert.Equal(t, rejections+1, testutil.ToFloat64(metrics.LimitRejections.WithLabelValues("1")))
+	})
+
+	t.Run("Limit lowered before the transaction is stored", func(t *testing.T) {
+		rollbacks := s.unitOfWork.Rollbacks
+		rejections := testutil.ToFloat64(metrics.LimitRejections.WithLabelValues("1"))
+		blobs := countBlobs(t, s.BlobStore)
+		s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor1: 20000000}, nil)
+		s.limits.EXPECT().GetLimitByCustomerIDForUpdate(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor1: 10000000}, nil)
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+
+		_, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.Equal(t, service.KindLimitExceeded, service.KindOf(err))
+		assert.Equal(t, rollbacks+1, s.unitOfWork.Rollbacks)
+		assert.Equal(t, rejections+1, testutil.ToFloat64(metrics.LimitRejections.WithLabelValues("1")))
+		// The contract document of the transaction that was not booked is deleted again
+		assert.Equal(t, blobs, countBlobs(t, s.BlobStore))
+	})
+
+	t.Run("Error from GetLimitByCustomerID", func(t *testing.T) {
+		s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(nil, errors.New("database error"))
+
+		_, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.Error(t, err)
+		assert.Zero(t, service.KindOf(err))
+	})
+
+	t.Run("Error from CreateTransaction", func(t *testing.T) {
+		s.expectLimit(&model.Limit{CustomerID: 1, Tenor1: 20000000})
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+
+		_, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.Error(t, err)
+		assert.Zero(t, service.KindOf(err))
+	})
+
+	t.Run("Code not delivered", func(t *testing.T) {
+		commits := s.unitOfWork.Commits
+		s.expectLimit(&model.Limit{CustomerID: 1, Tenor1: 20000000})
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+		s.notifier.err = errors.New("gateway unavailable")
+		defer func() { s.notifier.err = nil }()
+
+		transaction, err := s.BookTransaction(context.Background(), customer, input)
+
+		// The booked transaction is still returned, as it was committed before the code was sent
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionPending, transaction.Status)
+		assert.Equal(t, commits+1, s.unitOfWork.Commits)
+	})
+
+	t.Run("Contract number already used", func(t *testing.T) {
+		s.expectLimit(&model.Limit{CustomerID: 1, Tenor1: 20000000})
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(fmt.Errorf("%w: Duplicate entry", util.ErrDuplicate))
+
+		_, err := s.BookTransaction(context.Background(), customer, input)
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		assert.Equal(t, apierror.CodeContractNumberExists, err.(*service.Error).Code)
+	})
+}
+
+func TestBookTransactionAssetValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	inactive := newTestAsset()
+	inactive.ID = 2
+	inactive.Active = false
+	restricted := newTestAsset()
+	restricted.ID = 4
+	restricted.AllowedTenors = []int{1, 2}
+	s.assets.EXPECT().GetAssetByID(gomock.Any(), 2).Return(inactive, nil).AnyTimes()
+	s.assets.EXPECT().GetAssetByID(gomock.Any(), 3).Return(nil, nil).AnyTimes()
+	s.assets.EXPECT().GetAssetByID(gomock.Any(), 4).Return(restricted, nil).AnyTimes()
+
+	tests := []struct {
+		name  string
+		input service.BookTransactionInput
+	}{
+		{name: "Missing asset", input: service.BookTransactionInput{ContractNumber: "KTR-001", OTR: 20000000, DownPayment: 4000000, Tenor: 1}},
+		{name: "Unknown asset", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 3, OTR: 20000000, DownPayment: 4000000, Tenor: 1}},
+		{name: "Deactivated asset", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 2, OTR: 20000000, DownPayment: 4000000, Tenor: 1}},
+		{name: "Above tolerance", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 4, OTR: 22050001, DownPayment: 5000000, Tenor: 1}},
+		{name: "Below tolerance", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 4, OTR: 18000000, DownPayment: 4000000, Tenor: 1}},
+		{name: "Down payment too low", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 4, OTR: 20000000, DownPayment: 3999999, Tenor: 1}},
+		{name: "Down payment above OTR", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 4, OTR: 20000000, DownPayment: 20000001, Tenor: 1}},
+		{name: "Tenor not allowed", input: service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 4, OTR: 20000000, DownPayment: 4000000, Tenor: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, tt.input)
+
+			assert.Equal(t, service.KindValidation, service.KindOf(err))
+		})
+	}
+
+	t.Run("Within tolerance", func(t *testing.T) {
+		// Passing the asset checks leads on to the limit
+		s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(nil, nil)
+
+		_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1},
+			service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 4, OTR: 22050000, DownPayment: 4410000, Tenor: 2})
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+}
+
+func TestBookTransactionInputValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Inputs breaking a rule are rejected before any repository is used
+	s := newTestTransactionService(t, ctrl)
+	valid := service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 1, OTR: 20000000, DownPayment: 4000000, Tenor: 1}
+
+	tests := []struct {
+		name   string
+		change func(input *service.BookTransactionInput)
+		field  apierror.FieldError
+	}{
+		{
+			name:   "Missing contract number",
+			change: func(input *service.BookTransactionInput) { input.ContractNumber = " " },
+			field:  apierror.FieldError{Field: "contract_number", Code: apierror.CodeRequired, Message: "ContractNumber is required"},
+		},
+		{
+			name:   "Zero OTR",
+			change: func(input *service.BookTransactionInput) { input.OTR = 0 },
+			field:  apierror.FieldError{Field: "otr", Code: apierror.CodeOutOfRange, Message: "OTR must be positive"},
+		},
+		{
+			name:   "Tenor out of range",
+			change: func(input *service.BookTransactionInput) { input.Tenor = 7 },
+			field:  apierror.FieldError{Field: "tenor", Code: apierror.CodeOutOfRange, Message: "Tenor must be between 1 and 4"},
+		},
+		{
+			name:   "Negative down payment",
+			change: func(input *service.BookTransactionInput) { input.DownPayment = -1 },
+			field:  apierror.FieldError{Field: "down_payment", Code: apierror.CodeOutOfRange, Message: "DownPayment must not be negative"},
+		},
+		{
+			name:   "Negative installment",
+			change: func(input *service.BookTransactionInput) { input.InstallmentAmount = -1 },
+			field:  apierror.FieldError{Field: "installment_amount", Code: apierror.CodeOutOfRange, Message: "InstallmentAmount must not be negative"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.change(&input)
+
+			_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, input)
+
+			assert.Equal(t, service.KindValidation, service.KindOf(err))
+			assert.Equal(t, []apierror.FieldError{tt.field}, reported(err.(*service.Error).Fields))
+		})
+	}
+
+	t.Run("Every invalid field", func(t *testing.T) {
+		_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, service.BookTransactionInput{Tenor: 7})
+
+		assert.Equal(t, apierror.CodeValidationFailed, err.(*service.Error).Code)
+		var fields []string
+		for _, field := range err.(*service.Error).Fields {
+			fields = append(fields, field.Field)
+		}
+		assert.Equal(t, []string{"contract_number", "asset_id", "tenor", "otr"}, fields)
+	})
+}
+
+func TestBookTransactionPricing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	acceptContracts(s.contracts)
+	s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor2: 10000000}, nil).AnyTimes()
+	s.limits.EXPECT().GetLimitByCustomerIDForUpdate(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor2: 10000000}, nil).AnyTimes()
+	s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil).AnyTimes()
+
+	input := service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 1, OTR: 20000000, DownPayment: 5000000, Tenor: 2}
+
+	t.Run("Computed when omitted", func(t *testing.T) {
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+
+		transaction, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 100000.0, transaction.AdminFee)
+		assert.Equal(t, 600000.0, transaction.InterestAmount)
+		assert.Equal(t, 7850000.0, transaction.InstallmentAmount)
+		assert.Equal(t, 1, *transaction.PricingRuleID)
+		assert.Equal(t, 2, transaction.PricingRuleVersion)
+	})
+
+	t.Run("Matching client values", func(t *testing.T) {
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+
+		matching := input
+		matching.AdminFee = 100000
+		matching.InterestAmount = 600000
+		matching.InstallmentAmount = 7850000
+		_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, matching)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Client interest disagrees", func(t *testing.T) {
+		disagreeing := input
+		disagreeing.InterestAmount = 1
+		_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, disagreeing)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Contains(t, err.Error(), "600000.00")
+	})
+
+	t.Run("Client installment disagrees", func(t *testing.T) {
+		disagreeing := input
+		disagreeing.InstallmentAmount = 8000000
+		_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, disagreeing)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, "installment_amount", err.(*service.Error).Fields[0].Field)
+		assert.Contains(t, err.Error(), "7850000.00")
+	})
+}
+
+func TestBookTransactionWithVoucher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	acceptContracts(s.contracts)
+	s.limits.EXPECT().GetLimitByCustomerID(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor2: 10000000}, nil).AnyTimes()
+	s.limits.EXPECT().GetLimitByCustomerIDForUpdate(gomock.Any(), 1).Return(&model.Limit{CustomerID: 1, Tenor2: 10000000}, nil).AnyTimes()
+	s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil).AnyTimes()
+
+	input := service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 1, OTR: 20000000, DownPayment: 5000000, Tenor: 2, PromoCode: " zerofee "}
+	book := func(input service.BookTransactionInput) (*model.Transaction, error) {
+		return s.BookTransaction(context.Background(), service.Actor{CustomerID: 1}, input)
+	}
+
+	t.Run("Discount applied and redeemed", func(t *testing.T) {
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(newTestCampaign(), nil)
+		s.promotions.EXPECT().HasRedeemed(gomock.Any(), 7, 1, gomock.Any()).Return(false, nil)
+		// The redemption is recorded for the transaction stored before it in the same unit of work
+		created := s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, transaction *model.Transaction) error {
+			assert.Equal(t, model.TransactionPending, transaction.Status)
+			transaction.ID = 10
+			return nil
+		})
+		s.promotions.EXPECT().RedeemVoucher(gomock.Any(), gomock.Any()).After(created).DoAndReturn(func(_ context.Context, redemption *model.PromoRedemption) error {
+			assert.Equal(t, 7, redemption.CampaignID)
+			assert.Equal(t, 1, redemption.CustomerID)
+			assert.Equal(t, 10, redemption.TransactionID)
+			assert.Equal(t, 400000.0, redemption.DiscountAmount)
+			return nil
+		})
+
+		transaction, err := book(input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ZEROFEE", transaction.PromoCode)
+		assert.Equal(t, 0.0, transaction.AdminFee)
+		assert.Equal(t, 300000.0, transaction.InterestAmount)
+		assert.Equal(t, 400000.0, transaction.DiscountAmount)
+		// The installment is derived from the discounted fee and interest
+		assert.Equal(t, 7650000.0, transaction.InstallmentAmount)
+	})
+
+	t.Run("Client fee without the discount", func(t *testing.T) {
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(newTestCampaign(), nil)
+		s.promotions.EXPECT().HasRedeemed(gomock.Any(), 7, 1, gomock.Any()).Return(false, nil)
+
+		withFee := input
+		withFee.AdminFee = 100000
+		_, err := book(withFee)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+
+	t.Run("Unknown voucher", func(t *testing.T) {
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(nil, nil)
+
+		_, err := book(input)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+
+	t.Run("Campaign ended", func(t *testing.T) {
+		campaign := newTestCampaign()
+		endsAt := time.Now().Add(-time.Minute)
+		campaign.EndsAt = &endsAt
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(campaign, nil)
+
+		_, err := book(input)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+
+	t.Run("Not eligible", func(t *testing.T) {
+		campaign := newTestCampaign()
+		campaign.Tenor = 4
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(campaign, nil)
+
+		_, err := book(input)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Contains(t, err.Error(), "tenor of 4")
+	})
+
+	t.Run("Already used by the customer", func(t *testing.T) {
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(newTestCampaign(), nil)
+		s.promotions.EXPECT().HasRedeemed(gomock.Any(), 7, 1, gomock.Any()).Return(true, nil)
+
+		_, err := book(input)
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+	})
+
+	t.Run("Budget exhausted meanwhile", func(t *testing.T) {
+		s.promotions.EXPECT().GetCampaignByCode(gomock.Any(), "ZEROFEE").Return(newTestCampaign(), nil)
+		s.promotions.EXPECT().HasRedeemed(gomock.Any(), 7, 1, gomock.Any()).Return(false, nil)
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+		s.promotions.EXPECT().RedeemVoucher(gomock.Any(), gomock.Any()).Return(repository.ErrVoucherUnavailable)
+		commits, rollbacks := s.unitOfWork.Commits, s.unitOfWork.Rollbacks
+
+		_, err := book(input)
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+		// The transaction stored before the voucher was refused is rolled back with it
+		assert.Equal(t, commits, s.unitOfWork.Commits)
+		assert.Equal(t, rollbacks+1, s.unitOfWork.Rollbacks)
+	})
+}
+
+func TestBookPartnerTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	acceptContracts(s.contracts)
+	partner := service.Actor{Partner: &model.Partner{ID: 3, Channel: model.ChannelDealer, Active: true}}
+	input := service.BookTransactionInput{CustomerID: 1, ContractNumber: "KTR-001", AssetID: 1, OTR: 20000000, DownPayment: 4000000, Tenor: 1}
+
+	t.Run("Consented customer", func(t *testing.T) {
+		s.partners.EXPECT().GetConsent(gomock.Any(), 1, 3).Return(&model.PartnerConsent{CustomerID: 1, PartnerID: 3}, nil)
+		s.expectLimit(&model.Limit{CustomerID: 1, Tenor1: 20000000})
+		s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1}, nil)
+		s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+
+		transaction, err := s.BookTransaction(context.Background(), partner, input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, transaction.CustomerID)
+		assert.Equal(t, model.ChannelDealer, transaction.Channel)
+		assert.Equal(t, 3, *transaction.PartnerID)
+	})
+
+	t.Run("Consent revoked", func(t *testing.T) {
+		revokedAt := time.Now()
+		s.partners.EXPECT().GetConsent(gomock.Any(), 1, 3).Return(&model.PartnerConsent{CustomerID: 1, PartnerID: 3, RevokedAt: &revokedAt}, nil)
+
+		_, err := s.BookTransaction(context.Background(), partner, input)
+
+		assert.Equal(t, service.KindForbidden, service.KindOf(err))
+	})
+
+	t.Run("No consent", func(t *testing.T) {
+		s.partners.EXPECT().GetConsent(gomock.Any(), 2, 3).Return(nil, nil)
+
+		other := input
+		other.CustomerID = 2
+
+		_, err := s.BookTransaction(context.Background(), partner, other)
+
+		assert.Equal(t, service.KindForbidden, service.KindOf(err))
+	})
+
+	t.Run("Missing customer", func(t *testing.T) {
+		missing := input
+		missing.CustomerID = 0
+
+		_, err := s.BookTransaction(context.Background(), partner, missing)
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, "customer_id", err.(*service.Error).Fields[0].Field)
+	})
+}
+
+func TestBookTransactionStoresContractDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	s.expectLimit(&model.Limit{CustomerID: 1, Tenor1: 20000000})
+	s.customers.EXPECT().GetCustomerByID(gomock.Any(), 1).Return(&model.Customer{ID: 1, LegalName: "Budi Santoso", NIK: "3171234567890001"}, nil)
+	s.transactions.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, transaction *model.Transaction) error {
+		transaction.ID = 10
+		return nil
+	})
+
+	var stored *model.ContractDocument
+	s.contracts.EXPECT().CreateContractDocument(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, document *model.ContractDocument) error {
+		stored = document
+		return nil
+	})
+
+	_, err := s.BookTransaction(context.Background(), service.Actor{CustomerID: 1},
+		service.BookTransactionInput{ContractNumber: "KTR-001", AssetID: 1, OTR: 20000000, DownPayment: 4000000, Tenor: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, stored.TransactionID)
+	assert.Equal(t, contract.CurrentTemplate, stored.Template)
+	assert.Len(t, stored.ContentHash, 64)
+
+	// The document is stored encrypted and still matches its hash
+	encrypted, err := s.BlobStore.Get(stored.StorageKey)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "Budi Santoso")
+	data, err := service.LoadContractDocument(s.BlobStore, testEncryptionKey, stored)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Budi Santoso")
+}
+
+func TestConfirmTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	acceptedAt := time.Now()
+	s.contracts.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(&model.ContractDocument{ID: 1, TransactionID: 10, AcceptedAt: &acceptedAt}, nil).AnyTimes()
+
+	expiresAt := time.Now().Add(service.TransactionOTPTTL)
+	pending := func() *model.Transaction {
+		return &model.Transaction{ID: 10, CustomerID: 1, Tenor: 3, OTR: 20000000, DownPayment: 5000000,
+			Status: model.TransactionPending, OTPHash: hashCode("123456"), OTPExpiresAt: &expiresAt}
+	}
+	confirm := func(actor service.Actor, code string) (*model.Transaction, error) {
+		return s.ConfirmTransaction(context.Background(), actor, 10, code)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		booked := testutil.ToFloat64(metrics.ContractsBooked.WithLabelValues("3"))
+		financed := testutil.ToFloat64(metrics.AmountFinanced.WithLabelValues("3"))
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending(), nil)
+		s.transactions.EXPECT().ReserveOTPAttempt(gomock.Any(), 10, service.TransactionOTPMaxAttempts).Return(true, nil)
+		s.transactions.EXPECT().ConfirmTransaction(gomock.Any(), 10, gomock.Any()).Return(true, nil)
+
+		transaction, err := confirm(service.Actor{CustomerID: 1}, "123456")
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionConfirmed, transaction.Status)
+		assert.NotNil(t, transaction.ConfirmedAt)
+		assert.Equal(t, booked+1, testutil.ToFloat64(metrics.ContractsBooked.WithLabelValues("3")))
+		assert.Equal(t, financed+15000000, testutil.ToFloat64(metrics.AmountFinanced.WithLabelValues("3")))
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending(), nil)
+		s.transactions.EXPECT().ReserveOTPAttempt(gomock.Any(), 10, service.TransactionOTPMaxAttempts).Return(true, nil)
+
+		_, err := confirm(service.Actor{CustomerID: 1}, "654321")
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+	})
+
+	t.Run("Malformed code", func(t *testing.T) {
+		// Rejected without looking up the transaction or using up an attempt
+		_, err := confirm(service.Actor{CustomerID: 1}, "12345")
+
+		assert.Equal(t, service.KindValidation, service.KindOf(err))
+		assert.Equal(t, "code", err.(*service.Error).Fields[0].Field)
+	})
+
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending(), nil)
+		s.transactions.EXPECT().ReserveOTPAttempt(gomock.Any(), 10, service.TransactionOTPMaxAttempts).Return(false, nil)
+
+		_, err := confirm(service.Actor{CustomerID: 1}, "123456")
+
+		assert.Equal(t, service.KindTooManyAttempts, service.KindOf(err))
+	})
+
+	t.Run("Code expired", func(t *testing.T) {
+		expired := pending()
+		expiredAt := time.Now().Add(-time.Second)
+		expired.OTPExpiresAt = &expiredAt
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(expired, nil)
+
+		_, err := confirm(service.Actor{CustomerID: 1}, "123456")
+
+		assert.Equal(t, service.KindExpired, service.KindOf(err))
+	})
+
+	t.Run("Already confirmed", func(t *testing.T) {
+		confirmed := pending()
+		confirmed.Status = model.TransactionConfirmed
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(confirmed, nil)
+
+		_, err := confirm(service.Actor{CustomerID: 1}, "123456")
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+	})
+
+	t.Run("Transaction of another customer", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(pending(), nil)
+
+		_, err := confirm(service.Actor{CustomerID: 2}, "123456")
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+
+	t.Run("Transaction of another partner", func(t *testing.T) {
+		otherPartner := 4
+		transaction := pending()
+		transaction.PartnerID = &otherPartner
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(transaction, nil)
+
+		_, err := confirm(service.Actor{Partner: &model.Partner{ID: 3}}, "123456")
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+}
+
+func TestConfirmTransactionRequiresAcceptedContract(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	expiresAt := time.Now().Add(service.TransactionOTPTTL)
+	s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(&model.Transaction{ID: 10, CustomerID: 1, Status: model.TransactionPending, OTPHash: hashCode("123456"), OTPExpiresAt: &expiresAt}, nil)
+	s.contracts.EXPECT().GetContractDocumentByTransactionID(gomock.Any(), 10).Return(&model.ContractDocument{ID: 1, TransactionID: 10}, nil)
+
+	// No attempt is used up before the terms are accepted
+	_, err := s.ConfirmTransaction(context.Background(), service.Actor{CustomerID: 1}, 10, "123456")
+
+	assert.Equal(t, service.KindConflict, service.KindOf(err))
+}
+
+func TestCancelTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	cancel := func(actor service.Actor) (*model.Transaction, error) {
+		return s.CancelTransaction(context.Background(), actor, 10)
+	}
+
+	t.Run("Customer cancels a pending transaction", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(&model.Transaction{ID: 10, CustomerID: 1, Status: model.TransactionPending, PromoCode: "ZEROFEE"}, nil)
+		s.transactions.EXPECT().CancelTransaction(gomock.Any(), 10, []string{model.TransactionPending}, gomock.Any()).Return(true, nil)
+
+		transaction, err := cancel(service.Actor{CustomerID: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionCancelled, transaction.Status)
+		assert.NotNil(t, transaction.CancelledAt)
+	})
+
+	t.Run("Customer cannot cancel a booked transaction", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(&model.Transaction{ID: 10, CustomerID: 1, Status: model.TransactionConfirmed}, nil)
+
+		_, err := cancel(service.Actor{CustomerID: 1})
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+	})
+
+	t.Run("Another customer's transaction", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(&model.Transaction{ID: 10, CustomerID: 2, Status: model.TransactionPending}, nil)
+
+		_, err := cancel(service.Actor{CustomerID: 1})
+
+		assert.Equal(t, service.KindNotFound, service.KindOf(err))
+	})
+
+	t.Run("Officer cancels a booked transaction", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(&model.Transaction{ID: 10, CustomerID: 2, Status: model.TransactionConfirmed}, nil)
+		s.transactions.EXPECT().CancelTransaction(gomock.Any(), 10, []string{model.TransactionPending, model.TransactionConfirmed}, gomock.Any()).Return(true, nil)
+
+		_, err := cancel(service.Actor{Officer: true})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Already cancelled", func(t *testing.T) {
+		s.transactions.EXPECT().GetTransactionByID(gomock.Any(), 10).Return(&model.Transaction{ID: 10, CustomerID: 2, Status: model.TransactionCancelled}, nil)
+
+		_, err := cancel(service.Actor{Officer: true})
+
+		assert.Equal(t, service.KindConflict, service.KindOf(err))
+	})
+}
+
+func TestListTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := newTestTransactionService(t, ctrl)
+	transactions := []model.Transaction{{ID: 3}, {ID: 2}, {ID: 1}}
+
+	t.Run("More behind the page", func(t *testing.T) {
+		s.transactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filter model.TransactionFilter) ([]model.Transaction, error) {
+			// One extra row tells whether there is a next page
+			assert.Equal(t, 3, filter.Limit)
+			return transactions, nil
+		})
+
+		page, more, err := s.ListTransactions(context.Background(), model.TransactionFilter{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.True(t, more)
+		assert.Len(t, page, 2)
+		assert.Equal(t, 2, page[1].ID)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		s.transactions.EXPECT().ListTransactions(gomock.Any(), gomock.Any()).Return(transactions[2:], nil)
+
+		page, more, err := s.ListTransactions(context.Background(), model.TransactionFilter{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.False(t, more)
+		assert.Len(t, page, 1)
+	})
+}
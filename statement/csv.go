@@ -0,0 +1,48 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+var csvHeader = []string{"contract_number", "date", "description", "amount_due", "amount_paid", "balance"}
+
+// WriteContractCSV writes the account activity of the contract, one entry per row
+func WriteContractCSV(w io.Writer, contract Contract) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, entry := range contract.Entries {
+		writer.Write(csvRow(contract.Transaction.ContractNumber, entry))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMonthlyCSV writes the activity of every contract in the period, framed by
+// its opening and closing balance rows
+func WriteMonthlyCSV(w io.Writer, monthly Monthly) error {
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, contract := range monthly.Contracts {
+		number := contract.Transaction.ContractNumber
+		writer.Write(csvRow(number, Entry{Date: monthly.From, Description: "Opening balance", Balance: contract.OpeningBalance}))
+		for _, entry := range contract.Entries {
+			writer.Write(csvRow(number, entry))
+		}
+		writer.Write(csvRow(number, Entry{Date: lastDay(monthly), Description: "Closing balance", Balance: contract.ClosingBalance}))
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvRow(contractNumber string, entry Entry) []string {
+	return []string{
+		contractNumber,
+		entry.Date.Format(dateLayout),
+		entry.Description,
+		strconv.FormatFloat(entry.Due, 'f', 2, 64),
+		strconv.FormatFloat(entry.Paid, 'f', 2, 64),
+		strconv.FormatFloat(entry.Balance, 'f', 2, 64),
+	}
+}
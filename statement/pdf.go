@@ -0,0 +1,106 @@
+package statement
+
+import (
+	"alif-sigmatech/pdf"
+	"alif-sigmatech/util"
+	"fmt"
+	"io"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// WriteContractPDF renders the statement of a contract as a PDF document
+func WriteContractPDF(w io.Writer, contract Contract) error {
+	transaction := contract.Transaction
+	doc := pdf.New()
+
+	doc.Bold("STATEMENT OF ACCOUNT")
+	doc.Blank()
+	doc.Text(field("Contract", transaction.ContractNumber))
+	doc.Text(field("Asset", transaction.AssetName))
+	if transaction.ConfirmedAt != nil {
+		doc.Text(field("Booked", transaction.ConfirmedAt.Format(dateLayout)))
+	}
+	doc.Text(field("Tenor", fmt.Sprintf("%d months", transaction.Tenor)))
+	doc.Text(field("Installment", util.FormatAmount(transaction.InstallmentAmount)))
+	doc.Text(field("Statement date", contract.AsOf.Format(dateLayout)))
+	doc.Blank()
+	doc.Text(field("Total payable", util.FormatAmount(contract.TotalPayable)))
+	doc.Text(field("Paid to date", util.FormatAmount(contract.TotalPaid)))
+	doc.Text(field("Outstanding", util.FormatAmount(contract.Outstanding)))
+	doc.Text(field("Arrears", util.FormatAmount(contract.Arrears)))
+	doc.Blank()
+
+	doc.Bold("INSTALLMENT SCHEDULE")
+	doc.Text(fmt.Sprintf("%-6s%-14s%18s", "No", "Due date", "Amount"))
+	for _, installment := range contract.Schedule {
+		doc.Text(fmt.Sprintf("%-6d%-14s%18s", installment.Number, installment.DueDate.Format(dateLayout), util.FormatAmount(installment.Amount)))
+	}
+	doc.Blank()
+
+	doc.Bold("ACCOUNT ACTIVITY")
+	writeEntries(doc, contract.Entries)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// WriteMonthlyPDF renders a customer's monthly statement as a PDF document
+func WriteMonthlyPDF(w io.Writer, monthly Monthly) error {
+	doc := pdf.New()
+
+	doc.Bold("MONTHLY STATEMENT")
+	doc.Blank()
+	doc.Text(field("Customer", fmt.Sprintf("%s (%d)", monthly.Customer.FullName, monthly.Customer.ID)))
+	doc.Text(field("Period", fmt.Sprintf("%s to %s", monthly.From.Format(dateLayout), lastDay(monthly).Format(dateLayout))))
+	doc.Blank()
+
+	for _, contract := range monthly.Contracts {
+		doc.Bold(fmt.Sprintf("Contract %s - %s", contract.Transaction.ContractNumber, contract.Transaction.AssetName))
+		entries := append([]Entry{{Date: monthly.From, Description: "Opening balance", Balance: contract.OpeningBalance}}, contract.Entries...)
+		entries = append(entries, Entry{Date: lastDay(monthly), Description: "Closing balance", Balance: contract.ClosingBalance})
+		writeEntries(doc, entries)
+		doc.Text(field("Outstanding", util.FormatAmount(contract.Outstanding)))
+		doc.Blank()
+	}
+	if len(monthly.Contracts) == 0 {
+		doc.Text("No contracts in this period.")
+		doc.Blank()
+	}
+
+	doc.Bold("SUMMARY")
+	doc.Text(field("Installments due", util.FormatAmount(monthly.TotalDue)))
+	doc.Text(field("Payments received", util.FormatAmount(monthly.TotalPaid)))
+	doc.Text(field("Closing balance", util.FormatAmount(monthly.ClosingBalance)))
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func writeEntries(doc *pdf.Document, entries []Entry) {
+	doc.Text(fmt.Sprintf("%-12s%-30s%16s%16s%16s", "Date", "Description", "Due", "Paid", "Balance"))
+	for _, entry := range entries {
+		description := entry.Description
+		if len(description) > 29 {
+			description = description[:29]
+		}
+		doc.Text(fmt.Sprintf("%-12s%-30s%16s%16s%16s", entry.Date.Format(dateLayout), description,
+			formatOptionalAmount(entry.Due), formatOptionalAmount(entry.Paid), util.FormatAmount(entry.Balance)))
+	}
+}
+
+func field(label, value string) string {
+	return fmt.Sprintf("%-20s%s", label, value)
+}
+
+func lastDay(monthly Monthly) time.Time {
+	return monthly.To.AddDate(0, 0, -1)
+}
+
+func formatOptionalAmount(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return util.FormatAmount(amount)
+}
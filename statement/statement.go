@@ -0,0 +1,215 @@
+// Package statement builds statements of account for booked transactions and renders
+// them as CSV and PDF.
+package statement
+
+import (
+	"alif-sigmatech/model"
+	"alif-sigmatech/util"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Installment is one monthly installment of a contract's schedule
+type Installment struct {
+	Number  int
+	DueDate time.Time
+	Amount  float64
+}
+
+// Entry is a line of account activity. Balance is what the customer owes after the entry:
+// installments falling due increase it and payments reduce it.
+type Entry struct {
+	Date        time.Time
+	Description string
+	Due         float64
+	Paid        float64
+	Balance     float64
+}
+
+// Contract is the statement of a single contract up to AsOf
+type Contract struct {
+	Transaction model.Transaction
+	AsOf        time.Time
+	Schedule    []Installment
+	Entries     []Entry
+	// TotalPayable is the sum of all installments and Outstanding what remains of it after TotalPaid
+	TotalPayable float64
+	TotalPaid    float64
+	Outstanding  float64
+	// Arrears are the installments due but not paid yet
+	Arrears float64
+}
+
+// MonthlyContract is the activity of one contract during a monthly statement period
+type MonthlyContract struct {
+	Transaction    model.Transaction
+	OpeningBalance float64
+	Entries        []Entry
+	ClosingBalance float64
+	Outstanding    float64
+}
+
+// Monthly is a customer's statement of all contracts for one calendar month
+type Monthly struct {
+	Customer  model.Customer
+	From      time.Time
+	To        time.Time
+	Contracts []MonthlyContract
+	TotalDue  float64
+	TotalPaid float64
+	// ClosingBalance is what the customer owes across all contracts at the end of the period
+	ClosingBalance float64
+}
+
+// Schedule returns the installments of a booked transaction, one per month of tenor with
+// the first falling due a month after confirmation. Unconfirmed transactions have none.
+func Schedule(transaction model.Transaction) []Installment {
+	if transaction.ConfirmedAt == nil {
+		return nil
+	}
+
+	installments := make([]Installment, 0, transaction.Tenor)
+	for number := 1; number <= transaction.Tenor; number++ {
+		installments = append(installments, Installment{
+			Number:  number,
+			DueDate: transaction.ConfirmedAt.AddDate(0, number, 0),
+			Amount:  transaction.InstallmentAmount,
+		})
+	}
+	return installments
+}
+
+// BuildContract builds the statement of a contract from its payments, covering activity before asOf
+func BuildContract(transaction model.Transaction, payments []model.Payment, asOf time.Time) Contract {
+	schedule := Schedule(transaction)
+	entries := ledger(transaction, schedule, payments, time.Time{}, asOf, 0)
+
+	contract := Contract{
+		Transaction:  transaction,
+		AsOf:         asOf,
+		Schedule:     schedule,
+		Entries:      entries,
+		TotalPayable: totalPayable(schedule),
+		TotalPaid:    paidBefore(payments, asOf),
+	}
+	contract.Outstanding = util.RoundCents(contract.TotalPayable - contract.TotalPaid)
+	if len(entries) > 0 && entries[len(entries)-1].Balance > 0 {
+		contract.Arrears = entries[len(entries)-1].Balance
+	}
+	return contract
+}
+
+// BuildMonthly builds the customer's statement for the calendar month containing period. Payments
+// may belong to any of the transactions; transactions booked after the month are left out.
+func BuildMonthly(customer model.Customer, period time.Time, transactions []model.Transaction, payments []model.Payment) Monthly {
+	from := time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, period.Location())
+	to := from.AddDate(0, 1, 0)
+
+	paymentsByTransaction := map[int][]model.Payment{}
+	for _, payment := range payments {
+		paymentsByTransaction[payment.TransactionID] = append(paymentsByTransaction[payment.TransactionID], payment)
+	}
+
+	monthly := Monthly{Customer: customer, From: from, To: to, Contracts: []MonthlyContract{}}
+	for _, transaction := range transactions {
+		if transaction.ConfirmedAt == nil || !transaction.ConfirmedAt.Before(to) {
+			continue
+		}
+
+		schedule := Schedule(transaction)
+		payments := paymentsByTransaction[transaction.ID]
+		opening := util.RoundCents(dueBefore(schedule, from) - paidBefore(payments, from))
+		entries := ledger(transaction, schedule, payments, from, to, opening)
+
+		contract := MonthlyContract{
+			Transaction:    transaction,
+			OpeningBalance: opening,
+			Entries:        entries,
+			ClosingBalance: opening,
+			Outstanding:    util.RoundCents(totalPayable(schedule) - paidBefore(payments, to)),
+		}
+		for _, entry := range entries {
+			monthly.TotalDue += entry.Due
+			monthly.TotalPaid += entry.Paid
+			contract.ClosingBalance = entry.Balance
+		}
+		monthly.ClosingBalance += contract.ClosingBalance
+		monthly.Contracts = append(monthly.Contracts, contract)
+	}
+
+	monthly.TotalDue = util.RoundCents(monthly.TotalDue)
+	monthly.TotalPaid = util.RoundCents(monthly.TotalPaid)
+	monthly.ClosingBalance = util.RoundCents(monthly.ClosingBalance)
+	return monthly
+}
+
+// ledger lists the installments falling due and the payments made in [from, to) in date order,
+// with the running balance starting at opening. An installment comes before a payment on the same day.
+func ledger(transaction model.Transaction, schedule []Installment, payments []model.Payment, from, to time.Time, opening float64) []Entry {
+	entries := []Entry{}
+	for _, installment := range schedule {
+		if inPeriod(installment.DueDate, from, to) {
+			entries = append(entries, Entry{
+				Date:        installment.DueDate,
+				Description: fmt.Sprintf("Installment %d of %d", installment.Number, transaction.Tenor),
+				Due:         installment.Amount,
+			})
+		}
+	}
+	for _, payment := range payments {
+		if inPeriod(payment.PaidAt, from, to) {
+			description := "Payment"
+			if payment.Reference != "" {
+				description += " " + payment.Reference
+			}
+			entries = append(entries, Entry{Date: payment.PaidAt, Description: description, Paid: payment.Amount})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Due > 0 && entries[j].Due == 0
+		}
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	balance := opening
+	for i := range entries {
+		balance = util.RoundCents(balance + entries[i].Due - entries[i].Paid)
+		entries[i].Balance = balance
+	}
+	return entries
+}
+
+func inPeriod(at, from, to time.Time) bool {
+	return !at.Before(from) && at.Before(to)
+}
+
+func totalPayable(schedule []Installment) float64 {
+	total := 0.0
+	for _, installment := range schedule {
+		total += installment.Amount
+	}
+	return util.RoundCents(total)
+}
+
+func dueBefore(schedule []Installment, before time.Time) float64 {
+	due := 0.0
+	for _, installment := range schedule {
+		if installment.DueDate.Before(before) {
+			due += installment.Amount
+		}
+	}
+	return util.RoundCents(due)
+}
+
+func paidBefore(payments []model.Payment, before time.Time) float64 {
+	paid := 0.0
+	for _, payment := range payments {
+		if payment.PaidAt.Before(before) {
+			paid += payment.Amount
+		}
+	}
+	return util.RoundCents(paid)
+}
@@ -0,0 +1,114 @@
+package statement
+
+import (
+	"alif-sigmatech/model"
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func newTestTransactions() []model.Transaction {
+	first := time.Date(2026, 7, 15, 10, 0, 0, 0, time.UTC)
+	second := time.Date(2026, 9, 30, 16, 30, 0, 0, time.UTC)
+	return []model.Transaction{
+		{ID: 1, CustomerID: 1, ContractNumber: "KTR-001", AssetName: "Honda Vario 160", OTR: 20000000, DownPayment: 4000000,
+			InstallmentAmount: 4500000, Tenor: 4, Status: model.TransactionConfirmed, ConfirmedAt: &first},
+		{ID: 2, CustomerID: 1, ContractNumber: "KTR-002", AssetName: "Samsung Galaxy S26 (256GB)", OTR: 15000000, DownPayment: 3000000,
+			InstallmentAmount: 6120000.5, Tenor: 2, Status: model.TransactionConfirmed, ConfirmedAt: &second},
+	}
+}
+
+func newTestPayments() []model.Payment {
+	return []model.Payment{
+		{ID: 1, TransactionID: 1, Amount: 4500000, Reference: "VA-0001", PaidAt: time.Date(2026, 8, 14, 9, 0, 0, 0, time.UTC)},
+		{ID: 2, TransactionID: 1, Amount: 4500000, Reference: "VA-0002", PaidAt: time.Date(2026, 9, 15, 11, 0, 0, 0, time.UTC)},
+		{ID: 3, TransactionID: 1, Amount: 2000000, Reference: "VA-0003", PaidAt: time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC)},
+		{ID: 4, TransactionID: 2, Amount: 6120000.5, Reference: "", PaidAt: time.Date(2026, 10, 30, 12, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestBuildContract(t *testing.T) {
+	transaction := newTestTransactions()[0]
+	contract := BuildContract(transaction, newTestPayments()[:3], time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Len(t, contract.Schedule, 4)
+	assert.Equal(t, time.Date(2026, 8, 15, 10, 0, 0, 0, time.UTC), contract.Schedule[0].DueDate)
+	assert.Equal(t, 18000000.0, contract.TotalPayable)
+	assert.Equal(t, 11000000.0, contract.TotalPaid)
+	assert.Equal(t, 7000000.0, contract.Outstanding)
+	// Three installments fell due, of which 11,000,000 was paid
+	assert.Equal(t, 2500000.0, contract.Arrears)
+
+	// Unconfirmed transactions have no schedule
+	transaction.ConfirmedAt = nil
+	assert.Empty(t, BuildContract(transaction, nil, time.Now()).Schedule)
+}
+
+func TestBuildMonthly(t *testing.T) {
+	monthly := BuildMonthly(model.Customer{ID: 1, FullName: "Budi"}, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		newTestTransactions(), newTestPayments())
+
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), monthly.From)
+	assert.Len(t, monthly.Contracts, 2)
+	assert.Equal(t, 0.0, monthly.Contracts[0].OpeningBalance)
+	assert.Equal(t, 2500000.0, monthly.Contracts[0].ClosingBalance)
+	assert.Equal(t, 7000000.0, monthly.Contracts[0].Outstanding)
+	assert.Equal(t, 0.0, monthly.Contracts[1].ClosingBalance)
+	assert.Equal(t, 10620000.5, monthly.TotalDue)
+	assert.Equal(t, 8120000.5, monthly.TotalPaid)
+	assert.Equal(t, 2500000.0, monthly.ClosingBalance)
+
+	// Contracts booked after the period are left out
+	earlier := BuildMonthly(model.Customer{ID: 1}, time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), newTestTransactions(), newTestPayments())
+	assert.Len(t, earlier.Contracts, 1)
+}
+
+func TestGoldenFiles(t *testing.T) {
+	transactions := newTestTransactions()
+	payments := newTestPayments()
+	contract := BuildContract(transactions[0], payments[:3], time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+	monthly := BuildMonthly(model.Customer{ID: 1, FullName: "Budi Santoso"}, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), transactions, payments)
+
+	// Enough contracts to spill over onto a second page
+	var busy []model.Transaction
+	for i := 0; i < 12; i++ {
+		transaction := transactions[0]
+		transaction.ID = 100 + i
+		busy = append(busy, transaction)
+	}
+	long := BuildMonthly(model.Customer{ID: 2, FullName: "Siti Nurhaliza"}, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), busy, nil)
+
+	tests := []struct {
+		golden string
+		write  func(buf *bytes.Buffer) error
+	}{
+		{golden: "contract.csv", write: func(buf *bytes.Buffer) error { return WriteContractCSV(buf, contract) }},
+		{golden: "contract.pdf", write: func(buf *bytes.Buffer) error { return WriteContractPDF(buf, contract) }},
+		{golden: "monthly.csv", write: func(buf *bytes.Buffer) error { return WriteMonthlyCSV(buf, monthly) }},
+		{golden: "monthly.pdf", write: func(buf *bytes.Buffer) error { return WriteMonthlyPDF(buf, monthly) }},
+		{golden: "monthly_pages.pdf", write: func(buf *bytes.Buffer) error { return WriteMonthlyPDF(buf, long) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, tt.write(&buf))
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+			}
+
+			expected, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), buf.String())
+		})
+	}
+}
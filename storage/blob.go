@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under the requested key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque objects under slash separated keys. The method set
+// mirrors the basic operations of S3-compatible object stores so a bucket
+// backed implementation can be swapped in without touching callers.
+type BlobStore interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// FileSystemBlobStore is a BlobStore implementation keeping blobs on local disk
+type FileSystemBlobStore struct {
+	BaseDir string
+}
+
+// NewFileSystemBlobStore creates a new instance of FileSystemBlobStore rooted at baseDir
+func NewFileSystemBlobStore(baseDir string) (*FileSystemBlobStore, error) {
+	if err := os.MkdirAll(baseDir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSystemBlobStore{
+		BaseDir: baseDir,
+	}, nil
+}
+
+// Put writes data under key, replacing any existing blob. The content type is
+// not persisted by the filesystem store; callers keep it with their metadata.
+func (s *FileSystemBlobStore) Put(key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never observe a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get reads the blob stored under key
+func (s *FileSystemBlobStore) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return data, err
+}
+
+// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+func (s *FileSystemBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path maps a key to a file below BaseDir, rejecting keys that would escape it
+func (s *FileSystemBlobStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", errors.New("invalid blob key")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", errors.New("invalid blob key")
+		}
+	}
+	return filepath.Join(s.BaseDir, filepath.FromSlash(key)), nil
+}
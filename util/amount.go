@@ -0,0 +1,31 @@
+package util
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundCents rounds an amount to two decimals so float artefacts do not fail exact comparisons
+func RoundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// FormatAmount formats an amount with two decimals and thousands separators
+func FormatAmount(amount float64) string {
+	formatted := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(formatted, "-") {
+		sign, formatted = "-", formatted[1:]
+	}
+
+	whole, cents := formatted[:len(formatted)-3], formatted[len(formatted)-3:]
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + cents
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "0.00", FormatAmount(0))
+	assert.Equal(t, "999.50", FormatAmount(999.5))
+	assert.Equal(t, "1,000.00", FormatAmount(1000))
+	assert.Equal(t, "20,000,000.00", FormatAmount(20000000))
+	assert.Equal(t, "-1,234,567.89", FormatAmount(-1234567.89))
+}
+
+func TestRoundCents(t *testing.T) {
+	assert.Equal(t, 0.3, RoundCents(0.1+0.2))
+	assert.Equal(t, 1234.57, RoundCents(1234.565))
+	assert.Equal(t, -2.5, RoundCents(-2.499))
+}
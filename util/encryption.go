@@ -4,10 +4,18 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"io"
+	"math/big"
 )
 
+// CheckEncryptionKey reports whether key is a valid AES key
+func CheckEncryptionKey(key []byte) error {
+	_, err := aes.NewCipher(key)
+	return err
+}
+
 // EncryptData encrypt data using given key
 func EncryptData(data []byte, key []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
@@ -40,8 +48,32 @@ func DecryptData(ciphertext []byte, key []byte) ([]byte, error) {
 	iv := ciphertext[:aes.BlockSize]
 	ciphertext = ciphertext[aes.BlockSize:]
 
+	// Decrypt into a new slice so the caller's ciphertext stays intact
+	plaintext := make([]byte, len(ciphertext))
 	stream := cipher.NewCFBDecrypter(block, iv)
-	stream.XORKeyStream(ciphertext, ciphertext)
+	stream.XORKeyStream(plaintext, ciphertext)
 
-	return ciphertext, nil
+	return plaintext, nil
+}
+
+// RandomToken returns n cryptographically random bytes encoded as a URL safe string
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RandomDigits returns a cryptographically random numeric code of n digits, e.g. for one-time passwords
+func RandomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
 }
@@ -2,17 +2,26 @@ package util
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/go-sql-driver/mysql"
 )
 
+// ErrDuplicate is a row that violates a unique key
+var ErrDuplicate = errors.New("duplicate entry")
+
+// mysqlErrDuplicateEntry is the MySQL error number of a unique key violation
+const mysqlErrDuplicateEntry = 1062
+
+// CheckMySQLError turns the MySQL errors callers act upon into errors they can match with
+// errors.Is, e.g. ErrDuplicate. Other errors are returned as they are.
 func CheckMySQLError(err error) error {
-	me, ok := err.(*mysql.MySQLError)
-	if !ok {
+	var me *mysql.MySQLError
+	if !errors.As(err, &me) {
 		return err
 	}
-	if me.Number == 1062 {
-		return errors.New("It already exists in a database.")
+	if me.Number == mysqlErrDuplicateEntry {
+		return fmt.Errorf("%w: %s", ErrDuplicate, me.Message)
 	}
 	return err
 }
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMySQLError(t *testing.T) {
+	duplicate := CheckMySQLError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '3201010101010001' for key 'nik'"})
+	assert.True(t, errors.Is(duplicate, ErrDuplicate))
+
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	assert.Equal(t, deadlock, CheckMySQLError(deadlock))
+
+	other := errors.New("connection refused")
+	assert.Equal(t, other, CheckMySQLError(other))
+}
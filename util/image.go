@@ -0,0 +1,177 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+)
+
+// Image content types accepted for uploads
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+)
+
+// ErrUnsupportedImage is returned when the uploaded bytes are not a JPEG or PNG image
+var ErrUnsupportedImage = errors.New("only JPEG and PNG images are supported")
+
+// ImageLimits bounds the size and dimensions of uploaded images
+type ImageLimits struct {
+	MaxBytes      int64
+	MinWidth      int
+	MinHeight     int
+	MaxWidth      int
+	MaxHeight     int
+	ThumbnailSize int
+	JPEGQuality   int
+}
+
+// DefaultImageLimits returns limits suitable for identity document photos
+func DefaultImageLimits() ImageLimits {
+	return ImageLimits{
+		MaxBytes:      5 << 20,
+		MinWidth:      320,
+		MinHeight:     240,
+		MaxWidth:      6000,
+		MaxHeight:     6000,
+		ThumbnailSize: 256,
+		JPEGQuality:   90,
+	}
+}
+
+// NormalizedImage is an uploaded image re-encoded to JPEG together with its thumbnail
+type NormalizedImage struct {
+	Data      []byte
+	Thumbnail []byte
+	Width     int
+	Height    int
+}
+
+// DetectImageType identifies the image format from its magic bytes rather than
+// trusting the client supplied content type
+func DetectImageType(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return ContentTypeJPEG, nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ContentTypePNG, nil
+	default:
+		return "", ErrUnsupportedImage
+	}
+}
+
+// NormalizeImage validates an uploaded image against limits and re-encodes it
+// to JPEG. Re-encoding drops every metadata segment (EXIF, GPS, comments) the
+// original file carried.
+func NormalizeImage(data []byte, limits ImageLimits) (*NormalizedImage, error) {
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", limits.MaxBytes)
+	}
+
+	contentType, err := DetectImageType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the dimensions before decoding so oversized images are never expanded in memory
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width < limits.MinWidth || config.Height < limits.MinHeight {
+		return nil, fmt.Errorf("image must be at least %dx%d pixels", limits.MinWidth, limits.MinHeight)
+	}
+	if config.Width > limits.MaxWidth || config.Height > limits.MaxHeight {
+		return nil, fmt.Errorf("image must be at most %dx%d pixels", limits.MaxWidth, limits.MaxHeight)
+	}
+
+	var img image.Image
+	if contentType == ContentTypePNG {
+		img, err = png.Decode(bytes.NewReader(data))
+	} else {
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	normalized, err := encodeJPEG(img, limits.JPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, err := encodeJPEG(Thumbnail(img, limits.ThumbnailSize), limits.JPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NormalizedImage{
+		Data:      normalized,
+		Thumbnail: thumbnail,
+		Width:     config.Width,
+		Height:    config.Height,
+	}, nil
+}
+
+// Thumbnail scales img down so its longest side is at most size pixels,
+// averaging the source pixels covered by each thumbnail pixel
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= size && srcH <= size {
+		return img
+	}
+
+	dstW, dstH := size, size
+	if srcW > srcH {
+		dstH = maxInt(1, srcH*size/srcW)
+	} else {
+		dstW = maxInt(1, srcW*size/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := maxInt(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := maxInt(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
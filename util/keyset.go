@@ -0,0 +1,224 @@
+package util
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// VerificationKey is a public key accepted for verifying tokens, identified by its key ID
+type VerificationKey struct {
+	KID       string
+	Algorithm string
+	PublicKey crypto.PublicKey
+}
+
+// KeySet signs tokens with one private key and verifies them with any of its public keys.
+// Keeping retired public keys in the set lets tokens signed before a key rotation stay valid.
+type KeySet struct {
+	Issuer   string
+	Audience string
+
+	signingKID    string
+	signingMethod jwt.SigningMethod
+	signingKey    crypto.PrivateKey
+	keys          map[string]VerificationKey
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KTY string `json:"kty"`
+	KID string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	CRV string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeySet creates a KeySet signing with an RSA (RS256) or Ed25519 (EdDSA) private key
+func NewKeySet(signingKID string, signingKey crypto.PrivateKey, issuer, audience string) (*KeySet, error) {
+	if signingKID == "" {
+		return nil, errors.New("signing key ID is required")
+	}
+
+	keySet := &KeySet{
+		Issuer:     issuer,
+		Audience:   audience,
+		signingKID: signingKID,
+		signingKey: signingKey,
+		keys:       make(map[string]VerificationKey),
+	}
+
+	var publicKey crypto.PublicKey
+	switch key := signingKey.(type) {
+	case *rsa.PrivateKey:
+		keySet.signingMethod = jwt.SigningMethodRS256
+		publicKey = &key.PublicKey
+	case ed25519.PrivateKey:
+		keySet.signingMethod = jwt.SigningMethodEdDSA
+		publicKey = key.Public()
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", signingKey)
+	}
+
+	if err := keySet.AddVerificationKey(signingKID, publicKey); err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+// LoadKeySet reads the signing key and additional verification keys (key ID to file) from PEM files
+func LoadKeySet(signingKeyFile, signingKID string, verificationKeyFiles map[string]string, issuer, audience string) (*KeySet, error) {
+	pemBytes, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, err := parsePrivateKeyPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", signingKeyFile, err)
+	}
+
+	keySet, err := NewKeySet(signingKID, signingKey, issuer, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	for kid, file := range verificationKeyFiles {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, err := parsePublicKeyPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		if err := keySet.AddVerificationKey(kid, publicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return keySet, nil
+}
+
+// AddVerificationKey adds a public key accepted for tokens carrying kid in their header
+func (k *KeySet) AddVerificationKey(kid string, publicKey crypto.PublicKey) error {
+	if _, exists := k.keys[kid]; exists {
+		return fmt.Errorf("duplicate key ID %q", kid)
+	}
+
+	key := VerificationKey{KID: kid, PublicKey: publicKey}
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		key.Algorithm = jwt.SigningMethodRS256.Alg()
+	case ed25519.PublicKey:
+		key.Algorithm = jwt.SigningMethodEdDSA.Alg()
+	default:
+		return fmt.Errorf("unsupported verification key type %T", publicKey)
+	}
+
+	k.keys[kid] = key
+	return nil
+}
+
+// Sign signs claims with the current signing key and records its key ID in the header
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signingMethod, claims)
+	token.Header["kid"] = k.signingKID
+	return token.SignedString(k.signingKey)
+}
+
+// Check signs a token and verifies it again, reporting whether the key material is usable
+func (k *KeySet) Check(ctx context.Context) error {
+	if k == nil || k.signingKey == nil {
+		return errors.New("no signing key loaded")
+	}
+
+	token, err := k.Sign(jwt.StandardClaims{Subject: "readiness-check"})
+	if err != nil {
+		return fmt.Errorf("failed to sign with key %s: %w", k.signingKID, err)
+	}
+	_, err = jwt.Parse(token, k.Keyfunc)
+	if err != nil {
+		return fmt.Errorf("failed to verify with key %s: %w", k.signingKID, err)
+	}
+	return nil
+}
+
+// Keyfunc selects the verification key named by the token's kid header for jwt.Parse.
+// The token's algorithm must match the algorithm of that key.
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+	}
+
+	return key.PublicKey, nil
+}
+
+// JWKS returns every verification key in JSON Web Key Set format, sorted by key ID
+func (k *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.keys {
+		jwk := JWK{KID: key.KID, Use: "sig", Alg: key.Algorithm}
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KTY = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KTY = "OKP"
+			jwk.CRV = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KID < set.Keys[j].KID })
+	return set
+}
+
+func parsePrivateKeyPEM(pemBytes []byte) (crypto.PrivateKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("private key must be an RSA or Ed25519 key in PEM format")
+}
+
+func parsePublicKeyPEM(pemBytes []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("public key must be an RSA or Ed25519 key in PEM format")
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//go:embed breached_passwords.txt
+var breachedPasswordList string
+
+// PasswordPolicy describes the strength requirements for customer passwords
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// CheckBreached rejects passwords found in the bundled breached password list
+	CheckBreached bool
+}
+
+// DefaultPasswordPolicy returns the policy used when nothing else is configured
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: false,
+		CheckBreached: true,
+	}
+}
+
+var breachedPasswords = loadBreachedPasswords()
+
+func loadBreachedPasswords() map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(breachedPasswordList, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			passwords[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return passwords
+}
+
+// Requirements of a password policy a PasswordError reports
+const (
+	PasswordTooShort    = "too_short"
+	PasswordNoUppercase = "uppercase"
+	PasswordNoLowercase = "lowercase"
+	PasswordNoDigit     = "digit"
+	PasswordNoSymbol    = "symbol"
+	PasswordBreached    = "breached"
+)
+
+// PasswordError reports the requirement of a password policy a password does not meet
+type PasswordError struct {
+	Rule      string
+	MinLength int
+}
+
+func (e *PasswordError) Error() string {
+	switch e.Rule {
+	case PasswordTooShort:
+		return fmt.Sprintf("Password must be at least %d characters", e.MinLength)
+	case PasswordNoUppercase:
+		return "Password must contain an uppercase letter"
+	case PasswordNoLowercase:
+		return "Password must contain a lowercase letter"
+	case PasswordNoDigit:
+		return "Password must contain a digit"
+	case PasswordNoSymbol:
+		return "Password must contain a symbol"
+	default:
+		return "Password appears in a list of breached passwords"
+	}
+}
+
+// Validate returns a *PasswordError describing the first requirement the password does not meet
+func (p PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return &PasswordError{Rule: PasswordTooShort, MinLength: p.MinLength}
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c) || unicode.IsSpace(c):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return &PasswordError{Rule: PasswordNoUppercase}
+	}
+	if p.RequireLower && !hasLower {
+		return &PasswordError{Rule: PasswordNoLowercase}
+	}
+	if p.RequireDigit && !hasDigit {
+		return &PasswordError{Rule: PasswordNoDigit}
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return &PasswordError{Rule: PasswordNoSymbol}
+	}
+	if p.CheckBreached {
+		if _, found := breachedPasswords[strings.ToLower(password)]; found {
+			return &PasswordError{Rule: PasswordBreached}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the IP address of the client that sent the request
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request being served
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
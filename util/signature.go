@@ -0,0 +1,25 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignRequest returns the hex encoded HMAC-SHA256 over the method, request URI,
+// timestamp, nonce and body hash of a request, each on its own line
+func SignRequest(secret []byte, method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{method, requestURI, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequestSignature reports whether signature is the SignRequest signature of the request
+func VerifyRequestSignature(secret []byte, method, requestURI, timestamp, nonce string, body []byte, signature string) bool {
+	expected := SignRequest(secret, method, requestURI, timestamp, nonce, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"alif-sigmatech/model"
+	"time"
+)
+
+// LoginThrottlePolicy slows down and eventually locks out repeated failed logins
+type LoginThrottlePolicy struct {
+	// FreeAttempts is the number of failures allowed before any delay applies
+	FreeAttempts int
+	// BaseDelay is doubled for every failure past FreeAttempts, up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold failures lock logins for LockoutDuration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// ResetAfter forgets failures when no new failure happened for this long
+	ResetAfter time.Duration
+}
+
+// DefaultNIKThrottlePolicy returns the policy applied to failed logins per NIK
+func DefaultNIKThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       24 * time.Hour,
+	}
+}
+
+// DefaultIPThrottlePolicy returns the policy applied to failed logins per client IP.
+// It is looser than the NIK policy because many customers may share an address.
+func DefaultIPThrottlePolicy() LoginThrottlePolicy {
+	return LoginThrottlePolicy{
+		FreeAttempts:     10,
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Minute,
+		LockoutThreshold: 50,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	}
+}
+
+// NextAllowedAt returns when the next login attempt may be made after the given failures
+func (p LoginThrottlePolicy) NextAllowedAt(attempt *model.LoginAttempt) time.Time {
+	if attempt == nil || attempt.Failures == 0 || time.Since(attempt.LastFailureAt) > p.ResetAfter {
+		return time.Time{}
+	}
+
+	if attempt.Failures >= p.LockoutThreshold {
+		return attempt.LastFailureAt.Add(p.LockoutDuration)
+	}
+
+	excess := attempt.Failures - p.FreeAttempts
+	if excess <= 0 {
+		return time.Time{}
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < excess && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return attempt.LastFailureAt.Add(delay)
+}
@@ -0,0 +1,202 @@
+// Package validation checks request payloads against rules and collects every broken rule, so a
+// client learns about all invalid fields of a request from one response.
+//
+// Rules are declared field by field on a Validator:
+//
+//	v := validation.New()
+//	v.Required("nik", input.NIK)
+//	v.NIK("nik", input.NIK)
+//	v.Between("tenor", float64(input.Tenor), 1, 4)
+//	return v.Err()
+//
+// Only the first broken rule of a field is reported, so later rules may assume the earlier ones hold.
+package validation
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/i18n"
+	"alif-sigmatech/util"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of dates in request payloads
+const DateLayout = "2006-01-02"
+
+var (
+	nikPattern         = regexp.MustCompile(`^[0-9]{16}$`)
+	phoneNumberPattern = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+	digitsPattern      = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// acronyms are written in capitals when a field name is turned into the label used in messages
+var acronyms = map[string]string{
+	"id":  "ID",
+	"nik": "NIK",
+	"otr": "OTR",
+	"mfa": "MFA",
+	"ktp": "KTP",
+}
+
+// Error lists the invalid fields of a request
+type Error struct {
+	Fields []apierror.FieldError
+}
+
+// Error joins the messages of the invalid fields
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects the broken rules of a request
+type Validator struct {
+	fields  []apierror.FieldError
+	invalid map[string]bool
+}
+
+// New creates a Validator with no broken rules
+func New() *Validator {
+	return &Validator{invalid: make(map[string]bool)}
+}
+
+// Check records a broken rule of the field when ok is false, unless the field already broke one.
+// The rule is described as by NewFieldError. It returns ok so dependent rules can be skipped.
+func (v *Validator) Check(ok bool, field string, code apierror.Code, variant string, params i18n.Params) bool {
+	if ok || v.invalid[field] {
+		return ok
+	}
+	v.invalid[field] = true
+	v.fields = append(v.fields, NewFieldError(field, code, variant, params))
+	return false
+}
+
+// NewFieldError describes a broken rule of the field by the template of code and variant,
+// filled with params and the label of the field as {field}
+func NewFieldError(field string, code apierror.Code, variant string, params i18n.Params) apierror.FieldError {
+	filled := i18n.Params{"field": Label(field)}
+	for name, value := range params {
+		filled[name] = value
+	}
+	return apierror.NewFieldError(field, code, variant, filled)
+}
+
+// Valid reports whether no rule is broken
+func (v *Validator) Valid() bool {
+	return len(v.fields) == 0
+}
+
+// Err returns the broken rules as an *Error, or nil when there are none
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return &Error{Fields: v.fields}
+}
+
+// Required checks that a text field is not blank
+func (v *Validator) Required(field string, value string) bool {
+	return v.Check(strings.TrimSpace(value) != "", field, apierror.CodeRequired, "", nil)
+}
+
+// RequiredID checks that a reference to another resource is set
+func (v *Validator) RequiredID(field string, value int) bool {
+	return v.Check(value > 0, field, apierror.CodeRequired, "", nil)
+}
+
+// MaxLength checks that a text field is at most max bytes long, the unit the database columns are sized in
+func (v *Validator) MaxLength(field string, value string, max int) bool {
+	return v.Check(len(value) <= max, field, apierror.CodeTooLong, "", i18n.Params{"max": strconv.Itoa(max)})
+}
+
+// Positive checks that a number is greater than zero
+func (v *Validator) Positive(field string, value float64) bool {
+	return v.Check(value > 0, field, apierror.CodeOutOfRange, "positive", nil)
+}
+
+// NotNegative checks that a number is zero or greater
+func (v *Validator) NotNegative(field string, value float64) bool {
+	return v.Check(value >= 0, field, apierror.CodeOutOfRange, "not_negative", nil)
+}
+
+// Max checks that a number is at most max
+func (v *Validator) Max(field string, value float64, max float64) bool {
+	return v.Check(value <= max, field, apierror.CodeOutOfRange, "max", i18n.Params{"max": FormatNumber(max)})
+}
+
+// Between checks that a number is within min and max, both included
+func (v *Validator) Between(field string, value float64, min float64, max float64) bool {
+	return v.Check(value >= min && value <= max, field, apierror.CodeOutOfRange, "between",
+		i18n.Params{"min": FormatNumber(min), "max": FormatNumber(max)})
+}
+
+// OneOf checks that a text field has one of the allowed values
+func (v *Validator) OneOf(field string, value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	if len(allowed) == 2 {
+		return v.Check(false, field, apierror.CodeNotAllowed, "either", i18n.Params{"first": allowed[0], "second": allowed[1]})
+	}
+	return v.Check(false, field, apierror.CodeNotAllowed, "one_of", i18n.Params{"allowed": strings.Join(allowed, ", ")})
+}
+
+// Date checks that a text field is a date formatted as YYYY-MM-DD
+func (v *Validator) Date(field string, value string) bool {
+	_, err := time.Parse(DateLayout, value)
+	return v.Check(err == nil, field, apierror.CodeInvalidFormat, "date", nil)
+}
+
+// NIK checks that a text field is a national identity number of 16 digits
+func (v *Validator) NIK(field string, value string) bool {
+	return v.Check(nikPattern.MatchString(value), field, apierror.CodeInvalidFormat, "nik", nil)
+}
+
+// PhoneNumber checks that a text field is a phone number of 8 to 15 digits, optionally preceded by +
+func (v *Validator) PhoneNumber(field string, value string) bool {
+	return v.Check(phoneNumberPattern.MatchString(value), field, apierror.CodeInvalidFormat, "phone_number", nil)
+}
+
+// Digits checks that a text field is a code of exactly n digits
+func (v *Validator) Digits(field string, value string, n int) bool {
+	return v.Check(len(value) == n && digitsPattern.MatchString(value), field, apierror.CodeInvalidFormat, "digits",
+		i18n.Params{"digits": strconv.Itoa(n)})
+}
+
+// Password checks that a password meets the policy, reporting the first requirement it does not meet
+func (v *Validator) Password(field string, password string, policy util.PasswordPolicy) bool {
+	err := policy.Validate(password)
+	var passwordErr *util.PasswordError
+	if !errors.As(err, &passwordErr) {
+		return true
+	}
+	return v.Check(false, field, apierror.CodeWeakPassword, passwordErr.Rule,
+		i18n.Params{"min": strconv.Itoa(passwordErr.MinLength)})
+}
+
+// Label turns the JSON name of a field into the name used for it in messages, e.g. asset_id into AssetID
+func Label(field string) string {
+	var label strings.Builder
+	for _, word := range strings.Split(field, "_") {
+		if acronym, ok := acronyms[word]; ok {
+			label.WriteString(acronym)
+		} else if word != "" {
+			label.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return label.String()
+}
+
+// FormatNumber writes a number in messages without trailing zeros
+func FormatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,102 @@
+package validation_test
+
+import (
+	"alif-sigmatech/apierror"
+	"alif-sigmatech/util"
+	"alif-sigmatech/validation"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		v := validation.New()
+		v.Required("nik", "3201010101010001")
+		v.NIK("nik", "3201010101010001")
+		v.Between("tenor", 4, 1, 4)
+		v.Date("birth_date", "1990-01-31")
+		v.PhoneNumber("phone_number", "+6281234567890")
+		v.OneOf("channel", "dealer", "dealer", "ecommerce")
+		v.Digits("code", "012345", 6)
+
+		assert.True(t, v.Valid())
+		assert.NoError(t, v.Err())
+	})
+
+	t.Run("Aggregates every field", func(t *testing.T) {
+		v := validation.New()
+		v.Required("full_name", " ")
+		v.NIK("nik", "182381283182")
+		v.Between("tenor", 7, 1, 4)
+		v.Max("installment_amount", 20000000, 15000000)
+		v.OneOf("field_name", "salary", "nik", "full_name", "birth_date")
+
+		err := v.Err()
+		var validationErr *validation.Error
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "full_name", Code: apierror.CodeRequired, Message: "FullName is required"},
+			{Field: "nik", Code: apierror.CodeInvalidFormat, Message: "NIK must be 16 digits"},
+			{Field: "tenor", Code: apierror.CodeOutOfRange, Message: "Tenor must be between 1 and 4"},
+			{Field: "installment_amount", Code: apierror.CodeOutOfRange, Message: "InstallmentAmount must not exceed 15000000"},
+			{Field: "field_name", Code: apierror.CodeNotAllowed, Message: "FieldName must be one of nik, full_name, birth_date"},
+		}, reported(validationErr.Fields))
+		assert.Equal(t, "FullName is required; NIK must be 16 digits; Tenor must be between 1 and 4; "+
+			"InstallmentAmount must not exceed 15000000; FieldName must be one of nik, full_name, birth_date", err.Error())
+	})
+
+	t.Run("First broken rule of a field", func(t *testing.T) {
+		v := validation.New()
+		v.Required("nik", "")
+		v.NIK("nik", "")
+
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "nik", Code: apierror.CodeRequired, Message: "NIK is required"},
+		}, reported(v.Err().(*validation.Error).Fields))
+	})
+
+	t.Run("Formats", func(t *testing.T) {
+		v := validation.New()
+		v.Date("birth_date", "31-01-1990")
+		v.PhoneNumber("phone_number", "0812-345")
+		v.Digits("code", "12345a", 6)
+		v.MaxLength("address", "Jl. Sudirman", 5)
+
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "birth_date", Code: apierror.CodeInvalidFormat, Message: "BirthDate must be a date formatted as YYYY-MM-DD"},
+			{Field: "phone_number", Code: apierror.CodeInvalidFormat, Message: "PhoneNumber must contain 8 to 15 digits"},
+			{Field: "code", Code: apierror.CodeInvalidFormat, Message: "Code must be 6 digits"},
+			{Field: "address", Code: apierror.CodeTooLong, Message: "Address must be at most 5 characters"},
+		}, reported(v.Err().(*validation.Error).Fields))
+	})
+
+	t.Run("Password", func(t *testing.T) {
+		v := validation.New()
+		v.Password("password", "Str0ngPassword!", util.DefaultPasswordPolicy())
+		v.Password("new_password", "short", util.DefaultPasswordPolicy())
+
+		fields := v.Err().(*validation.Error).Fields
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "new_password", Code: apierror.CodeWeakPassword, Message: "Password must be at least 10 characters"},
+		}, reported(fields))
+		assert.Equal(t, util.PasswordTooShort, fields[0].Variant)
+	})
+}
+
+// reported keeps what clients see of field errors, dropping the templates they are translated from
+func reported(fields []apierror.FieldError) []apierror.FieldError {
+	reported := make([]apierror.FieldError, len(fields))
+	for i, field := range fields {
+		reported[i] = apierror.FieldError{Field: field.Field, Code: field.Code, Message: field.Message}
+	}
+	return reported
+}
+
+func TestLabel(t *testing.T) {
+	assert.Equal(t, "AssetID", validation.Label("asset_id"))
+	assert.Equal(t, "MinOTR", validation.Label("min_otr"))
+	assert.Equal(t, "NIK", validation.Label("nik"))
+	assert.Equal(t, "MaxFinancePercent", validation.Label("max_finance_percent"))
+}